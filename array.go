@@ -0,0 +1,132 @@
+package ksql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Split a one dimensional postgres array literal such as {1,"a b",NULL} into its elements,
+// reporting which elements are NULL
+func parseArray(s string) ([]string, []bool, error) {
+	// arrays with explicit bounds are prefixed with [1:3]=
+	if strings.HasPrefix(s, "[") {
+		if i := strings.Index(s, "="); i > 0 {
+			s = s[i+1:]
+		}
+	}
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, nil, ErrInvalidColumnTypeConversion
+	}
+	s = s[1 : len(s)-1]
+	if s == "" {
+		return []string{}, []bool{}, nil
+	}
+	var elems []string
+	var nulls []bool
+	for i := 0; ; i++ {
+		var b strings.Builder
+		quoted := false
+		if i < len(s) && s[i] == '"' {
+			quoted = true
+			for i++; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			if i == len(s) {
+				return nil, nil, ErrInvalidColumnTypeConversion
+			}
+			i++
+		} else {
+			for ; i < len(s) && s[i] != ','; i++ {
+				if s[i] == '{' || s[i] == '"' {
+					return nil, nil, ErrInvalidColumnTypeConversion
+				}
+				b.WriteByte(s[i])
+			}
+		}
+		elem := b.String()
+		elems = append(elems, elem)
+		nulls = append(nulls, !quoted && strings.EqualFold(elem, "NULL"))
+		if i == len(s) {
+			return elems, nulls, nil
+		}
+		if s[i] != ',' {
+			return nil, nil, ErrInvalidColumnTypeConversion
+		}
+	}
+}
+
+// Get the elements of an array column, which are never NULL
+func arrayElements(value interface{}) ([]string, error) {
+	var s string
+	switch value := value.(type) {
+	case []byte:
+		s = string(value)
+	case string:
+		s = value
+	default:
+		return nil, ErrInvalidColumnTypeConversion
+	}
+	elems, nulls, err := parseArray(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, null := range nulls {
+		if null {
+			return nil, ErrInvalidColumnTypeConversion
+		}
+	}
+	return elems, nil
+}
+
+func convertToStringSlice(value interface{}) ([]string, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return append([]string{}, value...), nil
+	}
+	return arrayElements(value)
+}
+
+func convertToInt64Slice(value interface{}) ([]int64, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case []int64:
+		return append([]int64{}, value...), nil
+	}
+	elems, err := arrayElements(value)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]int64, len(elems))
+	for i, elem := range elems {
+		if result[i], err = strconv.ParseInt(elem, 10, 64); err != nil {
+			return nil, ErrInvalidColumnTypeConversion
+		}
+	}
+	return result, nil
+}
+
+func convertToFloat64Slice(value interface{}) ([]float64, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case []float64:
+		return append([]float64{}, value...), nil
+	}
+	elems, err := arrayElements(value)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]float64, len(elems))
+	for i, elem := range elems {
+		if result[i], err = strconv.ParseFloat(elem, 64); err != nil {
+			return nil, ErrInvalidColumnTypeConversion
+		}
+	}
+	return result, nil
+}
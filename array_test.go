@@ -0,0 +1,83 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestParseArray(t *testing.T) {
+	tests := map[string][]string{
+		`{}`:                    {},
+		`{1,2,3}`:               {"1", "2", "3"},
+		`{"a b","c,d",e}`:       {"a b", "c,d", "e"},
+		`{"say \"hi\"","a\\b"}`: {`say "hi"`, `a\b`},
+		`{"NULL",x}`:            {"NULL", "x"},
+		`[0:1]={7,8}`:           {"7", "8"},
+	}
+	for text, want := range tests {
+		elems, nulls, err := parseArray(text)
+		if err != nil {
+			t.Errorf("%s: %v", text, err)
+			continue
+		}
+		if !reflect.DeepEqual(elems, want) {
+			t.Errorf("expected %q for %s, got %q", want, text, elems)
+		}
+		for i, null := range nulls {
+			if null {
+				t.Errorf("expected element %d of %s not to be NULL", i, text)
+			}
+		}
+	}
+	_, nulls, err := parseArray(`{a,NULL}`)
+	if err != nil || !reflect.DeepEqual(nulls, []bool{false, true}) {
+		t.Errorf("expected the second element to be NULL, got %v %v", nulls, err)
+	}
+	for _, text := range []string{``, `1,2`, `{"a}`, `{{1,2},{3,4}}`, `{"a"b}`} {
+		if _, _, err := parseArray(text); err != ErrInvalidColumnTypeConversion {
+			t.Errorf("expected a conversion error for %s, got %v", text, err)
+		}
+	}
+}
+
+func TestArrayGetters(t *testing.T) {
+	defer Close()
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testHosts.setResult("select tags", []string{"tags", "ids", "ratios", "nulls", "missing"},
+		[]driver.Value{[]byte(`{go,"sql db"}`), `{1,2,3}`, []byte(`{0.5,1e3}`), `{1,NULL}`, nil})
+	row := db.QueryRow("select tags")
+	tags, err := row.GetStringSlice("tags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, []string{"go", "sql db"}) {
+		t.Errorf("expected [go \"sql db\"] for \"tags\", got %q", tags)
+	}
+	ids, err := row.GetInt64Slice("ids")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int64{1, 2, 3}) {
+		t.Errorf("expected [1 2 3] for \"ids\", got %v", ids)
+	}
+	ratios, err := row.GetFloat64Slice("ratios")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ratios, []float64{0.5, 1000}) {
+		t.Errorf("expected [0.5 1000] for \"ratios\", got %v", ratios)
+	}
+	if _, err := row.GetInt64Slice("nulls"); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected a conversion error for NULL elements, got %v", err)
+	}
+	if _, err := row.GetInt64Slice("tags"); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected a conversion error for \"tags\", got %v", err)
+	}
+	if missing, err := row.GetStringSlice("missing"); err != nil || missing != nil {
+		t.Errorf("expected nil for NULL \"missing\", got %v %v", missing, err)
+	}
+}
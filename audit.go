@@ -0,0 +1,159 @@
+package ksql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record of a data-modifying statement, as written by AuditHook
+type AuditRecord struct {
+	Time         time.Time `json:"time"` // when the statement started
+	DB           string    `json:"db"`   // name of the database connection
+	Fingerprint  string    `json:"fingerprint"`
+	User         string    `json:"user,omitempty"`   // see ContextWithAudit
+	Tenant       string    `json:"tenant,omitempty"` // see ContextWithAudit
+	RowsAffected int64     `json:"rows_affected"`    // -1 when unknown
+	Err          string    `json:"error,omitempty"`  // error of the statement
+}
+
+// Destination of the records of AuditHook, such as NewAuditWriter, NewAuditChannel and
+// NewAuditTable
+type AuditSink interface {
+	WriteAudit(ctx context.Context, record AuditRecord) error
+}
+
+// Hook recording every exec of the databases it is added to, and every query that modifies
+// data such as an INSERT ... RETURNING of ExecReturningID, including the ones of transactions
+// and prepared statements and the failed ones, to the sink, e.g.
+//
+//	file, _ := os.OpenFile("audit.log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+//	ksql.AddHook(&ksql.AuditHook{Sink: ksql.NewAuditWriter(file)})
+//
+// Records are written before the exec returns, and OnError is called when the sink fails,
+// if set. The statements are recorded by fingerprint, without their literal values or
+// arguments.
+type AuditHook struct {
+	Sink    AuditSink
+	OnError func(err error, record AuditRecord)
+}
+
+type auditKey struct{}
+
+// User and tenant of the statements run with a context, recorded by AuditHook
+type auditActor struct {
+	user   string
+	tenant string
+}
+
+// Set the user and tenant recorded by AuditHook for the statements run with the context
+func ContextWithAudit(ctx context.Context, user, tenant string) context.Context {
+	return context.WithValue(ctx, auditKey{}, auditActor{user: user, tenant: tenant})
+}
+
+// Statements of NewAuditTable are not audited themselves
+type auditSinkKey struct{}
+
+func (h *AuditHook) BeforeQuery(ctx context.Context, event *QueryEvent) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *AuditHook) AfterQuery(ctx context.Context, event *QueryEvent) {
+	if (event.Op != OpExec && event.Op != OpQuery) || ctx.Value(auditSinkKey{}) != nil {
+		return
+	}
+	fingerprint := Fingerprint(event.Query)
+	if event.Op == OpQuery && !modifiesData(fingerprint) {
+		return
+	}
+	record := AuditRecord{Time: event.Start, DB: event.DB, Fingerprint: fingerprint,
+		RowsAffected: event.RowsAffected}
+	if actor, ok := ctx.Value(auditKey{}).(auditActor); ok {
+		record.User, record.Tenant = actor.user, actor.tenant
+	}
+	if event.Err != nil {
+		record.Err = event.Err.Error()
+	}
+	if err := h.Sink.WriteAudit(ctx, record); err != nil && h.OnError != nil {
+		h.OnError(err, record)
+	}
+}
+
+var modifyingStatement = regexp.MustCompile(`(?i)^\s*(INSERT|UPDATE|DELETE|MERGE|REPLACE)\b`)
+
+// Check whether the fingerprint of a query is a statement modifying data that returns rows,
+// such as an INSERT ... RETURNING, or an UPDATE ... OUTPUT of SQL Server
+func modifiesData(fingerprint string) bool {
+	return modifyingStatement.MatchString(fingerprint) || returningClause.MatchString(fingerprint)
+}
+
+// Sink writing the records as lines of JSON, such as to an append-only file
+func NewAuditWriter(w io.Writer) AuditSink {
+	return &auditWriter{w: w}
+}
+
+type auditWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *auditWriter) WriteAudit(ctx context.Context, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// Sink sending the records to a channel, to be processed in the background. Sending waits
+// for the channel to accept the record, or for the context of the statement to end.
+func NewAuditChannel(ch chan<- AuditRecord) AuditSink {
+	return auditChannel(ch)
+}
+
+type auditChannel chan<- AuditRecord
+
+func (ch auditChannel) WriteAudit(ctx context.Context, record AuditRecord) error {
+	select {
+	case ch <- record:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Sink inserting the records into a table of the database, with the columns occurred_at,
+// db, fingerprint, actor, tenant, rows_affected and error. The inserts are not audited.
+func NewAuditTable(db *DB, table string) AuditSink {
+	columns := []string{"occurred_at", "db", "fingerprint", "actor", "tenant", "rows_affected", "error"}
+	var b strings.Builder
+	b.WriteString("INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (")
+	for i := range columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		db.bind.placeholder(&b, i+1)
+	}
+	b.WriteByte(')')
+	return &auditTable{db: db, insert: b.String()}
+}
+
+type auditTable struct {
+	db     *DB
+	insert string
+}
+
+func (s *auditTable) WriteAudit(ctx context.Context, record AuditRecord) error {
+	// the record is kept even when the audited statement was canceled
+	ctx = context.WithValue(context.WithoutCancel(ctx), auditSinkKey{}, true)
+	_, err := s.db.ExecContext(ctx, s.insert, record.Time, record.DB, record.Fingerprint, record.User,
+		record.Tenant, record.RowsAffected, record.Err)
+	return err
+}
@@ -0,0 +1,98 @@
+package ksql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAuditHook(t *testing.T) {
+	defer Close()
+	var buf bytes.Buffer
+	db, err := New("audit", "ksql_hosts", "audit", WithHook(&AuditHook{Sink: NewAuditWriter(&buf)}), WithDialect(DialectPostgres))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := ContextWithAudit(context.Background(), "jd", "acme")
+	if _, err := db.ExecContext(ctx, "update people set name = 'john doe' where id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	db.Exec("fail")
+	rows, err := db.Query("select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	// run as a query with a RETURNING clause on postgres
+	if _, err := db.ExecReturningID("insert into people (name) values ($1)", "id", "jd"); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected the 2 execs and the insert to be recorded, got %q", lines)
+	}
+	var record AuditRecord
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.DB != "audit" || record.User != "jd" || record.Tenant != "acme" || record.RowsAffected != 1 ||
+		record.Fingerprint != Fingerprint("update people set name = 'john doe' where id = 1") || record.Time.IsZero() {
+		t.Errorf("unexpected record %+v", record)
+	}
+	if strings.Contains(lines[0], "john doe") {
+		t.Errorf("expected the literal values to be left out, got %s", lines[0])
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil || record.Err != "statement failed" {
+		t.Errorf("expected the failed exec to be recorded, got %+v %v", record, err)
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &record); err != nil || !strings.HasPrefix(record.Fingerprint, "insert") {
+		t.Errorf("expected the insert returning its id to be recorded, got %+v %v", record, err)
+	}
+}
+
+func TestAuditSinks(t *testing.T) {
+	defer Close()
+	records := make(chan AuditRecord, 10)
+	var failed error
+	hook := &AuditHook{Sink: NewAuditChannel(records)}
+	db, err := New("audit sinks", "ksql_hosts", "audit sinks", WithHook(hook))
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := &AuditHook{Sink: NewAuditTable(db, "audit_log"), OnError: func(err error, record AuditRecord) { failed = err }}
+	AddHook(table)
+	defer func() {
+		hooksMu.Lock()
+		hooks = hooks[:len(hooks)-1]
+		hooksMu.Unlock()
+	}()
+	if _, err := db.Exec("delete people"); err != nil {
+		t.Fatal(err)
+	}
+	if failed != nil {
+		t.Fatal(failed)
+	}
+	want := "INSERT INTO audit_log (occurred_at, db, fingerprint, actor, tenant, rows_affected, error) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	if got := testHosts.lastQuery(); got != want {
+		t.Errorf("expected the record to be inserted, got %q", got)
+	}
+	// the insert of the record is not audited itself
+	if len(records) != 1 {
+		t.Fatalf("expected a single record, got %d", len(records))
+	}
+	if record := <-records; record.Fingerprint != Fingerprint("delete people") {
+		t.Errorf("unexpected record %+v", record)
+	}
+
+	full := &AuditHook{Sink: NewAuditChannel(make(chan AuditRecord)), OnError: func(err error, record AuditRecord) { failed = err }}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	full.AfterQuery(ctx, &QueryEvent{Op: OpExec, Query: "delete people"})
+	if !errors.Is(failed, context.Canceled) {
+		t.Errorf("expected the full channel to give up with the context, got %v", failed)
+	}
+}
@@ -0,0 +1,181 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+)
+
+// Default maximum number of rows inserted by a single statement of a batch
+const DefaultBatchSize = 1000
+
+// Statement text and arguments, as generated by the helpers of this package
+type Statement struct {
+	Query string
+	Args  []interface{}
+}
+
+// Set the maximum number of rows inserted by a single statement of BatchInsert
+func WithBatchSize(n int) Option {
+	return func(db *DB) error {
+		db.batchSize = n
+		return nil
+	}
+}
+
+// Generate multi-row INSERT statements of rows into the columns of table, in chunks of at most
+// batchSize rows, or DefaultBatchSize when 0. Chunks are made smaller when needed to stay
+// within the placeholder and row limits of the dialect. rows is a slice of []interface{}
+// holding the values in column order, or a slice of structs or struct pointers whose fields
+// are matched to the columns like Rows.ScanStruct. It fails with ErrInvalidBatch when a
+// single row has more columns than the placeholder limit.
+func BatchInsertSQL(dialect Dialect, style BindStyle, table string, columns []string, rows interface{}, batchSize int) ([]Statement, error) {
+	v, values, err := batchValues(rows, columns)
+	if err != nil {
+		return nil, err
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	placeholders, maxRows := batchLimits(dialect)
+	max := placeholders / len(columns)
+	if max == 0 {
+		// a single row already has more values than placeholders allowed
+		return nil, ErrInvalidBatch
+	}
+	if maxRows > 0 && max > maxRows {
+		max = maxRows
+	}
+	if batchSize > max {
+		batchSize = max
+	}
+	prefix := "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES "
+	var statements []Statement
+	for start := 0; start < v.Len(); start += batchSize {
+		end := start + batchSize
+		if end > v.Len() {
+			end = v.Len()
+		}
+		var b strings.Builder
+		b.WriteString(prefix)
+		args := make([]interface{}, 0, (end-start)*len(columns))
+		for i := start; i < end; i++ {
+			if i > start {
+				b.WriteString(", ")
+			}
+			b.WriteByte('(')
+			row, err := values(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			for j := range row {
+				if j > 0 {
+					b.WriteString(", ")
+				}
+				style.placeholder(&b, len(args)+j+1)
+			}
+			b.WriteByte(')')
+			args = append(args, row...)
+		}
+		statements = append(statements, Statement{Query: b.String(), Args: args})
+	}
+	return statements, nil
+}
+
+// Get the highest number of placeholders a single statement of the dialect may have, and
+// of rows in its VALUES clause, 0 when unbounded. Unknown dialects get the limit of the
+// SQLite versions before 3.32, the lowest of all.
+func batchLimits(dialect Dialect) (placeholders, rows int) {
+	switch dialect {
+	case DialectPostgres, DialectMySQL:
+		return 65535, 0
+	case DialectSQLServer:
+		return 2100 - 1, 1000
+	case DialectOracle:
+		return 1000, 0
+	}
+	return 999, 0
+}
+
+// Get the slice of rows and the function extracting the column values of its elements
+func batchValues(rows interface{}, columns []string) (reflect.Value, func(reflect.Value) ([]interface{}, error), error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice || len(columns) == 0 {
+		return v, nil, ErrInvalidBatch
+	}
+	elemType := v.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return v, func(row reflect.Value) ([]interface{}, error) {
+			values, ok := row.Interface().([]interface{})
+			if !ok || len(values) != len(columns) {
+				return nil, ErrInvalidBatch
+			}
+			return values, nil
+		}, nil
+	}
+	fields := fieldsOf(elemType)
+	indexes := make([][]int, len(columns))
+	for i, column := range columns {
+		index, ok := fields[column]
+		if !ok {
+			return v, nil, ErrInvalidBatch
+		}
+		indexes[i] = index
+	}
+	return v, func(row reflect.Value) ([]interface{}, error) {
+		if row.Kind() == reflect.Ptr {
+			if row.IsNil() {
+				return nil, ErrInvalidBatch
+			}
+			row = row.Elem()
+		}
+		values := make([]interface{}, len(indexes))
+		for i, index := range indexes {
+			values[i] = row.FieldByIndex(index).Interface()
+		}
+		return values, nil
+	}, nil
+}
+
+// Statements can be run on both a DB and a Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func execAll(ctx context.Context, e execer, statements []Statement) (int64, error) {
+	var total int64
+	for _, statement := range statements {
+		result, err := e.ExecContext(ctx, statement.Query, statement.Args...)
+		if err != nil {
+			return total, err
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			total += n
+		}
+	}
+	return total, nil
+}
+
+// Insert rows into the columns of table with multi-row INSERT statements, see BatchInsertSQL.
+// The statements run one after the other, use Tx.BatchInsert to insert all rows or none.
+// The total number of rows affected is returned.
+func (db *DB) BatchInsert(ctx context.Context, table string, columns []string, rows interface{}) (int64, error) {
+	statements, err := BatchInsertSQL(db.dialect, db.bind, table, columns, rows, db.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	return execAll(ctx, db, statements)
+}
+
+// Insert rows into the columns of table with multi-row INSERT statements, see BatchInsertSQL
+func (tx *Tx) BatchInsert(ctx context.Context, table string, columns []string, rows interface{}) (int64, error) {
+	statements, err := BatchInsertSQL(tx.db.dialect, tx.db.bind, table, columns, rows, tx.db.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	return execAll(ctx, tx, statements)
+}
@@ -0,0 +1,110 @@
+package ksql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestBatchInsertSQL(t *testing.T) {
+	type person struct {
+		ID   int64
+		Name string `ksql:"full_name"`
+	}
+	people := []person{{1, "john doe"}, {2, "jane doe"}, {3, "baby doe"}}
+	statements, err := BatchInsertSQL(DialectPostgres, BindDollar, "people", []string{"id", "full_name"}, people, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Statement{
+		{"INSERT INTO people (id, full_name) VALUES ($1, $2), ($3, $4)", []interface{}{int64(1), "john doe", int64(2), "jane doe"}},
+		{"INSERT INTO people (id, full_name) VALUES ($1, $2)", []interface{}{int64(3), "baby doe"}},
+	}
+	if !reflect.DeepEqual(statements, want) {
+		t.Errorf("expected %v, got %v", want, statements)
+	}
+	statements, err = BatchInsertSQL(DialectMySQL, BindQuestion, "people", []string{"id", "name"}, [][]interface{}{{1, "john doe"}, {2, "jane doe"}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []Statement{{"INSERT INTO people (id, name) VALUES (?, ?), (?, ?)", []interface{}{1, "john doe", 2, "jane doe"}}}
+	if !reflect.DeepEqual(statements, want) {
+		t.Errorf("expected %v, got %v", want, statements)
+	}
+	ptrs := []*person{&people[0], &people[1]}
+	statements, err = BatchInsertSQL(DialectSQLServer, BindAt, "people", []string{"full_name"}, ptrs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statements) != 1 || statements[0].Query != "INSERT INTO people (full_name) VALUES (@p1), (@p2)" {
+		t.Errorf("expected a single statement of struct pointers, got %v", statements)
+	}
+	many := make([][]interface{}, 2000)
+	for i := range many {
+		many[i] = []interface{}{i, "doe", true}
+	}
+	chunks := []struct {
+		dialect Dialect
+		columns []string
+		rows    []int
+	}{
+		// 2099 placeholders, so 699 rows of 3 columns
+		{DialectSQLServer, []string{"id", "name", "married"}, []int{699, 699, 602}},
+		// at most 1000 rows in the VALUES clause
+		{DialectSQLServer, []string{"id"}, []int{1000, 1000}},
+		// 999 placeholders before SQLite 3.32, so 499 rows of 2 columns
+		{DialectSQLite, []string{"id", "name"}, []int{499, 499, 499, 499, 4}},
+		{DialectOracle, []string{"id", "name"}, []int{500, 500, 500, 500}},
+		{DialectPostgres, []string{"id", "name"}, []int{2000}},
+		{DialectMySQL, []string{"id", "name"}, []int{2000}},
+	}
+	for _, test := range chunks {
+		rows := make([][]interface{}, len(many))
+		for i, row := range many {
+			rows[i] = row[:len(test.columns)]
+		}
+		statements, err := BatchInsertSQL(test.dialect, BindQuestion, "people", test.columns, rows, 5000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sizes []int
+		for _, statement := range statements {
+			sizes = append(sizes, len(statement.Args)/len(test.columns))
+		}
+		if !reflect.DeepEqual(sizes, test.rows) {
+			t.Errorf("%v: expected chunks of %v rows, got %v", test.dialect, test.rows, sizes)
+		}
+	}
+	if _, err := BatchInsertSQL(DialectPostgres, BindDollar, "people", []string{"id", "missing"}, people, 0); err != ErrInvalidBatch {
+		t.Errorf("expected an invalid batch error for a missing field, got %v", err)
+	}
+	if _, err := BatchInsertSQL(DialectPostgres, BindDollar, "people", []string{"id", "name"}, [][]interface{}{{1}}, 0); err != ErrInvalidBatch {
+		t.Errorf("expected an invalid batch error for a short row, got %v", err)
+	}
+	wide := make([]string, 1001)
+	for i := range wide {
+		wide[i] = fmt.Sprintf("c%d", i)
+	}
+	if _, err := BatchInsertSQL(DialectOracle, BindColon, "wide", wide, [][]interface{}{make([]interface{}, 1001)}, 0); err != ErrInvalidBatch {
+		t.Errorf("expected an invalid batch error for more columns than placeholders, got %v", err)
+	}
+}
+
+func TestBatchInsert(t *testing.T) {
+	defer Close()
+	hook := &recordingHook{}
+	db, err := New("hosts", "ksql_hosts", "hosts", WithBatchSize(2), WithHook(hook))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]interface{}{{1, "john doe"}, {2, "jane doe"}, {3, "baby doe"}}
+	n, err := db.BatchInsert(context.Background(), "people", []string{"id", "name"}, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every statement of the test driver affects a single row
+	if n != 2 || len(hook.after) != 2 {
+		t.Errorf("expected 2 statements, got %d affecting %d rows", len(hook.after), n)
+	}
+}
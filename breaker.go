@@ -0,0 +1,125 @@
+package ksql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// State of the circuit breaker of a database
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // statements run
+	BreakerOpen                         // statements fail with ErrCircuitOpen
+	BreakerHalfOpen                     // a single statement probes whether the database recovered
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// Outcome recorded for the statements stopped before reaching the database, by the
+// concurrency limit or a hook, which says nothing of the database
+var errNotRun = errors.New("ksql: statement not run")
+
+// Circuit breaker of a database, see WithCircuitBreaker
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	opened   time.Time
+	probing  bool
+}
+
+// Open the circuit of the database after the given number of consecutive failures, so its
+// statements and transactions fail right away with ErrCircuitOpen rather than waiting on a
+// database that is down. Once the cooldown has passed, the next statement is let through as
+// a probe: the circuit closes again when it succeeds and stays open for another cooldown when
+// it fails. Only failures to reach the database count, the errors returned by the database
+// itself, with an error code, show it is up. Replicas get a breaker of their own with the
+// same settings, and the queries of an open replica go to the primary.
+func WithCircuitBreaker(failures int, cooldown time.Duration) Option {
+	return func(db *DB) error {
+		if failures <= 0 {
+			db.breaker = nil
+			return nil
+		}
+		db.breaker = &breaker{threshold: failures, cooldown: cooldown}
+		return nil
+	}
+}
+
+// Get the state of the circuit breaker of the database, always closed without one
+func (db *DB) BreakerState() BreakerState {
+	if db.breaker == nil {
+		return BreakerClosed
+	}
+	db.breaker.mu.Lock()
+	defer db.breaker.mu.Unlock()
+	return db.breaker.state
+}
+
+// Get a breaker with the same settings, in the closed state
+func (b *breaker) clone() *breaker {
+	if b == nil {
+		return nil
+	}
+	return &breaker{threshold: b.threshold, cooldown: b.cooldown}
+}
+
+// Check whether a statement may run, the returned function records its outcome
+func (b *breaker) allow() (func(error), error) {
+	if b == nil {
+		return func(error) {}, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.opened) < b.cooldown {
+			return nil, ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+	case BreakerHalfOpen:
+		if b.probing {
+			return nil, ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return b.record, nil
+}
+
+// Record the outcome of a statement let through
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	probe := b.state == BreakerHalfOpen
+	if probe {
+		b.probing = false
+	}
+	switch {
+	case err == nil || errors.Is(err, ErrNoRows) || errorCode(err) != "":
+		b.state = BreakerClosed
+		b.failures = 0
+	case err == errNotRun || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		// the statement never ran or the caller gave up, neither says anything of the database
+	default:
+		b.failures++
+		if probe || b.failures >= b.threshold {
+			b.state = BreakerOpen
+			b.opened = time.Now()
+		}
+	}
+}
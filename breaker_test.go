@@ -0,0 +1,99 @@
+package ksql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	defer Close()
+	db, err := New("tripping", "ksql_hosts", "tripping", WithCircuitBreaker(2, 20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if db.BreakerState() != BreakerClosed {
+			t.Fatalf("expected the circuit to be closed after %d failures", i)
+		}
+		if _, err := db.Exec("fail"); err == nil {
+			t.Fatal("expected the statement to fail")
+		}
+	}
+	if db.BreakerState() != BreakerOpen {
+		t.Fatalf("expected the circuit to open, got %v", db.BreakerState())
+	}
+	if _, err := db.Query("select people"); err != ErrCircuitOpen {
+		t.Errorf("expected to fail fast, got %v", err)
+	}
+	if _, err := db.Begin(); err != ErrCircuitOpen {
+		t.Errorf("expected transactions to fail fast, got %v", err)
+	}
+
+	// a failed probe opens the circuit for another cooldown
+	time.Sleep(30 * time.Millisecond)
+	if _, err := db.Exec("fail"); err == nil || err == ErrCircuitOpen {
+		t.Fatalf("expected the probe to run and fail, got %v", err)
+	}
+	if _, err := db.Exec("update people"); err != ErrCircuitOpen {
+		t.Errorf("expected to fail fast after the probe, got %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, err := db.Exec("update people"); err != nil {
+		t.Fatalf("expected the probe to succeed, got %v", err)
+	}
+	if db.BreakerState() != BreakerClosed {
+		t.Errorf("expected the circuit to close, got %v", db.BreakerState())
+	}
+}
+
+func TestBreakerRecord(t *testing.T) {
+	b := &breaker{threshold: 1, cooldown: time.Hour}
+	for _, err := range []error{&pqError{"23505"}, ErrNoRows, context.Canceled, errNotRun} {
+		record, err2 := b.allow()
+		if err2 != nil {
+			t.Fatal(err2)
+		}
+		record(err)
+		if b.state != BreakerClosed {
+			t.Errorf("%v: expected the circuit to stay closed", err)
+		}
+	}
+	record, _ := b.allow()
+	record(errors.New("connection refused"))
+	if b.state != BreakerOpen {
+		t.Errorf("expected the circuit to open")
+	}
+	if BreakerHalfOpen.String() != "half-open" {
+		t.Errorf("unexpected name %q", BreakerHalfOpen)
+	}
+}
+
+func TestCircuitBreakerReplica(t *testing.T) {
+	defer Close()
+	hook := &recordingHook{}
+	db, err := New("breaker primary", "ksql_hosts", "breaker primary", WithReadPreference(ReadReplica),
+		WithCircuitBreaker(1, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replica, err := NewReplica("breaker primary", "ksql_hosts", "breaker replica", WithHook(hook))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := replica.Query("fail"); err == nil {
+		t.Fatal("expected the query to fail")
+	}
+	if replica.BreakerState() != BreakerOpen || db.BreakerState() != BreakerClosed {
+		t.Fatalf("expected only the replica circuit to open, got %v and %v", replica.BreakerState(), db.BreakerState())
+	}
+	rows, err := db.Query("select people")
+	if err != nil {
+		t.Fatalf("expected the primary to take the query, got %v", err)
+	}
+	rows.Close()
+	if len(hook.before) != 1 {
+		t.Errorf("expected the open replica not to be queried, got %+v", hook.before)
+	}
+}
@@ -0,0 +1,88 @@
+package ksql
+
+import (
+	"slices"
+	"sync"
+)
+
+// Scan buffers of the values of a result set, recycled from one query to the next
+type rowBuffers struct {
+	values []interface{}
+	loader []interface{}
+}
+
+var (
+	buffersPool = sync.Pool{New: func() interface{} { return new(rowBuffers) }}
+	rowsPool    = sync.Pool{New: func() interface{} { return new(Rows) }}
+)
+
+// Get scan buffers for n columns, the loader pointing to the values
+func getBuffers(n int) *rowBuffers {
+	b := buffersPool.Get().(*rowBuffers)
+	if cap(b.values) < n {
+		b.values = make([]interface{}, n)
+		b.loader = make([]interface{}, n)
+	}
+	b.values = b.values[:n]
+	b.loader = b.loader[:n]
+	for i := range b.loader {
+		b.loader[i] = &b.values[i]
+	}
+	return b
+}
+
+// Give the scan buffers back to the pool, unless the rows are retained
+func (rs *Rows) releaseBuffers() {
+	if rs.buffers == nil || rs.retain {
+		return
+	}
+	// drop the values, so the pool doesn't keep them alive
+	clear(rs.buffers.values)
+	buffersPool.Put(rs.buffers)
+	rs.buffers = nil
+	rs.values = nil
+	rs.loader = nil
+}
+
+// Copy the values of the last row out of the scan buffers, and give the buffers back to the
+// pool, so the getters still read the row once the rows are closed
+func (rs *Rows) detachBuffers() {
+	if rs.buffers == nil || rs.retain {
+		return
+	}
+	values := slices.Clone(rs.values)
+	rs.releaseBuffers()
+	rs.values = values
+}
+
+// Keep the scan buffers of the rows when they are closed, rather than copying the last row
+// out of them and recycling them, see WithoutRowsPooling
+func (rs *Rows) Retain() {
+	rs.retain = true
+}
+
+// Allocate the scan buffers of the rows of the database rather than recycling them. Either
+// way the last row read can be read again after Close, which copies it out of recycled
+// buffers; the option saves the copy for callers that mostly read rows once closed.
+func WithoutRowsPooling() Option {
+	return func(db *DB) error {
+		db.noPool = true
+		return nil
+	}
+}
+
+// Get rows from the pool, to be set up by the caller
+func (db *DB) pooledRows() *Rows {
+	return rowsPool.Get().(*Rows)
+}
+
+// Give rows that were only used inside ksql, and are closed, back to the pool. Rows ksql
+// did not take from the pool, such as the ones of a Querier wrapping a DB, are left alone.
+func putRows(rs *Rows) {
+	if rs == nil || !rs.pooled {
+		return
+	}
+	rs.releaseBuffers()
+	*rs = Rows{}
+	rowsPool.Put(rs)
+}
@@ -0,0 +1,158 @@
+package ksql
+
+import (
+	"context"
+	"strings"
+)
+
+// Builder of SELECT statements. Conditions are written with ? placeholders, which are
+// rewritten for the driver when the SQL is generated, like the limit and offset for the
+// dialect. The result is plain SQL and arguments, usable with any Query or Exec method.
+type SelectBuilder struct {
+	columns []string
+	from    string
+	joins   []string
+	where   []string
+	groupBy []string
+	orderBy []string
+	// join arguments always precede the where arguments in the generated SQL
+	joinArgs  []interface{}
+	whereArgs []interface{}
+	limit     int
+	offset    int
+}
+
+// Start a SELECT statement of the given columns, or * when none are given
+func Select(columns ...string) *SelectBuilder {
+	return &SelectBuilder{columns: columns, limit: -1, offset: -1}
+}
+
+// Set the table, or any other FROM expression, to select from
+func (b *SelectBuilder) From(table string) *SelectBuilder {
+	b.from = table
+	return b
+}
+
+// Add an inner join, e.g. Join("orders o on o.person_id = p.id")
+func (b *SelectBuilder) Join(join string, args ...interface{}) *SelectBuilder {
+	return b.join("JOIN", join, args)
+}
+
+// Add a left outer join
+func (b *SelectBuilder) LeftJoin(join string, args ...interface{}) *SelectBuilder {
+	return b.join("LEFT JOIN", join, args)
+}
+
+func (b *SelectBuilder) join(kind, join string, args []interface{}) *SelectBuilder {
+	b.joinArgs = append(b.joinArgs, args...)
+	b.joins = append(b.joins, kind+" "+join)
+	return b
+}
+
+// Add a condition, multiple conditions are combined with AND
+func (b *SelectBuilder) Where(condition string, args ...interface{}) *SelectBuilder {
+	b.where = append(b.where, condition)
+	b.whereArgs = append(b.whereArgs, args...)
+	return b
+}
+
+// Add columns to group by
+func (b *SelectBuilder) GroupBy(columns ...string) *SelectBuilder {
+	b.groupBy = append(b.groupBy, columns...)
+	return b
+}
+
+// Add columns to order by, e.g. OrderBy("name", "id desc")
+func (b *SelectBuilder) OrderBy(columns ...string) *SelectBuilder {
+	b.orderBy = append(b.orderBy, columns...)
+	return b
+}
+
+// Limit the number of rows returned
+func (b *SelectBuilder) Limit(n int) *SelectBuilder {
+	b.limit = n
+	return b
+}
+
+// Skip a number of rows before returning any
+func (b *SelectBuilder) Offset(n int) *SelectBuilder {
+	b.offset = n
+	return b
+}
+
+// Generate the SQL and arguments in the syntax of the dialect, with placeholders of the
+// given style
+func (b *SelectBuilder) ToSQL(dialect Dialect, style BindStyle) (string, []interface{}) {
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	if len(b.columns) == 0 {
+		sb.WriteString("*")
+	} else {
+		sb.WriteString(strings.Join(b.columns, ", "))
+	}
+	if b.from != "" {
+		sb.WriteString(" FROM ")
+		sb.WriteString(b.from)
+	}
+	for _, join := range b.joins {
+		sb.WriteString(" ")
+		sb.WriteString(join)
+	}
+	if len(b.where) == 1 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(b.where[0])
+	} else if len(b.where) > 1 {
+		sb.WriteString(" WHERE (")
+		sb.WriteString(strings.Join(b.where, ") AND ("))
+		sb.WriteString(")")
+	}
+	if len(b.groupBy) > 0 {
+		sb.WriteString(" GROUP BY ")
+		sb.WriteString(strings.Join(b.groupBy, ", "))
+	}
+	paged := b.limit >= 0 || b.offset > 0
+	if len(b.orderBy) > 0 {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(strings.Join(b.orderBy, ", "))
+	} else if paged && dialect == DialectSQLServer {
+		// OFFSET FETCH is only allowed after an ORDER BY
+		sb.WriteString(" ORDER BY (SELECT NULL)")
+	}
+	if paged {
+		writeLimit(&sb, dialect, b.limit, int64(max(b.offset, 0)))
+	}
+	args := make([]interface{}, 0, len(b.joinArgs)+len(b.whereArgs))
+	args = append(append(args, b.joinArgs...), b.whereArgs...)
+	return Rebind(style, sb.String()), args
+}
+
+// Run the generated query on the database or in the transaction
+func (b *SelectBuilder) Query(q Querier) (*Rows, error) {
+	return b.QueryContext(context.Background(), q)
+}
+
+func (b *SelectBuilder) QueryContext(ctx context.Context, q Querier) (*Rows, error) {
+	query, args := b.ToSQL(syntaxOf(q))
+	return q.QueryContext(ctx, query, args...)
+}
+
+// Run the generated query on the database or in the transaction, expecting a single row
+func (b *SelectBuilder) QueryRow(q Querier) *Row {
+	return b.QueryRowContext(context.Background(), q)
+}
+
+func (b *SelectBuilder) QueryRowContext(ctx context.Context, q Querier) *Row {
+	rows, err := b.QueryContext(ctx, q)
+	return &Row{rows: rows, err: err}
+}
+
+// Get the dialect and placeholder style of the statements of a DB or Tx
+func syntaxOf(q Querier) (Dialect, BindStyle) {
+	switch q := q.(type) {
+	case *DB:
+		return q.dialect, q.bind
+	case *Tx:
+		return q.db.dialect, q.db.bind
+	}
+	return DialectUnknown, BindQuestion
+}
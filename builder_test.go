@@ -0,0 +1,83 @@
+package ksql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectBuilder(t *testing.T) {
+	tests := []struct {
+		builder *SelectBuilder
+		dialect Dialect
+		style   BindStyle
+		want    string
+		args    []interface{}
+	}{
+		{Select().From("people"), DialectPostgres, BindDollar, "SELECT * FROM people", []interface{}{}},
+		{
+			Select("id", "name").From("people").Where("married = ?", true).Where("ratio > ?", 1.5).OrderBy("name", "id desc").Limit(10).Offset(20),
+			DialectPostgres,
+			BindDollar,
+			"SELECT id, name FROM people WHERE (married = $1) AND (ratio > $2) ORDER BY name, id desc LIMIT 10 OFFSET 20",
+			[]interface{}{true, 1.5},
+		},
+		{
+			Select("p.name", "count(o.id)").From("people p").Where("p.name <> '?'").Where("p.id > ?", 1).LeftJoin("orders o on o.person_id = p.id and o.status = ?", "open").GroupBy("p.name"),
+			DialectMySQL,
+			BindQuestion,
+			"SELECT p.name, count(o.id) FROM people p LEFT JOIN orders o on o.person_id = p.id and o.status = ? WHERE (p.name <> '?') AND (p.id > ?) GROUP BY p.name",
+			[]interface{}{"open", 1},
+		},
+		{Select("id").From("people").Join("orders o on o.person_id = people.id").Where("id = ?", 1), DialectSQLServer, BindAt, "SELECT id FROM people JOIN orders o on o.person_id = people.id WHERE id = @p1", []interface{}{1}},
+		{Select("id").From("people").OrderBy("id").Limit(10).Offset(20), DialectSQLServer, BindAt, "SELECT id FROM people ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY", []interface{}{}},
+		{Select("id").From("people").OrderBy("id").Offset(20), DialectOracle, BindColon, "SELECT id FROM people ORDER BY id OFFSET 20 ROWS", []interface{}{}},
+		{Select("id").From("people").Limit(5), DialectSQLite, BindQuestion, "SELECT id FROM people LIMIT 5", []interface{}{}},
+		{Select("id").From("people").Offset(20), DialectSQLite, BindQuestion, "SELECT id FROM people LIMIT -1 OFFSET 20", []interface{}{}},
+		{Select("id").From("people").Offset(20), DialectMySQL, BindQuestion, "SELECT id FROM people LIMIT 18446744073709551615 OFFSET 20", []interface{}{}},
+		{Select("id").From("people").Offset(20), DialectPostgres, BindDollar, "SELECT id FROM people OFFSET 20", []interface{}{}},
+		{Select("id").From("people").Limit(5).Offset(20), DialectMySQL, BindQuestion, "SELECT id FROM people LIMIT 5 OFFSET 20", []interface{}{}},
+		{Select("id").From("people").Limit(5), DialectSQLServer, BindAt, "SELECT id FROM people ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY", []interface{}{}},
+		{Select("id").From("people").Offset(20), DialectSQLServer, BindAt, "SELECT id FROM people ORDER BY (SELECT NULL) OFFSET 20 ROWS", []interface{}{}},
+		{Select("id").From("people").Limit(5), DialectOracle, BindColon, "SELECT id FROM people OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY", []interface{}{}},
+	}
+	for _, test := range tests {
+		query, args := test.builder.ToSQL(test.dialect, test.style)
+		if query != test.want {
+			t.Errorf("expected %q, got %q", test.want, query)
+		}
+		if !reflect.DeepEqual(args, test.args) {
+			t.Errorf("%q: expected args %v, got %v", query, test.args, args)
+		}
+	}
+}
+
+func TestSelectBuilderTx(t *testing.T) {
+	defer Close()
+	db, err := New("builder", "ksql_hosts", "builder", WithDialect(DialectSQLServer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	id, err := Select("id").From("people").OrderBy("id").Limit(1).QueryRow(tx).GetInteger("id")
+	if err != nil || id != 1 {
+		t.Errorf("expected to query in the transaction, got %v %v", id, err)
+	}
+	if query := testHosts.lastQuery(); query != "SELECT id FROM people ORDER BY id OFFSET 0 ROWS FETCH NEXT 1 ROWS ONLY" {
+		t.Errorf("expected the limit in the syntax of the dialect, got %q", query)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := "select * from people where id = ? and name = '?' -- ?\nand ratio > ?"
+	if got := Rebind(BindQuestion, query); got != query {
+		t.Errorf("expected %q, got %q", query, got)
+	}
+	want := "select * from people where id = $1 and name = '?' -- ?\nand ratio > $2"
+	if got := Rebind(BindDollar, query); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
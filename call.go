@@ -0,0 +1,267 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OUT or INOUT parameter of a stored procedure, see DB.Call
+type OutParam struct {
+	Name  string      // name the value is read by from CallResult.Out
+	Value interface{} // value passed in by an INOUT parameter
+	InOut bool
+}
+
+// Declare an OUT parameter of a stored procedure by the name its value is read by
+func Out(name string) OutParam {
+	return OutParam{Name: name}
+}
+
+// Declare an INOUT parameter of a stored procedure with the value passed in
+func InOut(name string, value interface{}) OutParam {
+	return OutParam{Name: name, Value: value, InOut: true}
+}
+
+// Result of a stored procedure call. The embedded rows read the result sets returned by
+// the procedure, with NextResultSet to move between them, and Out reads the OUT and INOUT
+// parameters. Close must be called when done, unless Out was.
+type CallResult struct {
+	*Rows
+	names []string
+	// values of the parameters once read, or how to read them after the rows
+	values []interface{}
+	read   func() ([]interface{}, error)
+	// connection the call runs on, held until the parameters are read
+	conn *sql.Conn
+}
+
+// Call the stored procedure with the arguments, in the syntax of the dialect of the database:
+//
+//	res, err := db.Call(ctx, "transfer", from, to, amount, ksql.Out("balance"))
+//	...
+//	out, err := res.Out()
+//	balance, err := out.GetDouble("balance")
+//
+// OUT and INOUT parameters are declared with Out and InOut in their position among the
+// arguments. Postgres procedures are run with CALL and return their parameters as a row,
+// mysql procedures with CALL and session variables on a dedicated connection, and sql server
+// procedures by name with sql.Out arguments, so their other arguments must be sql.Named.
+// Other dialects return ErrUnsupportedDialect.
+func (db *DB) Call(ctx context.Context, procedure string, args ...interface{}) (*CallResult, error) {
+	switch db.dialect {
+	case DialectPostgres:
+		return db.callPostgres(ctx, procedure, args)
+	case DialectMySQL:
+		return db.callMySQL(ctx, procedure, args)
+	case DialectSQLServer:
+		return db.callSQLServer(ctx, procedure, args)
+	}
+	return nil, ErrUnsupportedDialect
+}
+
+// Postgres returns the OUT and INOUT parameters of CALL as its only row, the OUT ones
+// being passed as NULL
+func (db *DB) callPostgres(ctx context.Context, procedure string, args []interface{}) (*CallResult, error) {
+	var b strings.Builder
+	b.WriteString("CALL ")
+	b.WriteString(procedure)
+	b.WriteByte('(')
+	var values []interface{}
+	var names []string
+	for i, arg := range args {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if p, ok := arg.(OutParam); ok {
+			names = append(names, p.Name)
+			if !p.InOut {
+				b.WriteString("NULL")
+				continue
+			}
+			arg = p.Value
+		}
+		values = append(values, arg)
+		db.bind.placeholder(&b, len(values))
+	}
+	b.WriteByte(')')
+	rows, err := db.QueryContext(ctx, b.String(), values...)
+	if err != nil {
+		return nil, err
+	}
+	res := &CallResult{Rows: rows, names: names, values: make([]interface{}, len(names))}
+	if len(names) > 0 && rows.Next() {
+		for i := range names {
+			if i < len(rows.columns) {
+				res.values[i] = rows.values[i]
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return res, nil
+}
+
+// MySQL sets the OUT and INOUT parameters of CALL into session variables, read once the
+// result sets are done on the same connection
+func (db *DB) callMySQL(ctx context.Context, procedure string, args []interface{}) (*CallResult, error) {
+	conn, err := db.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	b.WriteString("CALL ")
+	b.WriteString(procedure)
+	b.WriteByte('(')
+	var values []interface{}
+	var names, variables []string
+	for i, arg := range args {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		p, ok := arg.(OutParam)
+		if !ok {
+			values = append(values, arg)
+			b.WriteByte('?')
+			continue
+		}
+		variable := "@ksql_out_" + strconv.Itoa(len(names)+1)
+		if p.InOut {
+			if _, err := db.connExec(ctx, conn, "SET "+variable+" = ?", p.Value); err != nil {
+				conn.Close()
+				return nil, err
+			}
+		}
+		names = append(names, p.Name)
+		variables = append(variables, variable)
+		b.WriteString(variable)
+	}
+	b.WriteByte(')')
+	rows, err := db.connQuery(ctx, conn, b.String(), values...)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	res := &CallResult{Rows: rows, names: names, conn: conn}
+	res.read = func() ([]interface{}, error) {
+		values := make([]interface{}, len(names))
+		if len(names) == 0 {
+			return values, nil
+		}
+		dest := make([]interface{}, len(values))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		rows, err := db.connQuery(ctx, conn, "SELECT "+strings.Join(variables, ", "))
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		if !rows.Rows.Next() {
+			if err := rows.Err(); err != nil {
+				return nil, err
+			}
+			return nil, ErrNoRows
+		}
+		if err := rows.Rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		return values, rows.Close()
+	}
+	return res, nil
+}
+
+// SQL Server runs the procedure by name, filling the sql.Out arguments once the rows are
+// closed
+func (db *DB) callSQLServer(ctx context.Context, procedure string, args []interface{}) (*CallResult, error) {
+	var names []string
+	var outs []*interface{}
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		p, ok := arg.(OutParam)
+		if !ok {
+			values[i] = arg
+			continue
+		}
+		dest := new(interface{})
+		if p.InOut {
+			*dest = p.Value
+		}
+		names = append(names, p.Name)
+		outs = append(outs, dest)
+		values[i] = sql.Named(p.Name, sql.Out{Dest: dest, In: p.InOut})
+	}
+	// a comment would keep the driver from recognizing the name of the procedure
+	rows, err := db.QueryContext(context.WithValue(ctx, noCommentKey{}, true), procedure, values...)
+	if err != nil {
+		return nil, err
+	}
+	res := &CallResult{Rows: rows, names: names}
+	res.read = func() ([]interface{}, error) {
+		values := make([]interface{}, len(outs))
+		for i, dest := range outs {
+			values[i] = *dest
+		}
+		return values, nil
+	}
+	return res, nil
+}
+
+// Get the OUT and INOUT parameters by name, with the getters of rows, and close the call.
+// The result sets not read yet are discarded, as most drivers only return the parameters
+// after them.
+func (res *CallResult) Out() (*RowView, error) {
+	err := res.Rows.Close()
+	if err == nil && res.values == nil {
+		res.values, err = res.read()
+	}
+	if cerr := res.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &RowView{rows: detachedRows(res.db, res.opts, res.names, res.values)}, nil
+}
+
+// Close the result sets, and give back the connection of the call
+func (res *CallResult) Close() error {
+	err := res.Rows.Close()
+	if res.conn != nil {
+		if cerr := res.conn.Close(); err == nil {
+			err = cerr
+		}
+		res.conn = nil
+	}
+	return err
+}
+
+// Run a statement on a dedicated connection, with the hooks of the database
+func (db *DB) connExec(ctx context.Context, conn *sql.Conn, query string, args ...interface{}) (sql.Result, error) {
+	ctx, event, err := db.beforeQuery(ctx, OpExec, query, args)
+	if err != nil {
+		return nil, err
+	}
+	result, err := conn.ExecContext(ctx, db.annotate(ctx, query), args...)
+	db.afterQuery(ctx, event, result, err)
+	return result, db.mapError(err)
+}
+
+// Run a query on a dedicated connection, with the hooks of the database
+func (db *DB) connQuery(ctx context.Context, conn *sql.Conn, query string, args ...interface{}) (*Rows, error) {
+	ctx, event, err := db.beforeQuery(ctx, OpQuery, query, args)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	rows, err := conn.QueryContext(ctx, db.annotate(ctx, query), args...)
+	db.afterQuery(ctx, event, nil, err)
+	if err != nil {
+		return nil, db.mapError(err)
+	}
+	return db.newRows(rows, start), nil
+}
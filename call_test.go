@@ -0,0 +1,97 @@
+package ksql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestCallPostgres(t *testing.T) {
+	defer Close()
+	testHosts.setResult("CALL transfer($1, $2, NULL, $3)", []string{"balance", "count"},
+		[]driver.Value{float64(12.5), int64(4)})
+	db, err := New("hosts", "ksql_hosts", "hosts", WithDialect(DialectPostgres), WithBindStyle(BindDollar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := db.Call(context.Background(), "transfer", 1, 2, Out("balance"), InOut("count", 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := res.Out()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance, err := out.GetDouble("balance"); err != nil || balance != 12.5 {
+		t.Errorf("expected the balance, got %v %v", balance, err)
+	}
+	if count, err := out.GetInteger("count"); err != nil || count != 4 {
+		t.Errorf("expected the count, got %v %v", count, err)
+	}
+	if _, err := out.GetString("missing"); err != ErrColumnNotFound {
+		t.Errorf("expected an unknown parameter, got %v", err)
+	}
+}
+
+func TestCallMySQL(t *testing.T) {
+	defer Close()
+	hook := &recordingHook{}
+	testHosts.setResultSets("CALL report(?, @ksql_out_1, @ksql_out_2)",
+		&hostsRows{columns: []string{"id", "name"}, values: [][]driver.Value{{int64(1), "john doe"}}},
+		&hostsRows{columns: []string{"total"}, values: [][]driver.Value{{int64(42)}}})
+	testHosts.setResult("SELECT @ksql_out_1, @ksql_out_2", []string{"@ksql_out_1", "@ksql_out_2"},
+		[]driver.Value{"done", int64(2)})
+	db, err := New("hosts", "ksql_hosts", "hosts", WithDialect(DialectMySQL), WithHook(hook))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := db.Call(context.Background(), "report", 2024, Out("status"), InOut("pages", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Next() {
+		t.Fatal("expected the first result set")
+	}
+	if name, err := res.GetString("name"); err != nil || name != "john doe" {
+		t.Errorf("expected john doe, got %q %v", name, err)
+	}
+	if !res.NextResultSet() || !res.Next() {
+		t.Fatal("expected the second result set")
+	}
+	if total, err := res.GetInteger("total"); err != nil || total != 42 {
+		t.Errorf("expected the total, got %v %v", total, err)
+	}
+	out, err := res.Out()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, err := out.GetString("status"); err != nil || status != "done" {
+		t.Errorf("expected the status, got %q %v", status, err)
+	}
+	if pages, err := out.GetInteger("pages"); err != nil || pages != 2 {
+		t.Errorf("expected the pages, got %v %v", pages, err)
+	}
+	want := []string{"SET @ksql_out_2 = ?", "CALL report(?, @ksql_out_1, @ksql_out_2)", "SELECT @ksql_out_1, @ksql_out_2"}
+	if len(hook.before) != len(want) {
+		t.Fatalf("expected %d statements, got %+v", len(want), hook.before)
+	}
+	for i, query := range want {
+		if hook.before[i].Query != query {
+			t.Errorf("expected %q, got %q", query, hook.before[i].Query)
+		}
+	}
+	if stats := db.Stats(); stats.InUse != 0 {
+		t.Errorf("expected the connection to be given back, got %d in use", stats.InUse)
+	}
+}
+
+func TestCallUnsupported(t *testing.T) {
+	defer Close()
+	db, err := New("hosts", "ksql_hosts", "hosts", WithDialect(DialectSQLite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Call(context.Background(), "report"); err != ErrUnsupportedDialect {
+		t.Errorf("expected an unsupported dialect, got %v", err)
+	}
+}
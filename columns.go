@@ -0,0 +1,41 @@
+package ksql
+
+import "reflect"
+
+// Description of a result column, see sql.ColumnType. The Has and Known fields tell whether
+// the driver reported the value next to them.
+type ColumnInfo struct {
+	Name              string
+	Key               string // name the column is read by, see WithStrictColumns
+	DatabaseType      string
+	Nullable          bool
+	NullableKnown     bool
+	Length            int64
+	HasLength         bool
+	Precision         int64
+	Scale             int64
+	HasPrecisionScale bool
+	ScanType          reflect.Type
+}
+
+// Describe the columns of the result, in order
+func (rs *Rows) ColumnInfo() ([]ColumnInfo, error) {
+	types, err := rs.Rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.Name()
+	}
+	keys, _ := uniqueColumns(names)
+	infos := make([]ColumnInfo, len(types))
+	for i, t := range types {
+		info := ColumnInfo{Name: t.Name(), Key: keys[i], DatabaseType: t.DatabaseTypeName(), ScanType: t.ScanType()}
+		info.Nullable, info.NullableKnown = t.Nullable()
+		info.Length, info.HasLength = t.Length()
+		info.Precision, info.Scale, info.HasPrecisionScale = t.DecimalSize()
+		infos[i] = info
+	}
+	return infos, nil
+}
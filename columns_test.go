@@ -0,0 +1,39 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestColumnInfo(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select join", []string{"id", "name", "id"}, []driver.Value{int64(1), "john doe", int64(2)})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("select join")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	infos, err := rows.ColumnInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 3 {
+		t.Fatalf("expected 3 columns, got %d", len(infos))
+	}
+	for i, want := range []string{"id", "name", "id_2"} {
+		if infos[i].Key != want {
+			t.Errorf("expected the key %q for column %d, got %q", want, i, infos[i].Key)
+		}
+	}
+	if infos[2].Name != "id" {
+		t.Errorf("expected the name \"id\" for column 2, got %q", infos[2].Name)
+	}
+	// the test driver describes nothing beyond the names
+	if infos[0].NullableKnown || infos[0].HasLength || infos[0].HasPrecisionScale || infos[0].ScanType == nil {
+		t.Errorf("expected only the names and the default scan type, got %+v", infos[0])
+	}
+}
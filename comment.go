@@ -0,0 +1,111 @@
+package ksql
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Key values of the comments of the statements run with a context
+type commentKey struct{}
+
+// Statements run with a context holding it are left without comments, such as the bare
+// procedure names SQL Server runs as remote procedure calls
+type noCommentKey struct{}
+
+// Settings of the comments appended to the statements of a database, see WithSQLComment
+type commenter struct {
+	tags map[string]string
+	fn   func(ctx context.Context) map[string]string
+}
+
+// Append a comment in the sqlcommenter format, such as /*app='shop',traceparent='00-...'*/,
+// to the queries and execs of the database and its transactions, so the statements seen by
+// the database, e.g. in pg_stat_activity, can be matched to the application and its traces.
+// The comment holds the tags, the key values added to the context by ContextWithComment, and
+// the ones fn returns for the context, such as the traceparent of its span, when fn is not
+// nil. Statements that already hold a comment are left as they are, and so are prepared
+// statements, whose text is shared by their executions. Hooks see the statements without
+// the comment.
+func WithSQLComment(tags map[string]string, fn func(ctx context.Context) map[string]string) Option {
+	return func(db *DB) error {
+		c := &commenter{tags: make(map[string]string, len(tags)), fn: fn}
+		for k, v := range tags {
+			c.tags[k] = v
+		}
+		db.comment = c
+		return nil
+	}
+}
+
+// Add a key value to the comments of the statements run with the context, see WithSQLComment
+func ContextWithComment(ctx context.Context, key, value string) context.Context {
+	prev, _ := ctx.Value(commentKey{}).(map[string]string)
+	values := make(map[string]string, len(prev)+1)
+	for k, v := range prev {
+		values[k] = v
+	}
+	values[key] = value
+	return context.WithValue(ctx, commentKey{}, values)
+}
+
+// Get the statement with the comment of the database for the context appended
+func (db *DB) annotate(ctx context.Context, query string) string {
+	if db.comment == nil || ctx.Value(noCommentKey{}) != nil || strings.Contains(query, "/*") || strings.Contains(query, "--") {
+		return query
+	}
+	values := make(map[string]string, len(db.comment.tags))
+	for k, v := range db.comment.tags {
+		values[k] = v
+	}
+	if ctxValues, ok := ctx.Value(commentKey{}).(map[string]string); ok {
+		for k, v := range ctxValues {
+			values[k] = v
+		}
+	}
+	if db.comment.fn != nil {
+		for k, v := range db.comment.fn(ctx) {
+			values[k] = v
+		}
+	}
+	if len(values) == 0 {
+		return query
+	}
+	return appendComment(query, values)
+}
+
+// Append the key values as a comment of the statement, sorted by key, with the keys and
+// values URL encoded and the values quoted, before the final semicolon if any
+func appendComment(query string, values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	trimmed := strings.TrimRight(query, " \t\r\n")
+	end := strings.TrimSuffix(trimmed, ";")
+	b.WriteString(end)
+	b.WriteString(" /*")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(commentEscape(k))
+		b.WriteString("='")
+		b.WriteString(commentEscape(values[k]))
+		b.WriteByte('\'')
+	}
+	b.WriteString("*/")
+	if len(end) < len(trimmed) {
+		b.WriteByte(';')
+	}
+	return b.String()
+}
+
+// URL encode a key or value of a comment, with spaces as %20. Quotes are encoded too, so
+// values need no further escaping.
+func commentEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,82 @@
+package ksql
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAppendComment(t *testing.T) {
+	tests := []struct {
+		query  string
+		values map[string]string
+		want   string
+	}{
+		{"select 1", map[string]string{"app": "shop"}, "select 1 /*app='shop'*/"},
+		{"select 1;\n", map[string]string{"route": "/orders/{id}", "app": "shop"},
+			"select 1 /*app='shop',route='%2Forders%2F%7Bid%7D'*/;"},
+		{"select 1", map[string]string{"user's name": "it's me"}, "select 1 /*user%27s%20name='it%27s%20me'*/"},
+	}
+	for _, test := range tests {
+		if got := appendComment(test.query, test.values); got != test.want {
+			t.Errorf("%q: expected %q, got %q", test.query, test.want, got)
+		}
+	}
+}
+
+func TestSQLComment(t *testing.T) {
+	defer Close()
+	hook := &recordingHook{}
+	traceparent := func(ctx context.Context) map[string]string {
+		return map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	}
+	db, err := New("comment", "ksql_hosts", "comment", WithHook(hook),
+		WithSQLComment(map[string]string{"app": "shop"}, traceparent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := ContextWithComment(context.Background(), "route", "checkout")
+	if _, err := db.ExecContext(ctx, "update people"); err != nil {
+		t.Fatal(err)
+	}
+	want := "update people /*app='shop',route='checkout',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/"
+	if got := testHosts.lastQuery(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if last := hook.after[len(hook.after)-1]; last.Query != "update people" {
+		t.Errorf("expected the hooks to see the statement without the comment, got %q", last.Query)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	rows, err := tx.Query("select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	want = "select people /*app='shop',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/"
+	if got := testHosts.lastQuery(); got != want {
+		t.Errorf("expected the statements of transactions to be annotated, got %q", got)
+	}
+	if _, err := db.Exec("update people /* by hand */"); err != nil {
+		t.Fatal(err)
+	}
+	if got := testHosts.lastQuery(); got != "update people /* by hand */" {
+		t.Errorf("expected a statement with a comment to be left as is, got %q", got)
+	}
+	mssql, err := New("comment mssql", "ksql_hosts", "comment mssql", WithDialect(DialectSQLServer),
+		WithSQLComment(map[string]string{"app": "shop"}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := mssql.Call(context.Background(), "dbo.report", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Close()
+	if got := testHosts.lastQuery(); got != "dbo.report" {
+		t.Errorf("expected the procedure name of SQL Server to be left as is, got %q", got)
+	}
+}
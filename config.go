@@ -0,0 +1,178 @@
+package ksql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Named database connections described by a configuration file, e.g. in JSON
+//
+//	{"connections": {"master": {"driver": "postgres", "host": "db.local", "user": "app",
+//		"password": "${DB_PASSWORD}", "database": "shop", "max_open_conns": 20}}}
+type Config struct {
+	Connections map[string]ConnConfig `json:"connections" yaml:"connections" toml:"connections"`
+}
+
+// Configuration of a named database connection. The data source name is either given as is,
+// or built from its parts with DSN.
+type ConnConfig struct {
+	Driver          string            `json:"driver" yaml:"driver" toml:"driver"`
+	DSN             string            `json:"dsn" yaml:"dsn" toml:"dsn"`
+	Host            string            `json:"host" yaml:"host" toml:"host"`
+	Port            int               `json:"port" yaml:"port" toml:"port"`
+	User            string            `json:"user" yaml:"user" toml:"user"`
+	Password        string            `json:"password" yaml:"password" toml:"password"`
+	Database        string            `json:"database" yaml:"database" toml:"database"`
+	SSLMode         string            `json:"sslmode" yaml:"sslmode" toml:"sslmode"`
+	Params          map[string]string `json:"params" yaml:"params" toml:"params"`
+	MaxOpenConns    int               `json:"max_open_conns" yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int               `json:"max_idle_conns" yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime string            `json:"conn_max_lifetime" yaml:"conn_max_lifetime" toml:"conn_max_lifetime"` // e.g. "5m"
+	PingOnOpen      bool              `json:"ping_on_open" yaml:"ping_on_open" toml:"ping_on_open"`
+	Tags            map[string]string `json:"tags" yaml:"tags" toml:"tags"`
+}
+
+// Decoders of configuration files by format, JSON is built in
+var (
+	configFormatsMu sync.RWMutex
+	configFormats   = map[string]func([]byte, interface{}) error{"json": json.Unmarshal}
+)
+
+// Register the decoder of a configuration file format, named like the file extension. Only
+// JSON is built in, so the package does not depend on YAML or TOML libraries; register their
+// decoders to read such files, e.g. RegisterConfigFormat("yaml", yaml.Unmarshal) or
+// RegisterConfigFormat("toml", toml.Unmarshal).
+func RegisterConfigFormat(format string, unmarshal func([]byte, interface{}) error) {
+	configFormatsMu.Lock()
+	defer configFormatsMu.Unlock()
+	configFormats[strings.ToLower(format)] = unmarshal
+}
+
+// Read the configuration file, in the format of its extension, and open every connection
+// it describes with the options
+func LoadConfig(path string, opts ...Option) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return LoadConfigFrom(f, strings.TrimPrefix(filepath.Ext(path), "."), opts...)
+}
+
+// Read the configuration in the given format, and open every connection it describes with
+// the options
+func LoadConfigFrom(r io.Reader, format string, opts ...Option) error {
+	config, err := ParseConfig(r, format)
+	if err != nil {
+		return err
+	}
+	return config.Open(opts...)
+}
+
+// Read the configuration in the given format, then expand the $VAR and ${VAR} environment
+// variables of the data source names, hosts, users, passwords, databases, SSL modes and
+// parameters. The variables are expanded after decoding, so their values need no escaping.
+func ParseConfig(r io.Reader, format string) (*Config, error) {
+	configFormatsMu.RLock()
+	unmarshal, ok := configFormats[strings.ToLower(format)]
+	if !ok && format == "yml" {
+		unmarshal, ok = configFormats["yaml"]
+	}
+	configFormatsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("ksql: unsupported config format %q", format)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err := unmarshal(buf.Bytes(), config); err != nil {
+		return nil, err
+	}
+	for name, cc := range config.Connections {
+		config.Connections[name] = cc.expandEnv()
+	}
+	return config, nil
+}
+
+// Get the configuration with the environment variables of its connection fields expanded
+func (cc ConnConfig) expandEnv() ConnConfig {
+	for _, field := range []*string{&cc.DSN, &cc.Host, &cc.User, &cc.Password, &cc.Database, &cc.SSLMode} {
+		*field = os.ExpandEnv(*field)
+	}
+	if len(cc.Params) > 0 {
+		params := make(map[string]string, len(cc.Params))
+		for k, v := range cc.Params {
+			params[k] = os.ExpandEnv(v)
+		}
+		cc.Params = params
+	}
+	return cc
+}
+
+// Open every connection of the configuration, by name, with the options. When one fails,
+// the connections already opened are closed again.
+func (c *Config) Open(opts ...Option) error {
+	names := make([]string, 0, len(c.Connections))
+	for name := range c.Connections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var opened []*DB
+	for _, name := range names {
+		db, err := c.Connections[name].open(name, opts)
+		if err != nil {
+			for _, db := range opened {
+				db.Close()
+			}
+			return fmt.Errorf("ksql: connection %q: %w", name, err)
+		}
+		opened = append(opened, db)
+	}
+	return nil
+}
+
+func (cc ConnConfig) open(name string, opts []Option) (*DB, error) {
+	dsn := cc.DSN
+	if dsn == "" {
+		builder := NewDSN(cc.Driver).Host(cc.Host).Port(cc.Port).User(cc.User).Password(cc.Password).
+			Database(cc.Database).SSLMode(cc.SSLMode)
+		for k, v := range cc.Params {
+			builder.Param(k, v)
+		}
+		var err error
+		if dsn, err = builder.Build(); err != nil {
+			return nil, err
+		}
+	}
+	var pool []Option
+	if cc.MaxOpenConns > 0 {
+		pool = append(pool, WithMaxOpenConns(cc.MaxOpenConns))
+	}
+	if cc.MaxIdleConns > 0 {
+		pool = append(pool, WithMaxIdleConns(cc.MaxIdleConns))
+	}
+	if cc.ConnMaxLifetime != "" {
+		d, err := time.ParseDuration(cc.ConnMaxLifetime)
+		if err != nil {
+			return nil, err
+		}
+		pool = append(pool, WithConnMaxLifetime(d))
+	}
+	if len(cc.Tags) > 0 {
+		pool = append(pool, WithTags(cc.Tags))
+	}
+	if cc.PingOnOpen {
+		pool = append(pool, WithPingOnOpen())
+	}
+	return New(name, cc.Driver, dsn, append(pool, opts...)...)
+}
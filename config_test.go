@@ -0,0 +1,100 @@
+package ksql
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	defer Close()
+	t.Setenv("KSQL_REPLICA_DSN", "config replica")
+	path := filepath.Join(t.TempDir(), "databases.json")
+	config := `{"connections": {
+		"primary": {"driver": "ksql_hosts", "dsn": "config primary", "max_open_conns": 5, "conn_max_lifetime": "5m"},
+		"replica": {"driver": "ksql_hosts", "dsn": "${KSQL_REPLICA_DSN}", "ping_on_open": true, "tags": {"role": "replica"}}
+	}}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	db, ok := Get("primary")
+	if !ok {
+		t.Fatal("expected the primary connection")
+	}
+	if n := db.Stats().MaxOpenConnections; n != 5 {
+		t.Errorf("expected 5 open connections at most, got %d", n)
+	}
+	if db, ok := GetByTag("role", "replica"); !ok || db.Name() != "replica" {
+		t.Errorf("expected the tagged replica connection")
+	}
+}
+
+func TestParseConfigEnv(t *testing.T) {
+	// a value that would break the JSON text if expanded before decoding
+	t.Setenv("KSQL_PASSWORD", `p"a$s\`)
+	t.Setenv("KSQL_PORT", "6432")
+	config := `{"connections": {"a": {"driver": "postgres", "host": "db", "password": "${KSQL_PASSWORD}",
+		"params": {"port": "$KSQL_PORT"}, "tags": {"team": "$KSQL_PORT"}}}}`
+	c, err := ParseConfig(strings.NewReader(config), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := c.Connections["a"]
+	if cc.Password != `p"a$s\` || cc.Params["port"] != "6432" {
+		t.Errorf("expected the variables to be expanded, got %q and %q", cc.Password, cc.Params["port"])
+	}
+	if cc.Tags["team"] != "$KSQL_PORT" {
+		t.Errorf("expected the tags to be left as is, got %q", cc.Tags["team"])
+	}
+}
+
+func TestLoadConfigFailure(t *testing.T) {
+	defer Close()
+	testHosts.setDown("config down", true)
+	defer testHosts.setDown("config down", false)
+	config := `{"connections": {
+		"a": {"driver": "ksql_hosts", "dsn": "config up"},
+		"b": {"driver": "ksql_hosts", "dsn": "config down", "ping_on_open": true}
+	}}`
+	err := LoadConfigFrom(strings.NewReader(config), "json")
+	if err == nil || !strings.Contains(err.Error(), `"b"`) {
+		t.Fatalf("expected the second connection to fail, got %v", err)
+	}
+	if _, ok := Get("a"); ok {
+		t.Errorf("expected the first connection to be closed again")
+	}
+	if err := LoadConfigFrom(strings.NewReader(config), "ini"); err == nil {
+		t.Errorf("expected an unsupported format")
+	}
+	_, err = ParseConfig(strings.NewReader(`{"connections": {"a": {"driver": "ksql_hosts"}}}`), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = LoadConfigFrom(strings.NewReader(`{"connections": {"a": {"driver": "ksql_hosts", "host": "db"}}}`), "json")
+	if err == nil {
+		t.Errorf("expected the data source name not to be built for an unknown driver")
+	}
+}
+
+func TestRegisterConfigFormat(t *testing.T) {
+	defer Close()
+	RegisterConfigFormat("lines", func(data []byte, v interface{}) error {
+		config := v.(*Config)
+		config.Connections = make(map[string]ConnConfig)
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			name, dsn, _ := strings.Cut(line, "=")
+			config.Connections[name] = ConnConfig{Driver: "ksql_hosts", DSN: dsn}
+		}
+		return nil
+	})
+	if err := LoadConfigFrom(strings.NewReader("one=config one\ntwo=config two\n"), "lines"); err != nil {
+		t.Fatal(err)
+	}
+	if names := Databases(); strings.Join(names, ",") != "one,two" {
+		t.Errorf("expected both connections, got %v", names)
+	}
+}
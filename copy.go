@@ -0,0 +1,76 @@
+package ksql
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+)
+
+// Whether the driver of db loads rows with COPY FROM STDIN prepared in a transaction
+func copySupported(db *DB) bool {
+	return driverPackage(db.DB) == "github.com/lib/pq"
+}
+
+// Get the COPY statement loading the columns of table from the client
+func copyStatement(table string, columns []string) string {
+	return "COPY " + table + " (" + strings.Join(columns, ", ") + ") FROM STDIN"
+}
+
+// Load rows into the columns of table, see BatchInsertSQL for the accepted rows. Postgres
+// databases opened with lib/pq use the COPY protocol in a transaction of its own, other drivers
+// fall back to BatchInsert. The number of rows loaded is returned.
+func (db *DB) CopyIn(ctx context.Context, table string, columns []string, rows interface{}) (int64, error) {
+	if !copySupported(db) {
+		return db.BatchInsert(ctx, table, columns, rows)
+	}
+	var n int64
+	err := db.WithTx(ctx, func(tx *Tx) error {
+		var err error
+		n, err = tx.CopyIn(ctx, table, columns, rows)
+		return err
+	})
+	return n, err
+}
+
+// Load rows into the columns of table as part of the transaction, see DB.CopyIn
+func (tx *Tx) CopyIn(ctx context.Context, table string, columns []string, rows interface{}) (int64, error) {
+	if !copySupported(tx.db) {
+		return tx.BatchInsert(ctx, table, columns, rows)
+	}
+	v, values, err := batchValues(rows, columns)
+	if err != nil {
+		return 0, err
+	}
+	query := copyStatement(table, columns)
+	ctx, event, err := tx.db.beforeQuery(ctx, OpExec, query, nil)
+	if err != nil {
+		return 0, err
+	}
+	n, err := tx.copyIn(ctx, query, v, values)
+	tx.db.afterQuery(ctx, event, driver.RowsAffected(n), err)
+	return n, err
+}
+
+func (tx *Tx) copyIn(ctx context.Context, query string, v reflect.Value, values func(reflect.Value) ([]interface{}, error)) (int64, error) {
+	stmt, err := tx.Tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	for i := 0; i < v.Len(); i++ {
+		row, err := values(v.Index(i))
+		if err != nil {
+			return 0, err
+		}
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return 0, err
+		}
+	}
+	// executing without arguments ends the copy and reports the rows loaded
+	result, err := stmt.ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
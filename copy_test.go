@@ -0,0 +1,32 @@
+package ksql
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCopyStatement(t *testing.T) {
+	query := copyStatement("people", []string{"id", "name"})
+	if query != "COPY people (id, name) FROM STDIN" {
+		t.Errorf("expected a COPY FROM STDIN statement, got %q", query)
+	}
+}
+
+func TestCopyInFallback(t *testing.T) {
+	defer Close()
+	hook := &recordingHook{}
+	db, err := New("hosts", "ksql_hosts", "hosts", WithHook(hook))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copySupported(db) {
+		t.Fatalf("expected the test driver not to support COPY")
+	}
+	rows := [][]interface{}{{1, "john doe"}, {2, "jane doe"}}
+	if _, err := db.CopyIn(context.Background(), "people", []string{"id", "name"}, rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(hook.after) != 1 || hook.after[0].Query != "INSERT INTO people (id, name) VALUES (?, ?), (?, ?)" {
+		t.Errorf("expected a batch insert, got %v", hook.after)
+	}
+}
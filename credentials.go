@@ -0,0 +1,79 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+)
+
+// Source of the user name and password of a database, called every time a new connection
+// is made, so rotated passwords are picked up without reopening the database
+type CredentialProvider func(ctx context.Context) (user, password string, err error)
+
+// Open a database connection whose credentials are fetched from the provider for every new
+// connection, and save the reference by name. The data source name is built from dsn, with
+// the user and password of the provider. Connections made with the previous credentials stay
+// in use until they close, WithConnMaxLifetime bounds how long that is.
+func NewWithCredentials(name, driverName string, dsn *DSN, provider CredentialProvider, opts ...Option) (*DB, error) {
+	if _, dup := lookup(name); dup {
+		return nil, ErrDupConnName
+	}
+	// fail early on drivers the data source name can't be built for
+	if _, err := dsn.Build(); err != nil {
+		return nil, err
+	}
+	probe, err := sql.Open(driverName, "")
+	if err != nil {
+		return nil, err
+	}
+	d := probe.Driver()
+	probe.Close()
+	sqldb := sql.OpenDB(&credentialConnector{driver: d, dsn: dsn.clone(), provider: provider})
+	db := &DB{DB: sqldb, name: name, bind: bindStyleFor(driverName), dialect: dialectFor(driverName)}
+	if err := db.apply(opts); err != nil {
+		sqldb.Close()
+		return nil, err
+	}
+	if err := register(name, db); err != nil {
+		sqldb.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Get a copy of the data source name, later changes to either don't affect the other
+func (d *DSN) clone() *DSN {
+	c := *d
+	c.params = make(map[string]string, len(d.params))
+	for k, v := range d.params {
+		c.params[k] = v
+	}
+	return &c
+}
+
+type credentialConnector struct {
+	driver   driver.Driver
+	dsn      *DSN
+	provider CredentialProvider
+}
+
+// Connect with the current credentials of the provider
+func (c *credentialConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	user, password, err := c.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dsn, err := c.dsn.clone().User(user).Password(password).Build()
+	if err != nil {
+		return nil, err
+	}
+	connector, err := dsnConnector(c.driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+func (c *credentialConnector) Driver() driver.Driver {
+	return c.driver
+}
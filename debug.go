@@ -0,0 +1,139 @@
+package ksql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Render the statement with its arguments in place of the ?, $1, :1 and @p1 placeholders,
+// quoted and escaped as SQL literals, to log it or reproduce it by hand. The text is never
+// run, placeholders without an argument are kept as they are.
+func DebugSQL(query string, args ...interface{}) string {
+	if len(args) == 0 {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if end := skipLiteral(query, i); end > i {
+			b.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+		index, end := placeholderAt(query, i)
+		if end == i {
+			b.WriteByte(query[i])
+			continue
+		}
+		if index == 0 {
+			n++
+			index = n
+		}
+		if index > len(args) {
+			b.WriteString(query[i:end])
+		} else {
+			b.WriteString(debugValue(args[index-1]))
+		}
+		i = end - 1
+	}
+	return b.String()
+}
+
+// Render the statement of the event with its arguments, see DebugSQL
+func (e *QueryEvent) DebugSQL() string {
+	return DebugSQL(e.Query, e.Args...)
+}
+
+// Get the 1 based index of the argument of the placeholder at offset i of query, 0 for a ?
+// placeholder, and the end offset of the placeholder, or i itself when there is none
+func placeholderAt(query string, i int) (int, int) {
+	c := query[i]
+	start := i + 1
+	switch {
+	case c == '?':
+		return 0, i + 1
+	case c == '$':
+	case c == ':' && (i == 0 || query[i-1] != ':'):
+	case c == '@' && strings.HasPrefix(query[i:], "@p"):
+		start++
+	default:
+		return 0, i
+	}
+	end := start
+	for end < len(query) && query[end] >= '0' && query[end] <= '9' {
+		end++
+	}
+	if end == start {
+		return 0, i
+	}
+	index, err := strconv.Atoi(query[start:end])
+	if err != nil || index == 0 {
+		return 0, i
+	}
+	return index, end
+}
+
+// Format an argument as a SQL literal
+func debugValue(arg interface{}) string {
+	if named, ok := arg.(sql.NamedArg); ok {
+		arg = named.Value
+	}
+	// a nil pointer can't be asked for its value, e.g. a *UUID with a value receiver
+	if v := reflect.ValueOf(arg); v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "NULL"
+		}
+		if _, ok := arg.(driver.Valuer); !ok {
+			arg = v.Elem().Interface()
+		}
+	}
+	if valuer, ok := arg.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return "NULL"
+		}
+		arg = value
+	}
+	switch v := arg.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quoteLiteral(v)
+	case []byte:
+		if v == nil {
+			return "NULL"
+		}
+		if utf8.Valid(v) {
+			return quoteLiteral(string(v))
+		}
+		return "X'" + hex.EncodeToString(v) + "'"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return quoteLiteral(v.Format(time.RFC3339Nano))
+	case fmt.Stringer:
+		return quoteLiteral(v.String())
+	}
+	return quoteLiteral(fmt.Sprint(arg))
+}
+
+// Quote text as a SQL string literal, doubling the single quotes
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
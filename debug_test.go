@@ -0,0 +1,41 @@
+package ksql
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestDebugSQL(t *testing.T) {
+	day := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	name := "jd"
+	tests := []struct {
+		query string
+		args  []interface{}
+		want  string
+	}{
+		{"select * from people where id = ? and name = ?", []interface{}{1, "o'brien"},
+			"select * from people where id = 1 and name = 'o''brien'"},
+		{"select $2, $1, $1", []interface{}{"a", 2.5}, "select 2.5, 'a', 'a'"},
+		{"select :1::text, @p2", []interface{}{true, nil}, "select TRUE::text, NULL"},
+		{"select '?', ? -- ?\n", []interface{}{[]byte("text")}, "select '?', 'text' -- ?\n"},
+		{"select ?", []interface{}{[]byte{0xff, 0x01}}, "select X'ff01'"},
+		{"select ?", []interface{}{day}, "select '2024-03-01T12:30:00Z'"},
+		{"select ?", []interface{}{sql.NullInt64{Int64: 7, Valid: true}}, "select 7"},
+		{"select ?", []interface{}{sql.NullString{}}, "select NULL"},
+		{"select ?, ?", []interface{}{(*UUID)(nil), (*string)(nil)}, "select NULL, NULL"},
+		{"select ?", []interface{}{&name}, "select 'jd'"},
+		{"select ?, ?", []interface{}{1}, "select 1, ?"},
+		{"select $3", []interface{}{1}, "select $3"},
+		{"select ?", nil, "select ?"},
+	}
+	for _, test := range tests {
+		if got := DebugSQL(test.query, test.args...); got != test.want {
+			t.Errorf("%q: expected %q, got %q", test.query, test.want, got)
+		}
+	}
+	event := &QueryEvent{Query: "delete from people where id = $1", Args: []interface{}{int64(3)}}
+	if got := event.DebugSQL(); got != "delete from people where id = 3" {
+		t.Errorf("expected the event statement, got %q", got)
+	}
+}
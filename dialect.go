@@ -0,0 +1,62 @@
+package ksql
+
+import "strings"
+
+// SQL dialect of a database, for the helpers generating statements that differ between backends
+type Dialect int
+
+const (
+	DialectUnknown Dialect = iota
+	DialectPostgres
+	DialectMySQL
+	DialectSQLite
+	DialectSQLServer
+	DialectOracle
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case DialectPostgres:
+		return "postgres"
+	case DialectMySQL:
+		return "mysql"
+	case DialectSQLite:
+		return "sqlite"
+	case DialectSQLServer:
+		return "sqlserver"
+	case DialectOracle:
+		return "oracle"
+	}
+	return "unknown"
+}
+
+// Guess the dialect from a driver name or driver package path
+func dialectFor(driver string) Dialect {
+	driver = strings.ToLower(driver)
+	switch {
+	case strings.Contains(driver, "postgres"), strings.Contains(driver, "pgx"), strings.HasSuffix(driver, "pq"):
+		return DialectPostgres
+	case strings.Contains(driver, "mysql"):
+		return DialectMySQL
+	case strings.Contains(driver, "sqlite"):
+		return DialectSQLite
+	case strings.Contains(driver, "sqlserver"), strings.Contains(driver, "mssql"):
+		return DialectSQLServer
+	case strings.Contains(driver, "oci8"), strings.Contains(driver, "godror"), strings.Contains(driver, "goracle"), strings.Contains(driver, "oracle"):
+		return DialectOracle
+	}
+	return DialectUnknown
+}
+
+// Override the dialect guessed from the driver
+func WithDialect(dialect Dialect) Option {
+	return func(db *DB) error {
+		db.dialect = dialect
+		return nil
+	}
+}
+
+// Get the SQL dialect of the database
+func (db *DB) Dialect() Dialect {
+	return db.dialect
+}
@@ -0,0 +1,197 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+// Driver whose data source names can be marked unreachable. Every query returns the same
+// row of people unless other results were set for its text, and every statement fails when
+// its text is "fail".
+type hostsDriver struct {
+	mu      sync.Mutex
+	down    map[string]bool
+	results map[string]*hostsRows
+	// options of the last transaction begun
+	txOptions driver.TxOptions
+	// text of the last statement run
+	last string
+}
+
+func (d *hostsDriver) Open(dsn string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.down[dsn] {
+		return nil, errors.New("host unreachable: " + dsn)
+	}
+	return hostsConn{driver: d, dsn: dsn}, nil
+}
+
+func (d *hostsDriver) isDown(dsn string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.down[dsn]
+}
+
+func (d *hostsDriver) setDown(dsn string, down bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.down[dsn] = down
+}
+
+// Set the columns and rows returned by the query
+func (d *hostsDriver) setResult(query string, columns []string, values ...[]driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.results[query] = &hostsRows{columns: columns, values: values}
+}
+
+// Set the columns and rows returned by the query until the end of the test
+func (d *hostsDriver) setTestResult(t testing.TB, query string, columns []string, values ...[]driver.Value) {
+	d.setResult(query, columns, values...)
+	t.Cleanup(func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.results, query)
+	})
+}
+
+func (d *hostsDriver) result(query string) *hostsRows {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if r, ok := d.results[query]; ok {
+		return &hostsRows{columns: r.columns, values: r.values, next: r.next}
+	}
+	return &hostsRows{columns: []string{"id", "name"}, values: [][]driver.Value{{int64(1), "john doe"}}}
+}
+
+type hostsConn struct {
+	driver *hostsDriver
+	dsn    string
+}
+
+func (c hostsConn) Ping(ctx context.Context) error {
+	if c.driver.isDown(c.dsn) {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (c hostsConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.driver.record(query)
+	if query == "fail" {
+		return nil, errors.New("statement failed")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c hostsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.record(query)
+	if query == "fail" {
+		return nil, errors.New("statement failed")
+	}
+	return c.driver.result(query), nil
+}
+
+func (c hostsConn) Prepare(query string) (driver.Stmt, error) {
+	return hostsStmt{conn: c, query: query}, nil
+}
+
+func (hostsConn) Close() error              { return nil }
+func (hostsConn) Begin() (driver.Tx, error) { return hostsTx{}, nil }
+
+func (c hostsConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.txOptions = opts
+	return hostsTx{}, nil
+}
+
+func (d *hostsDriver) record(query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.last = query
+}
+
+func (d *hostsDriver) lastQuery() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.last
+}
+
+func (d *hostsDriver) lastTxOptions() driver.TxOptions {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.txOptions
+}
+
+type hostsStmt struct {
+	conn  hostsConn
+	query string
+}
+
+func (s hostsStmt) Close() error  { return nil }
+func (s hostsStmt) NumInput() int { return -1 }
+
+func (s hostsStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, nil)
+}
+
+func (s hostsStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, nil)
+}
+
+type hostsTx struct{}
+
+func (hostsTx) Commit() error   { return nil }
+func (hostsTx) Rollback() error { return nil }
+
+var testHosts = &hostsDriver{down: make(map[string]bool), results: make(map[string]*hostsRows)}
+
+func init() {
+	sql.Register("ksql_hosts", testHosts)
+}
+
+type hostsRows struct {
+	columns []string
+	values  [][]driver.Value
+	// further result sets
+	next []*hostsRows
+}
+
+// Set the result sets returned by the query
+func (d *hostsDriver) setResultSets(query string, sets ...*hostsRows) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	first := *sets[0]
+	first.next = sets[1:]
+	d.results[query] = &first
+}
+
+func (r *hostsRows) HasNextResultSet() bool { return len(r.next) > 0 }
+
+func (r *hostsRows) NextResultSet() error {
+	if len(r.next) == 0 {
+		return io.EOF
+	}
+	next := r.next[0]
+	*r = hostsRows{columns: next.columns, values: next.values, next: r.next[1:]}
+	return nil
+}
+
+func (r *hostsRows) Columns() []string { return r.columns }
+func (r *hostsRows) Close() error      { return nil }
+
+func (r *hostsRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
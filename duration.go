@@ -0,0 +1,115 @@
+package ksql
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Lengths of the calendar units of an interval, as postgres counts them for its epoch
+const (
+	intervalDay   = 24 * time.Hour
+	intervalMonth = 30 * intervalDay
+	intervalYear  = 365*intervalDay + 6*time.Hour
+)
+
+// Set the unit of integer and floating point columns read by GetDuration, seconds by default
+func WithDurationUnit(unit time.Duration) Option {
+	return func(db *DB) error {
+		db.read.durationUnit = unit
+		return nil
+	}
+}
+
+// Get the unit of numeric durations of the rows
+func (rs *Rows) durationUnit() time.Duration {
+	if rs.opts.durationUnit == 0 {
+		return time.Second
+	}
+	return rs.opts.durationUnit
+}
+
+func convertToDuration(value interface{}, unit time.Duration) (time.Duration, error) {
+	switch value := value.(type) {
+	case int64:
+		return time.Duration(value) * unit, nil
+	case float64:
+		return time.Duration(value * float64(unit)), nil
+	case []byte:
+		return parseInterval(string(value))
+	case string:
+		return parseInterval(value)
+	}
+	return 0, ErrInvalidColumnTypeConversion
+}
+
+// Parse a postgres interval such as "1 year 2 mons -3 days +04:05:06.5", or a Go duration
+// such as "1h30m". Years and months count as 365.25 and 30 days.
+func parseInterval(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0, ErrInvalidColumnTypeConversion
+	}
+	var total time.Duration
+	for i := 0; i < len(fields); i++ {
+		if strings.Contains(fields[i], ":") {
+			d, err := parseClock(fields[i])
+			if err != nil {
+				return 0, err
+			}
+			total += d
+			continue
+		}
+		if i+1 == len(fields) {
+			return 0, ErrInvalidColumnTypeConversion
+		}
+		n, err := strconv.ParseInt(fields[i], 10, 64)
+		if err != nil {
+			return 0, ErrInvalidColumnTypeConversion
+		}
+		i++
+		switch strings.TrimSuffix(fields[i], "s") {
+		case "year":
+			total += time.Duration(n) * intervalYear
+		case "mon":
+			total += time.Duration(n) * intervalMonth
+		case "day":
+			total += time.Duration(n) * intervalDay
+		default:
+			return 0, ErrInvalidColumnTypeConversion
+		}
+	}
+	return total, nil
+}
+
+// Parse the [+-]hh:mm:ss[.fraction] time part of an interval
+func parseClock(s string) (time.Duration, error) {
+	sign := time.Duration(1)
+	if s[0] == '-' || s[0] == '+' {
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, ErrInvalidColumnTypeConversion
+	}
+	hours, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidColumnTypeConversion
+	}
+	minutes, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || minutes > 59 {
+		return 0, ErrInvalidColumnTypeConversion
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil || seconds >= 60 || seconds < 0 {
+		return 0, ErrInvalidColumnTypeConversion
+	}
+	d := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second)+0.5)
+	return sign * d, nil
+}
@@ -0,0 +1,73 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	day := 24 * time.Hour
+	tests := map[string]time.Duration{
+		"00:00:01.5":        1500 * time.Millisecond,
+		"04:05:06":          4*time.Hour + 5*time.Minute + 6*time.Second,
+		"-00:30:00":         -30 * time.Minute,
+		"3 days":            3 * day,
+		"1 day 02:00:00":    day + 2*time.Hour,
+		"-1 days +02:03:00": -day + 2*time.Hour + 3*time.Minute,
+		"1 mon":             30 * day,
+		"1 year 2 mons":     365*day + 6*time.Hour + 60*day,
+		"1h30m":             90 * time.Minute,
+		"100:00:00":         100 * time.Hour,
+	}
+	for text, want := range tests {
+		got, err := parseInterval(text)
+		if err != nil {
+			t.Errorf("%q: %v", text, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("expected %v for %q, got %v", want, text, got)
+		}
+	}
+	for _, text := range []string{"", "soon", "3", "3 weeks", "1:2", "00:61:00"} {
+		if _, err := parseInterval(text); err != ErrInvalidColumnTypeConversion {
+			t.Errorf("expected a conversion error for %q, got %v", text, err)
+		}
+	}
+}
+
+func TestGetDuration(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select timeout", []string{"interval", "count", "ratio", "name"},
+		[]driver.Value{[]byte("00:01:30"), int64(90), 1.5, "john doe"})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := db.QueryRow("select timeout")
+	tests := map[string]time.Duration{"interval": 90 * time.Second, "count": 90 * time.Second, "ratio": 1500 * time.Millisecond}
+	for column, want := range tests {
+		d, err := row.GetDuration(column)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d != want {
+			t.Errorf("expected %v for %q, got %v", want, column, d)
+		}
+	}
+	if _, err := row.GetDuration("name"); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected a conversion error for \"name\", got %v", err)
+	}
+	millis, err := New("millis", "ksql_hosts", "hosts", WithDurationUnit(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := millis.QueryRow("select timeout").GetDuration("count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != 90*time.Millisecond {
+		t.Errorf("expected 90ms for \"count\" in milliseconds, got %v", d)
+	}
+}
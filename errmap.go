@@ -0,0 +1,197 @@
+package ksql
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Kinds of constraints reported by ConstraintError
+type ConstraintKind int
+
+const (
+	UniqueConstraint ConstraintKind = iota + 1
+	ForeignKeyConstraint
+	NotNullConstraint
+	CheckConstraint
+)
+
+func (k ConstraintKind) String() string {
+	switch k {
+	case UniqueConstraint:
+		return "unique"
+	case ForeignKeyConstraint:
+		return "foreign key"
+	case NotNullConstraint:
+		return "not null"
+	case CheckConstraint:
+		return "check"
+	}
+	return "unknown"
+}
+
+// Violation of a constraint of the database. The constraint, table and column are filled in
+// as far as the driver reports them, they are empty otherwise.
+type ConstraintError struct {
+	Kind       ConstraintKind
+	Constraint string
+	Table      string
+	Column     string
+	Err        error // error of the driver
+}
+
+func (e *ConstraintError) Error() string { return e.Err.Error() }
+func (e *ConstraintError) Unwrap() error { return e.Err }
+
+// Failure to reach the database, or loss of the connection
+type ConnectionError struct {
+	Err error // error of the driver
+}
+
+func (e *ConnectionError) Error() string { return e.Err.Error() }
+func (e *ConnectionError) Unwrap() error { return e.Err }
+
+// Statement that ran out of time, by a context deadline, a statement or lock timeout of
+// the database, or a network timeout
+type TimeoutError struct {
+	Err error // error of the driver or context
+}
+
+func (e *TimeoutError) Error() string { return e.Err.Error() }
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// Wrap the errors returned by the statements, transactions and rows of the database into
+// ConstraintError, ConnectionError and TimeoutError, see MapError. The original errors can
+// still be reached by errors.Is and errors.As, but no longer compared directly.
+func WithErrorMapping() Option {
+	return func(db *DB) error {
+		db.mapErrors = true
+		return nil
+	}
+}
+
+// Wrap a driver error into a ConstraintError, ConnectionError or TimeoutError, so the
+// conditions of any database can be matched with errors.As. Other errors, including those
+// already mapped, are returned as they are.
+func MapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var (
+		constraintErr *ConstraintError
+		connectionErr *ConnectionError
+		timeoutErr    *TimeoutError
+	)
+	if errors.As(err, &constraintErr) || errors.As(err, &connectionErr) || errors.As(err, &timeoutErr) {
+		return err
+	}
+	switch {
+	case IsUniqueViolation(err):
+		return newConstraintError(UniqueConstraint, err)
+	case IsForeignKeyViolation(err):
+		return newConstraintError(ForeignKeyConstraint, err)
+	case IsNotNullViolation(err):
+		return newConstraintError(NotNullConstraint, err)
+	case IsCheckViolation(err):
+		return newConstraintError(CheckConstraint, err)
+	case timeout(err):
+		return &TimeoutError{Err: err}
+	case connectionFailure(err):
+		return &ConnectionError{Err: err}
+	}
+	return err
+}
+
+// Map the error when the database was opened WithErrorMapping
+func (db *DB) mapError(err error) error {
+	if err == nil || db == nil || !db.mapErrors {
+		return err
+	}
+	return MapError(err)
+}
+
+// Check whether the error is a timeout
+func timeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	switch errorCode(err) {
+	case "57014", // postgres query_canceled, by statement_timeout
+		"55P03", // postgres lock_not_available, by lock_timeout
+		"1205",  // mysql ER_LOCK_WAIT_TIMEOUT
+		"3024":  // mysql ER_QUERY_TIMEOUT
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// Messages naming the constraint, table or column violated, for the drivers that only
+// report them in the text of the error
+var constraintMessages = []*regexp.Regexp{
+	// sqlite: UNIQUE constraint failed: people.email
+	regexp.MustCompile(`constraint failed: (?P<table>\w+)\.(?P<column>\w+)`),
+	// mysql: Duplicate entry 'x' for key 'people.email_key'
+	regexp.MustCompile("for key '(?:(?P<table>\\w+)\\.)?(?P<constraint>[^']+)'"),
+	// mysql: a foreign key constraint fails (`db`.`orders`, CONSTRAINT `fk` FOREIGN KEY (`person_id`) ...
+	regexp.MustCompile("\\(`\\w+`\\.`(?P<table>\\w+)`, CONSTRAINT `(?P<constraint>[^`]+)` FOREIGN KEY \\(`(?P<column>\\w+)`"),
+	// mysql: Column 'name' cannot be null
+	regexp.MustCompile(`Column '(?P<column>\w+)' cannot be null`),
+	// mysql: Check constraint 'age_check' is violated
+	regexp.MustCompile(`Check constraint '(?P<constraint>[^']+)' is violated`),
+}
+
+func newConstraintError(kind ConstraintKind, err error) *ConstraintError {
+	e := &ConstraintError{Kind: kind, Err: err}
+	// pq names the fields Constraint, Table and Column, pgx ConstraintName, TableName and ColumnName
+	for cause := err; cause != nil; cause = errors.Unwrap(cause) {
+		v := reflect.ValueOf(cause)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+		e.Constraint = stringField(v, "Constraint", "ConstraintName")
+		e.Table = stringField(v, "Table", "TableName")
+		e.Column = stringField(v, "Column", "ColumnName")
+		if e.Constraint != "" || e.Table != "" || e.Column != "" {
+			return e
+		}
+	}
+	message := err.Error()
+	for _, re := range constraintMessages {
+		match := re.FindStringSubmatch(message)
+		if match == nil {
+			continue
+		}
+		for i, name := range re.SubexpNames() {
+			switch name {
+			case "constraint":
+				e.Constraint = match[i]
+			case "table":
+				e.Table = match[i]
+			case "column":
+				e.Column = match[i]
+			}
+		}
+		break
+	}
+	return e
+}
+
+// Get the first string field of the struct by one of the names
+func stringField(v reflect.Value, names ...string) string {
+	for _, name := range names {
+		if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.String {
+			return strings.TrimSpace(f.String())
+		}
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package ksql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Mimic the errors of pq, which name the constraint violated
+type pqConstraintError struct {
+	Code       string
+	Constraint string
+	Table      string
+	Column     string
+}
+
+func (e *pqConstraintError) Error() string { return "pq: " + e.Code }
+
+func TestMapError(t *testing.T) {
+	tests := []struct {
+		err                       error
+		kind                      ConstraintKind
+		constraint, table, column string
+	}{
+		{err: &pqConstraintError{Code: "23505", Constraint: "people_email_key", Table: "people"},
+			kind: UniqueConstraint, constraint: "people_email_key", table: "people"},
+		{err: fmt.Errorf("insert: %w", &pqConstraintError{Code: "23502", Table: "people", Column: "name"}),
+			kind: NotNullConstraint, table: "people", column: "name"},
+		{err: sqliteMessageError{sqlite3Error{Code: 19, ExtendedCode: 2067}, "UNIQUE constraint failed: people.email"},
+			kind: UniqueConstraint, table: "people", column: "email"},
+		{err: mysqlMessageError{mysqlError{1062}, "Duplicate entry 'a@b.c' for key 'people.email_key'"},
+			kind: UniqueConstraint, constraint: "email_key", table: "people"},
+		{err: mysqlMessageError{mysqlError{1452}, "Cannot add or update a child row: a foreign key constraint fails " +
+			"(`shop`.`orders`, CONSTRAINT `orders_person_fk` FOREIGN KEY (`person_id`) REFERENCES `people` (`id`))"},
+			kind: ForeignKeyConstraint, constraint: "orders_person_fk", table: "orders", column: "person_id"},
+		{err: &mysqlError{3819}, kind: CheckConstraint},
+	}
+	for _, test := range tests {
+		var e *ConstraintError
+		if !errors.As(MapError(test.err), &e) {
+			t.Errorf("%v: expected a constraint error", test.err)
+			continue
+		}
+		if e.Kind != test.kind || e.Constraint != test.constraint || e.Table != test.table || e.Column != test.column {
+			t.Errorf("%v: expected %v %q %q %q, got %v %q %q %q", test.err, test.kind, test.constraint, test.table,
+				test.column, e.Kind, e.Constraint, e.Table, e.Column)
+		}
+		if !errors.Is(e, test.err) || e.Error() != test.err.Error() {
+			t.Errorf("%v: expected the driver error to be wrapped", test.err)
+		}
+	}
+
+	var connErr *ConnectionError
+	if err := MapError(driver.ErrBadConn); !errors.As(err, &connErr) || !errors.Is(err, driver.ErrBadConn) {
+		t.Errorf("expected a connection error, got %#v", err)
+	}
+	var timeoutErr *TimeoutError
+	for _, err := range []error{context.DeadlineExceeded, &pqError{"57014"}, &mysqlError{1205}} {
+		if !errors.As(MapError(err), &timeoutErr) {
+			t.Errorf("%v: expected a timeout error", err)
+		}
+	}
+	plain := errors.New("syntax error")
+	if MapError(plain) != plain || MapError(nil) != nil {
+		t.Errorf("expected other errors to be returned as they are")
+	}
+	mapped := MapError(&pqError{"23505"})
+	if MapError(mapped) != mapped {
+		t.Errorf("expected a mapped error to be returned as it is")
+	}
+}
+
+type sqliteMessageError struct {
+	sqlite3Error
+	message string
+}
+
+func (e sqliteMessageError) Error() string { return e.message }
+
+type mysqlMessageError struct {
+	mysqlError
+	message string
+}
+
+func (e mysqlMessageError) Error() string { return e.message }
+
+func TestWithErrorMapping(t *testing.T) {
+	defer Close()
+	db, err := New("mapped", "ksql_hosts", "mapped", WithErrorMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	var timeoutErr *TimeoutError
+	if _, err := db.ExecContext(ctx, "update people"); !errors.As(err, &timeoutErr) {
+		t.Errorf("expected a timeout error, got %#v", err)
+	}
+	if _, err := db.QueryContext(ctx, "select people"); !errors.As(err, &timeoutErr) {
+		t.Errorf("expected a timeout error, got %#v", err)
+	}
+	if _, err := db.Exec("fail"); err == nil || errors.As(err, &timeoutErr) {
+		t.Errorf("expected the error of the statement as it is, got %#v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package ksql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"syscall"
+)
+
+// Get the database error code of a driver error, without importing the driver. This is the
+// SQLSTATE of postgres errors (pq, pgx) and the error number of mysql and sql server errors.
+func errorCode(err error) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(interface{ SQLState() string }); ok {
+			return e.SQLState()
+		}
+		v := reflect.ValueOf(err)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+		if f := v.FieldByName("Code"); f.IsValid() && f.Kind() == reflect.String {
+			return f.String()
+		}
+		if f := v.FieldByName("Number"); f.IsValid() {
+			switch f.Kind() {
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				return strconv.FormatUint(f.Uint(), 10)
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return strconv.FormatInt(f.Int(), 10)
+			}
+		}
+	}
+	return ""
+}
+
+// Get the extended result code of a sqlite error, without importing the driver, or 0. This
+// is the ExtendedCode of mattn/go-sqlite3 errors and the Code of modernc.org/sqlite errors.
+func sqliteCode(err error) int {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(interface{ Code() int }); ok {
+			return e.Code()
+		}
+		v := reflect.ValueOf(err)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+		if f := v.FieldByName("ExtendedCode"); f.IsValid() {
+			switch f.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return int(f.Int())
+			}
+		}
+	}
+	return 0
+}
+
+// Check whether the error is a violation of a unique constraint or primary key
+func IsUniqueViolation(err error) bool {
+	switch errorCode(err) {
+	case "23505", // postgres unique_violation
+		"1062", // mysql ER_DUP_ENTRY
+		"1586", // mysql ER_DUP_ENTRY_WITH_KEY_NAME
+		"2601", // sql server duplicate key in unique index
+		"2627": // sql server unique or primary key constraint
+		return true
+	}
+	switch sqliteCode(err) {
+	case 2067, // SQLITE_CONSTRAINT_UNIQUE
+		1555: // SQLITE_CONSTRAINT_PRIMARYKEY
+		return true
+	}
+	return false
+}
+
+// Check whether the error is a violation of a foreign key, by a missing parent row or a
+// parent row still referenced
+func IsForeignKeyViolation(err error) bool {
+	switch errorCode(err) {
+	case "23503", // postgres foreign_key_violation
+		"1451", // mysql ER_ROW_IS_REFERENCED_2
+		"1452", // mysql ER_NO_REFERENCED_ROW_2
+		"1216", // mysql ER_NO_REFERENCED_ROW
+		"1217", // mysql ER_ROW_IS_REFERENCED
+		"547":  // sql server constraint conflict
+		return true
+	}
+	return sqliteCode(err) == 787 // SQLITE_CONSTRAINT_FOREIGNKEY
+}
+
+// Check whether the error is a NULL value given to a column that is NOT NULL
+func IsNotNullViolation(err error) bool {
+	switch errorCode(err) {
+	case "23502", // postgres not_null_violation
+		"1048", // mysql ER_BAD_NULL_ERROR
+		"515":  // sql server cannot insert NULL
+		return true
+	}
+	return sqliteCode(err) == 1299 // SQLITE_CONSTRAINT_NOTNULL
+}
+
+// Check whether the error is a violation of a check constraint
+func IsCheckViolation(err error) bool {
+	switch errorCode(err) {
+	case "23514", // postgres check_violation
+		"3819": // mysql ER_CHECK_CONSTRAINT_VIOLATED
+		return true
+	}
+	return sqliteCode(err) == 275 // SQLITE_CONSTRAINT_CHECK
+}
+
+// Check whether the error is a deadlock, after which the transaction chosen as the victim
+// was rolled back
+func IsDeadlock(err error) bool {
+	switch errorCode(err) {
+	case "40P01", // postgres deadlock_detected
+		"1213": // mysql ER_LOCK_DEADLOCK
+		return true
+	}
+	return false
+}
+
+// Check whether the error is a serialization failure of a transaction
+func IsSerializationFailure(err error) bool {
+	return errorCode(err) == "40001" // postgres serialization_failure
+}
+
+// Check whether the error is transient, so that running the statement or transaction again
+// may succeed: a serialization failure, deadlock, lock timeout, busy sqlite database or a
+// failure to reach the database
+func IsRetryable(err error) bool {
+	if retryable(err) || connectionFailure(err) {
+		return true
+	}
+	switch errorCode(err) {
+	case "55P03", // postgres lock_not_available
+		"1205": // mysql ER_LOCK_WAIT_TIMEOUT, or the deadlock victim of sql server
+		return true
+	}
+	switch sqliteCode(err) & 0xff {
+	case 5, // SQLITE_BUSY
+		6: // SQLITE_LOCKED
+		return true
+	}
+	return false
+}
+
+// Check whether the error is a serialization failure or deadlock, after which the
+// whole transaction can be retried
+func retryable(err error) bool {
+	return IsSerializationFailure(err) || IsDeadlock(err)
+}
+
+// Check whether the error is a failure to reach the database, after which the statement
+// can be run again on another connection
+func connectionFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	switch code := errorCode(err); {
+	case len(code) == 5 && code[:2] == "08", // postgres connection_exception class
+		code == "57P01", // postgres admin_shutdown
+		code == "57P02", // postgres crash_shutdown
+		code == "57P03", // postgres cannot_connect_now
+		code == "1040",  // mysql ER_CON_COUNT_ERROR
+		code == "1053",  // mysql ER_SERVER_SHUTDOWN
+		code == "2002",  // mysql CR_CONNECTION_ERROR
+		code == "2003",  // mysql CR_CONN_HOST_ERROR
+		code == "2006",  // mysql CR_SERVER_GONE_ERROR
+		code == "2013":  // mysql CR_SERVER_LOST
+		return true
+	case code != "":
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		errors.As(err, &netErr)
+}
@@ -0,0 +1,131 @@
+package ksql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+)
+
+// Mimic the error types of the common drivers
+type pqError struct{ Code string }
+
+func (e *pqError) Error() string { return "pq: " + e.Code }
+
+type pgxError struct{ code string }
+
+func (e *pgxError) Error() string    { return "pgx: " + e.code }
+func (e *pgxError) SQLState() string { return e.code }
+
+type mysqlError struct{ Number uint16 }
+
+func (e *mysqlError) Error() string { return fmt.Sprintf("mysql: %d", e.Number) }
+
+type mssqlError struct{ Number int32 }
+
+func (e *mssqlError) Error() string { return fmt.Sprintf("mssql: %d", e.Number) }
+
+type sqlite3Error struct {
+	Code         int
+	ExtendedCode int
+}
+
+func (e sqlite3Error) Error() string { return fmt.Sprintf("sqlite3: %d", e.ExtendedCode) }
+
+type moderncError struct{ code int }
+
+func (e *moderncError) Error() string { return fmt.Sprintf("sqlite: %d", e.code) }
+func (e *moderncError) Code() int     { return e.code }
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err       error
+		code      string
+		retryable bool
+	}{
+		{&pqError{"40001"}, "40001", true},
+		{&pgxError{"40P01"}, "40P01", true},
+		{&mysqlError{1213}, "1213", true},
+		{fmt.Errorf("insert: %w", &pqError{"23505"}), "23505", false},
+		{errors.New("connection refused"), "", false},
+		{nil, "", false},
+	}
+	for _, test := range tests {
+		if code := errorCode(test.err); code != test.code {
+			t.Errorf("%v: expected code %q, got %q", test.err, test.code, code)
+		}
+		if retryable(test.err) != test.retryable {
+			t.Errorf("%v: expected retryable %v", test.err, test.retryable)
+		}
+	}
+}
+
+func TestConnectionFailure(t *testing.T) {
+	tests := []struct {
+		err     error
+		failure bool
+	}{
+		{driver.ErrBadConn, true},
+		{fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+		{&net.OpError{Op: "read", Err: syscall.ECONNRESET}, true},
+		{io.ErrUnexpectedEOF, true},
+		{&pqError{"08006"}, true},
+		{&pqError{"57P01"}, true},
+		{&mysqlError{2006}, true},
+		{&pqError{"23505"}, false},
+		{&mysqlError{1062}, false},
+		{context.DeadlineExceeded, false},
+		{errors.New("syntax error"), false},
+		{nil, false},
+	}
+	for _, test := range tests {
+		if connectionFailure(test.err) != test.failure {
+			t.Errorf("%v: expected connection failure %v", test.err, test.failure)
+		}
+	}
+}
+
+func TestErrorPredicates(t *testing.T) {
+	tests := []struct {
+		err                                  error
+		unique, foreignKey, notNull, check   bool
+		deadlock, serialization, isRetryable bool
+	}{
+		{err: &pqError{"23505"}, unique: true},
+		{err: fmt.Errorf("insert: %w", &mysqlError{1062}), unique: true},
+		{err: &mssqlError{2627}, unique: true},
+		{err: sqlite3Error{Code: 19, ExtendedCode: 1555}, unique: true},
+		{err: &moderncError{2067}, unique: true},
+		{err: &pgxError{"23503"}, foreignKey: true},
+		{err: &mysqlError{1452}, foreignKey: true},
+		{err: sqlite3Error{Code: 19, ExtendedCode: 787}, foreignKey: true},
+		{err: &pqError{"23502"}, notNull: true},
+		{err: &mysqlError{1048}, notNull: true},
+		{err: &pqError{"23514"}, check: true},
+		{err: &moderncError{275}, check: true},
+		{err: &pqError{"40P01"}, deadlock: true, isRetryable: true},
+		{err: &mysqlError{1213}, deadlock: true, isRetryable: true},
+		{err: &pgxError{"40001"}, serialization: true, isRetryable: true},
+		{err: &mysqlError{1205}, isRetryable: true},
+		{err: sqlite3Error{Code: 5, ExtendedCode: 517}, isRetryable: true},
+		{err: &pqError{"08006"}, isRetryable: true},
+		{err: driver.ErrBadConn, isRetryable: true},
+		{err: errors.New("duplicate key value violates unique constraint")},
+		{err: nil},
+	}
+	for _, test := range tests {
+		got := []bool{IsUniqueViolation(test.err), IsForeignKeyViolation(test.err), IsNotNullViolation(test.err),
+			IsCheckViolation(test.err), IsDeadlock(test.err), IsSerializationFailure(test.err), IsRetryable(test.err)}
+		want := []bool{test.unique, test.foreignKey, test.notNull, test.check, test.deadlock, test.serialization, test.isRetryable}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%v: expected %v, got %v", test.err, want, got)
+				break
+			}
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"log"
 
 	sql "github.com/kahoon/ksql"
@@ -49,7 +48,11 @@ func populate() {
 
 func main() {
 	// create a connection and name it
-	db, err := sql.New("master", "postgres", fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", USER, PASSWORD, HOSTNAME, DATABASE))
+	dsn, err := sql.NewDSN("postgres").Host(HOSTNAME).User(USER).Password(PASSWORD).Database(DATABASE).SSLMode("disable").Build()
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := sql.New("master", "postgres", dsn)
 	if err != nil {
 		log.Fatal(err)
 	}
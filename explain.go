@@ -0,0 +1,82 @@
+package ksql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Prefix the query with the EXPLAIN statement of the dialect. ANALYZE runs the query to
+// report the actual plan, which is not supported by sqlite.
+func ExplainSQL(dialect Dialect, query string, analyze bool) (string, error) {
+	switch {
+	case dialect == DialectPostgres, dialect == DialectMySQL:
+		if analyze {
+			return "EXPLAIN ANALYZE " + query, nil
+		}
+		return "EXPLAIN " + query, nil
+	case dialect == DialectSQLite && !analyze:
+		return "EXPLAIN QUERY PLAN " + query, nil
+	}
+	return "", ErrUnsupportedDialect
+}
+
+// Get the plan of the query as rows, without running it
+func (db *DB) Explain(query string, args ...interface{}) (*Rows, error) {
+	return db.ExplainContext(context.Background(), query, args...)
+}
+
+func (db *DB) ExplainContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	return db.explain(ctx, query, false, args)
+}
+
+// Run the query and get its actual plan as rows. Statements that modify data do so, unless
+// run in a transaction that is rolled back.
+func (db *DB) ExplainAnalyze(query string, args ...interface{}) (*Rows, error) {
+	return db.ExplainAnalyzeContext(context.Background(), query, args...)
+}
+
+func (db *DB) ExplainAnalyzeContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	return db.explain(ctx, query, true, args)
+}
+
+func (db *DB) explain(ctx context.Context, query string, analyze bool, args []interface{}) (*Rows, error) {
+	explain, err := ExplainSQL(db.dialect, query, analyze)
+	if err != nil {
+		return nil, err
+	}
+	return db.QueryContext(ctx, explain, args...)
+}
+
+// Get the plan of the query as text, one line per row with the columns separated by tabs,
+// e.g. to log the plans of slow statements
+func (db *DB) ExplainText(ctx context.Context, query string, args ...interface{}) (string, error) {
+	rows, err := db.ExplainContext(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	var b strings.Builder
+	for rows.Next() {
+		for i := range rows.columns {
+			value, err := validateIndex(rows, i)
+			if err != nil {
+				return "", err
+			}
+			if i > 0 {
+				b.WriteByte('\t')
+			}
+			if v, ok := value.([]byte); ok {
+				value = string(v)
+			}
+			if value != nil {
+				fmt.Fprint(&b, value)
+			}
+		}
+		b.WriteByte('\n')
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,78 @@
+package ksql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestExplainSQL(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		analyze bool
+		want    string
+	}{
+		{DialectPostgres, false, "EXPLAIN select 1"},
+		{DialectPostgres, true, "EXPLAIN ANALYZE select 1"},
+		{DialectMySQL, true, "EXPLAIN ANALYZE select 1"},
+		{DialectSQLite, false, "EXPLAIN QUERY PLAN select 1"},
+	}
+	for _, test := range tests {
+		got, err := ExplainSQL(test.dialect, "select 1", test.analyze)
+		if err != nil || got != test.want {
+			t.Errorf("%v: expected %q, got %q %v", test.dialect, test.want, got, err)
+		}
+	}
+	for _, dialect := range []Dialect{DialectSQLServer, DialectOracle, DialectUnknown} {
+		if _, err := ExplainSQL(dialect, "select 1", false); err != ErrUnsupportedDialect {
+			t.Errorf("%v: expected an unsupported dialect, got %v", dialect, err)
+		}
+	}
+	if _, err := ExplainSQL(DialectSQLite, "select 1", true); err != ErrUnsupportedDialect {
+		t.Errorf("expected analyze to be unsupported by sqlite, got %v", err)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	defer Close()
+	testHosts.setResult("EXPLAIN select people", []string{"QUERY PLAN"},
+		[]driver.Value{[]byte("Seq Scan on people")}, []driver.Value{"  Filter: (id = 1)"})
+	db, err := New("hosts", "ksql_hosts", "hosts", WithDialect(DialectPostgres))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Explain("select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rows.Next() {
+		t.Fatal("expected a plan")
+	}
+	if line, _ := rows.GetString("QUERY PLAN"); line != "Seq Scan on people" {
+		t.Errorf("expected the plan, got %q", line)
+	}
+	rows.Close()
+	plan, err := db.ExplainText(context.Background(), "select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan != "Seq Scan on people\n  Filter: (id = 1)\n" {
+		t.Errorf("unexpected plan %q", plan)
+	}
+	testHosts.setResult("EXPLAIN ANALYZE select people", []string{"QUERY PLAN"}, []driver.Value{"Seq Scan on people (actual rows=1)"})
+	rows, err = db.ExplainAnalyze("select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rows.Next() {
+		t.Fatal("expected the actual plan")
+	}
+	rows.Close()
+	other, err := New("other", "ksql_hosts", "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Explain("select people"); err != ErrUnsupportedDialect {
+		t.Errorf("expected an unsupported dialect, got %v", err)
+	}
+}
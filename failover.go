@@ -0,0 +1,160 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync/atomic"
+	"time"
+)
+
+// Default interval between probes of the first data source name after a failover
+const DefaultFailbackInterval = 30 * time.Second
+
+// Open a database connection that fails over across several data source names of the same
+// driver, and save the reference by name. New connections are made to the current host,
+// moving on to the next host when it is unreachable. After a failover the first host is
+// probed periodically, and new connections go back to it once it is reachable again.
+// Connections to the other hosts are recycled as they close, WithConnMaxLifetime bounds
+// how long they remain in use.
+func NewFailover(name, driverName string, dsns []string, opts ...Option) (*DB, error) {
+	if len(dsns) == 0 {
+		return nil, ErrNoDataSource
+	}
+	if _, dup := lookup(name); dup {
+		return nil, ErrDupConnName
+	}
+	// open through the registry to find the driver of the name
+	probe, err := sql.Open(driverName, dsns[0])
+	if err != nil {
+		return nil, err
+	}
+	d := probe.Driver()
+	probe.Close()
+	connector := &failoverConnector{driver: d, interval: DefaultFailbackInterval}
+	for _, dsn := range dsns {
+		c, err := dsnConnector(d, dsn)
+		if err != nil {
+			return nil, err
+		}
+		connector.connectors = append(connector.connectors, c)
+	}
+	sqldb := sql.OpenDB(connector)
+	stop := make(chan struct{})
+	db := &DB{DB: sqldb, name: name, bind: bindStyleFor(driverName), dialect: dialectFor(driverName), failover: connector}
+	db.onClose = append(db.onClose, func() { close(stop) })
+	if err := db.apply(opts); err != nil {
+		sqldb.Close()
+		return nil, err
+	}
+	if err := register(name, db); err != nil {
+		sqldb.Close()
+		return nil, err
+	}
+	go connector.failback(stop)
+	return db, nil
+}
+
+// Set the interval between probes of the first host of a failover connection, or
+// DefaultFailbackInterval when it is not positive
+func WithFailbackInterval(d time.Duration) Option {
+	return func(db *DB) error {
+		if d <= 0 {
+			d = DefaultFailbackInterval
+		}
+		if db.failover != nil {
+			db.failover.interval = d
+		}
+		return nil
+	}
+}
+
+// Get the index of the data source name new connections are currently made to
+func (db *DB) CurrentHost() int {
+	if db.failover == nil {
+		return 0
+	}
+	return int(atomic.LoadInt32(&db.failover.current))
+}
+
+type failoverConnector struct {
+	driver     driver.Driver
+	connectors []driver.Connector
+	current    int32
+	interval   time.Duration
+}
+
+// Connect to the current host, or to the first of the following hosts that is reachable
+func (c *failoverConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	start := int(atomic.LoadInt32(&c.current))
+	var last error
+	for i := range c.connectors {
+		index := (start + i) % len(c.connectors)
+		conn, err := c.connectors[index].Connect(ctx)
+		if err == nil {
+			if index != start {
+				atomic.CompareAndSwapInt32(&c.current, int32(start), int32(index))
+			}
+			return conn, nil
+		}
+		last = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, last
+}
+
+func (c *failoverConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Probe the first host while failed over, until stopped
+func (c *failoverConnector) failback(stop chan struct{}) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			c.probe()
+		}
+	}
+}
+
+// Switch back to the first host if it is reachable again
+func (c *failoverConnector) probe() {
+	if atomic.LoadInt32(&c.current) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.interval)
+	defer cancel()
+	conn, err := c.connectors[0].Connect(ctx)
+	if err != nil {
+		return
+	}
+	conn.Close()
+	atomic.StoreInt32(&c.current, 0)
+}
+
+// Get a connector for the data source name, for drivers with or without driver.DriverContext
+func dsnConnector(d driver.Driver, dsn string) (driver.Connector, error) {
+	if dc, ok := d.(driver.DriverContext); ok {
+		return dc.OpenConnector(dsn)
+	}
+	return &simpleConnector{driver: d, dsn: dsn}, nil
+}
+
+type simpleConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c *simpleConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *simpleConnector) Driver() driver.Driver {
+	return c.driver
+}
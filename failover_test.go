@@ -0,0 +1,48 @@
+package ksql
+
+import "testing"
+
+func TestNewFailover(t *testing.T) {
+	defer Close()
+	if _, err := NewFailover("hosts", "ksql_hosts", nil); err != ErrNoDataSource {
+		t.Errorf("expected a no data source error, got %v", err)
+	}
+	testHosts.setDown("primary", true)
+	defer testHosts.setDown("primary", false)
+	db, err := NewFailover("hosts", "ksql_hosts", []string{"primary", "secondary"}, WithMaxIdleConns(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if db.CurrentHost() != 1 {
+		t.Errorf("expected to fail over to the secondary, got host %d", db.CurrentHost())
+	}
+	db.failover.probe()
+	if db.CurrentHost() != 1 {
+		t.Errorf("expected to stay on the secondary while the primary is down, got host %d", db.CurrentHost())
+	}
+	testHosts.setDown("primary", false)
+	db.failover.probe()
+	if db.CurrentHost() != 0 {
+		t.Errorf("expected to fail back to the primary, got host %d", db.CurrentHost())
+	}
+	testHosts.setDown("primary", true)
+	testHosts.setDown("secondary", true)
+	defer testHosts.setDown("secondary", false)
+	if err := db.Ping(); err == nil {
+		t.Errorf("expected an error with every host down")
+	}
+}
+
+func TestFailbackInterval(t *testing.T) {
+	defer Close()
+	db, err := NewFailover("failback", "ksql_hosts", []string{"failback"}, WithFailbackInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.failover.interval != DefaultFailbackInterval {
+		t.Errorf("expected the default interval, got %v", db.failover.interval)
+	}
+}
@@ -0,0 +1,54 @@
+package ksql
+
+import "strings"
+
+// Normalize a query by replacing its string and number literals with ?, dropping comments
+// and collapsing whitespace. Statements that only differ by their literal values share a
+// fingerprint, which is also safe to log or trace without leaking the values.
+func Fingerprint(query string) string {
+	var b strings.Builder
+	space := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if end := skipLiteral(query, i); end > i {
+			if c == '-' || c == '/' {
+				// comments read as whitespace
+				space = b.Len() > 0
+				i = end - 1
+				continue
+			}
+			if space {
+				b.WriteByte(' ')
+				space = false
+			}
+			if c == '\'' {
+				// a doubled quote escapes a quote within the same literal
+				for end < len(query) && query[end] == '\'' {
+					end = skipLiteral(query, end)
+				}
+				b.WriteByte('?')
+			} else {
+				// quoted identifiers are kept
+				b.WriteString(query[i:end])
+			}
+			i = end - 1
+			continue
+		}
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = b.Len() > 0
+			continue
+		case c >= '0' && c <= '9' && (i == 0 || !isNamePart(query[i-1]) && query[i-1] != '$'):
+			for i+1 < len(query) && (query[i+1] >= '0' && query[i+1] <= '9' || query[i+1] == '.') {
+				i++
+			}
+			c = '?'
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,18 @@
+package ksql
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	tests := map[string]string{
+		"select * from people where id = 1":                                 "select * from people where id = ?",
+		"select *\n  from people\twhere name = 'john doe' and ratio > 3.14": "select * from people where name = ? and ratio > ?",
+		"select * from people where id = $1 -- by id\n":                     "select * from people where id = $1",
+		"insert into t1 (col2, \"Col 3\") values (/* id */ 42, 'it''s')":    "insert into t1 (col2, \"Col 3\") values ( ?, ?)",
+		"  select 1  ": "select ?",
+	}
+	for query, want := range tests {
+		if got := Fingerprint(query); got != want {
+			t.Errorf("Fingerprint(%q): expected %q, got %q", query, want, got)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package ksql
+
+import (
+	"context"
+	"reflect"
+)
+
+// Rows can be queried from both a DB and a Tx
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error)
+}
+
+// Run the query and map every row to a T, closing the rows. A struct T, or the struct a
+// pointer T points to, is scanned like Rows.ScanStruct, while any other T is read from the
+// first column with the conversions of Value.
+func Query[T any](q Querier, query string, args ...interface{}) ([]T, error) {
+	return QueryContext[T](context.Background(), q, query, args...)
+}
+
+func QueryContext[T any](ctx context.Context, q Querier, query string, args ...interface{}) ([]T, error) {
+	return collect[T](ctx, q, scannerOf(reflect.TypeOf((*T)(nil)).Elem()), query, args)
+}
+
+// Run the query and read the first column of every row into a slice, such as the ids of
+// Pluck[int64](db, "select id from people where active") for a later IN query. The values are
+// read like QueryScalar, even when T is a struct.
+func Pluck[T any](q Querier, query string, args ...interface{}) ([]T, error) {
+	return PluckContext[T](context.Background(), q, query, args...)
+}
+
+func PluckContext[T any](ctx context.Context, q Querier, query string, args ...interface{}) ([]T, error) {
+	return collect[T](ctx, q, scanScalar, query, args)
+}
+
+// Run the query and read the first column of every row as a string, see Pluck
+func (db *DB) PluckStrings(query string, args ...interface{}) ([]string, error) {
+	return PluckContext[string](context.Background(), db, query, args...)
+}
+
+func (db *DB) PluckStringsContext(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	return PluckContext[string](ctx, db, query, args...)
+}
+
+// Run the query and read the first column of every row as an integer, see Pluck
+func (db *DB) PluckIntegers(query string, args ...interface{}) ([]int64, error) {
+	return PluckContext[int64](context.Background(), db, query, args...)
+}
+
+func (db *DB) PluckIntegersContext(ctx context.Context, query string, args ...interface{}) ([]int64, error) {
+	return PluckContext[int64](ctx, db, query, args...)
+}
+
+// Run the query and store every row into a T with scan, closing the rows
+func collect[T any](ctx context.Context, q Querier, scan func(*Rows, reflect.Value) error, query string, args []interface{}) ([]T, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	// the rows never leave this function, they can be recycled
+	defer putRows(rows)
+	defer rows.Close()
+	var result []T
+	for rows.Next() {
+		var dest T
+		if err := scan(rows, reflect.ValueOf(&dest).Elem()); err != nil {
+			return nil, err
+		}
+		result = append(result, dest)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Run the query and read the first column of its first row as a T, such as the count of
+// QueryScalar[int64](db, "select count(*) from people"), or return ErrNoRows when there are
+// no rows. A NULL is read as nil into a pointer T and through Scan into a sql.Scanner such as
+// sql.NullInt64, while other types fail with ErrInvalidColumnTypeConversion, or get the zero
+// value with WithNullAsZero.
+func QueryScalar[T any](q Querier, query string, args ...interface{}) (T, error) {
+	return QueryScalarContext[T](context.Background(), q, query, args...)
+}
+
+func QueryScalarContext[T any](ctx context.Context, q Querier, query string, args ...interface{}) (T, error) {
+	var dest, zero T
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return zero, err
+	}
+	defer putRows(rows)
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return zero, err
+		}
+		return zero, ErrNoRows
+	}
+	if err := scanScalar(rows, reflect.ValueOf(&dest).Elem()); err != nil {
+		return zero, err
+	}
+	if err := rows.Close(); err != nil {
+		return zero, err
+	}
+	return dest, nil
+}
+
+// Get how the current row is stored into a value of type t
+func scannerOf(t reflect.Type) func(rs *Rows, dest reflect.Value) error {
+	switch {
+	case isStruct(t):
+		return scanStruct
+	case t.Kind() == reflect.Ptr && isStruct(t.Elem()):
+		return func(rs *Rows, dest reflect.Value) error {
+			dest.Set(reflect.New(t.Elem()))
+			return scanStruct(rs, dest.Elem())
+		}
+	}
+	return scanScalar
+}
+
+// Store the first column of the current row into dest
+func scanScalar(rs *Rows, dest reflect.Value) error {
+	value, err := validateIndex(rs, 0)
+	if err != nil {
+		return err
+	}
+	return rs.convertAssign(dest, value)
+}
+
+// Check whether the columns of a row are scanned into the fields of t, rather than t being
+// a single value such as a time.Time or a sql.Scanner
+func isStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PointerTo(t).Implements(scannerType)
+}
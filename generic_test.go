@@ -0,0 +1,177 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestQueryGeneric(t *testing.T) {
+	defer Close()
+	testHosts.setTestResult(t, "select staff", []string{"id", "name", "born"},
+		[]driver.Value{int64(1), "john doe", time.Unix(1, 0)}, []driver.Value{int64(2), nil, time.Unix(2, 0)})
+	testHosts.setTestResult(t, "select born", []string{"born", "name"}, []driver.Value{time.Unix(1, 0), "john doe"})
+	testHosts.setTestResult(t, "select person", []string{"id", "name"}, []driver.Value{int64(1), "john doe"})
+	db, err := New("generic", "ksql_hosts", "generic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type person struct {
+		ID   int64
+		Name *string
+	}
+	people, err := Query[person](db, "select staff")
+	if err != nil || len(people) != 2 {
+		t.Fatalf("expected 2 people, got %v %v", people, err)
+	}
+	if people[0].ID != 1 || *people[0].Name != "john doe" || people[1].Name != nil {
+		t.Errorf("expected the rows in the structs, got %+v %+v", people[0], people[1])
+	}
+	pointers, err := Query[*person](db, "select staff")
+	if err != nil || len(pointers) != 2 || pointers[1].ID != 2 {
+		t.Errorf("expected pointers to people, got %v %v", pointers, err)
+	}
+	ids, err := Query[int](db, "select staff")
+	if err != nil || len(ids) != 2 || ids[1] != 2 {
+		t.Errorf("expected the ids from the first column, got %v %v", ids, err)
+	}
+	times, err := QueryContext[time.Time](context.Background(), db, "select born")
+	if err != nil || len(times) != 1 || !times[0].Equal(time.Unix(1, 0)) {
+		t.Errorf("expected a time, got %v %v", times, err)
+	}
+	names, err := Query[sql.NullString](db, "select person")
+	if err != nil || len(names) != 1 || !names[0].Valid {
+		t.Errorf("expected a scanner from the first column, got %v %v", names, err)
+	}
+	testHosts.setTestResult(t, "select photos", []string{"id", "photo"}, []driver.Value{int64(1), []byte("jpeg")})
+	type photo struct {
+		ID    int64
+		Photo []byte
+	}
+	photos, err := Query[photo](db, "select photos")
+	if err != nil || len(photos) != 1 || string(photos[0].Photo) != "jpeg" {
+		t.Errorf("expected the bytes in the struct, got %v %v", photos, err)
+	}
+	if _, err := Query[int](db, "select born"); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected a conversion error, got %v", err)
+	}
+	if _, err := Query[int](db, "fail"); err == nil {
+		t.Error("expected the error of the query")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if ids, err := Query[int64](tx, "select person"); err != nil || len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("expected to query in the transaction, got %v %v", ids, err)
+	}
+}
+
+// Querier keeping the rows it returns, which ksql must not recycle
+type keepingQuerier struct {
+	db   *DB
+	kept []*Rows
+}
+
+func (q *keepingQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	rows, err := q.db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	rs := &Rows{Rows: rows, db: q.db, opts: q.db.read}
+	q.kept = append(q.kept, rs)
+	return rs, nil
+}
+
+func TestQueryGenericRecycling(t *testing.T) {
+	defer Close()
+	db, err := New("recycling", "ksql_hosts", "recycling")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := &keepingQuerier{db: db}
+	if _, err := Query[int64](q, "select person"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := QueryScalar[int64](q, "select person"); err != nil {
+		t.Fatal(err)
+	}
+	for _, rows := range q.kept {
+		if rows.db != db {
+			t.Errorf("expected the rows of the querier not to be recycled")
+		}
+	}
+}
+
+func TestQueryScalar(t *testing.T) {
+	defer Close()
+	testHosts.setTestResult(t, "select count", []string{"count"}, []driver.Value{int64(42)})
+	testHosts.setTestResult(t, "select nothing", []string{"id"})
+	testHosts.setTestResult(t, "select null", []string{"name"}, []driver.Value{nil})
+	testHosts.setTestResult(t, "select person", []string{"id", "name"}, []driver.Value{int64(1), "john doe"})
+	db, err := New("scalar", "ksql_hosts", "scalar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count, err := QueryScalar[int64](db, "select count"); err != nil || count != 42 {
+		t.Errorf("expected 42, got %d %v", count, err)
+	}
+	if id, err := QueryScalarContext[int](context.Background(), db, "select person"); err != nil || id != 1 {
+		t.Errorf("expected the first column, got %d %v", id, err)
+	}
+	if _, err := QueryScalar[int64](db, "select nothing"); err != ErrNoRows {
+		t.Errorf("expected ErrNoRows, got %v", err)
+	}
+	if _, err := QueryScalar[string](db, "select null"); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected NULL to fail a string, got %v", err)
+	}
+	if name, err := QueryScalar[*string](db, "select null"); err != nil || name != nil {
+		t.Errorf("expected a nil pointer, got %v %v", name, err)
+	}
+	if name, err := QueryScalar[sql.NullString](db, "select null"); err != nil || name.Valid {
+		t.Errorf("expected an invalid NullString, got %v %v", name, err)
+	}
+	if _, err := QueryScalar[int64](db, "fail"); err == nil {
+		t.Error("expected the error of the query")
+	}
+
+	zero, err := New("scalar zero", "ksql_hosts", "scalar zero", WithNullAsZero())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, err := QueryScalar[string](zero, "select null"); err != nil || name != "" {
+		t.Errorf("expected the zero value, got %q %v", name, err)
+	}
+}
+
+func TestPluck(t *testing.T) {
+	defer Close()
+	testHosts.setTestResult(t, "select ids", []string{"id", "name"},
+		[]driver.Value{int64(1), "john doe"}, []driver.Value{int64(2), "jane doe"})
+	testHosts.setTestResult(t, "select names", []string{"name", "id"},
+		[]driver.Value{"john doe", int64(1)}, []driver.Value{"jane doe", int64(2)})
+	testHosts.setTestResult(t, "select uuids", []string{"id"}, []driver.Value{"6ba7b810-9dad-11d1-80b4-00c04fd430c8"})
+	db, err := New("pluck", "ksql_hosts", "pluck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := db.PluckIntegers("select ids"); err != nil || len(ids) != 2 || ids[1] != 2 {
+		t.Errorf("expected the ids, got %v %v", ids, err)
+	}
+	if names, err := db.PluckStrings("select names"); err != nil || len(names) != 2 || names[0] != "john doe" {
+		t.Errorf("expected the names, got %v %v", names, err)
+	}
+	if ids, err := Pluck[uint8](db, "select ids"); err != nil || len(ids) != 2 {
+		t.Errorf("expected the ids as bytes, got %v %v", ids, err)
+	}
+	if ids, err := PluckContext[UUID](context.Background(), db, "select uuids"); err != nil || len(ids) != 1 {
+		t.Errorf("expected a UUID, got %v %v", ids, err)
+	}
+	if _, err := db.PluckIntegers("select names"); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected a conversion error, got %v", err)
+	}
+}
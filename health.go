@@ -0,0 +1,137 @@
+package ksql
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Health of a named database connection, as seen by the last check
+type Health struct {
+	Name      string
+	Up        bool
+	LastError error
+	LastCheck time.Time
+	Since     time.Time // time of the last change between up and down
+}
+
+// Background checker that periodically pings every named database connection. The fields
+// must be set before Start is called.
+type HealthChecker struct {
+	Interval time.Duration // time between checks, defaults to 10 seconds
+	Timeout  time.Duration // timeout of every ping, defaults to the interval
+	OnChange func(Health)  // called when a connection goes up or down, if set
+
+	mu    sync.Mutex
+	state map[string]Health
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// Start checking in the background, the first check is run right away
+func (hc *HealthChecker) Start() {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if hc.stop != nil {
+		return
+	}
+	hc.stop = make(chan struct{})
+	hc.done = make(chan struct{})
+	go hc.run(hc.stop, hc.done)
+}
+
+// Stop checking in the background, and wait for a running check to finish
+func (hc *HealthChecker) Stop() {
+	hc.mu.Lock()
+	stop, done := hc.stop, hc.done
+	hc.stop, hc.done = nil, nil
+	hc.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func (hc *HealthChecker) run(stop, done chan struct{}) {
+	defer close(done)
+	interval := hc.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		hc.Check(context.Background())
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Ping every named database connection now, and get the resulting report
+func (hc *HealthChecker) Check(ctx context.Context) []Health {
+	timeout := hc.Timeout
+	if timeout <= 0 {
+		timeout = hc.Interval
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	names := Databases()
+	results := make([]Health, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		db, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, name string, db *DB) {
+			defer wg.Done()
+			pingCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			err := db.PingContext(pingCtx)
+			results[i] = Health{Name: name, Up: err == nil, LastError: err, LastCheck: time.Now()}
+		}(i, name, db)
+	}
+	wg.Wait()
+	var changed []Health
+	hc.mu.Lock()
+	state := make(map[string]Health, len(results))
+	for _, h := range results {
+		if h.Name == "" {
+			continue
+		}
+		previous, seen := hc.state[h.Name]
+		if seen && previous.Up == h.Up {
+			h.Since = previous.Since
+		} else {
+			h.Since = h.LastCheck
+			changed = append(changed, h)
+		}
+		state[h.Name] = h
+	}
+	hc.state = state
+	hc.mu.Unlock()
+	if hc.OnChange != nil {
+		for _, h := range changed {
+			hc.OnChange(h)
+		}
+	}
+	return hc.Report()
+}
+
+// Get the health of every named database connection as of the last check, sorted by name
+func (hc *HealthChecker) Report() []Health {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	report := make([]Health, 0, len(hc.state))
+	for _, h := range hc.state {
+		report = append(report, h)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Name < report[j].Name })
+	return report
+}
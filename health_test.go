@@ -0,0 +1,40 @@
+package ksql
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHealthChecker(t *testing.T) {
+	defer Close()
+	if _, err := New("first", "ksql_hosts", "first"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New("second", "ksql_hosts", "second"); err != nil {
+		t.Fatal(err)
+	}
+	var changes []Health
+	hc := &HealthChecker{OnChange: func(h Health) { changes = append(changes, h) }}
+	report := hc.Check(context.Background())
+	if len(report) != 2 || report[0].Name != "first" || !report[0].Up || report[1].Name != "second" || !report[1].Up {
+		t.Fatalf("expected both connections up, got %+v", report)
+	}
+	if len(changes) != 2 {
+		t.Errorf("expected both connections to be reported on the first check, got %+v", changes)
+	}
+	changes = nil
+	testHosts.setDown("second", true)
+	defer testHosts.setDown("second", false)
+	report = hc.Check(context.Background())
+	if !report[0].Up || report[1].Up || report[1].LastError == nil {
+		t.Errorf("expected the second connection down, got %+v", report)
+	}
+	if len(changes) != 1 || changes[0].Name != "second" || changes[0].Up {
+		t.Errorf("expected the second connection to be reported down, got %+v", changes)
+	}
+	if !report[0].Since.Before(report[0].LastCheck) {
+		t.Errorf("expected the first connection to be up since the first check, got %+v", report[0])
+	}
+	hc.Start()
+	hc.Stop()
+}
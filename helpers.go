@@ -0,0 +1,100 @@
+package ksql
+
+import "context"
+
+// Run the query and call fn for every row, stopping at the first error. The rows are always
+// closed, and the error of fn, of the rows, or of closing them is returned.
+func (db *DB) ForEach(query string, fn func(r *Rows) error, args ...interface{}) error {
+	return db.ForEachContext(context.Background(), query, fn, args...)
+}
+
+func (db *DB) ForEachContext(ctx context.Context, query string, fn func(r *Rows) error, args ...interface{}) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// Run a query such as "select count(*) from people" and get the integer in the first column
+// of its first row, or 0 when it returns no rows. Counts returned as text are parsed.
+func (db *DB) Count(query string, args ...interface{}) (int64, error) {
+	return db.CountContext(context.Background(), query, args...)
+}
+
+func (db *DB) CountContext(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return 0, rows.Err()
+	}
+	value, err := validateIndex(rows, 0)
+	if err != nil || value == nil {
+		return 0, err
+	}
+	count, err := convertToInt(lenientValue(value, int64(0)))
+	if err != nil {
+		return 0, err
+	}
+	return count, rows.Close()
+}
+
+// Run the query and report whether it returns any row, as in "select 1 from people where id=$1".
+// The query is not rewritten, so "select exists(...)" always returns a row.
+func (db *DB) Exists(query string, args ...interface{}) (bool, error) {
+	return db.ExistsContext(context.Background(), query, args...)
+}
+
+func (db *DB) ExistsContext(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return false, rows.Err()
+	}
+	return true, rows.Close()
+}
+
+// Execute the statement and get the number of rows it affected
+func (db *DB) ExecAffected(query string, args ...interface{}) (int64, error) {
+	return db.ExecAffectedContext(context.Background(), query, args...)
+}
+
+func (db *DB) ExecAffectedContext(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Execute the statement and return ErrNotOneRow unless it affected exactly one row, such as
+// an UPDATE whose WHERE clause matched nothing
+func (db *DB) ExecOne(query string, args ...interface{}) error {
+	return db.ExecOneContext(context.Background(), query, args...)
+}
+
+func (db *DB) ExecOneContext(ctx context.Context, query string, args ...interface{}) error {
+	n, err := db.ExecAffectedContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return ErrNotOneRow
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestForEach(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select people", []string{"id", "name"},
+		[]driver.Value{int64(1), "john doe"}, []driver.Value{int64(2), "jane doe"})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	err = db.ForEach("select people", func(r *Rows) error {
+		id, err := r.GetInteger("id")
+		ids = append(ids, id)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[1] != 2 {
+		t.Errorf("expected ids 1 and 2, got %v", ids)
+	}
+	stop := errors.New("stop")
+	calls := 0
+	err = db.ForEach("select people", func(r *Rows) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("expected to stop at the first error, got %v after %d calls", err, calls)
+	}
+	if err := db.ForEach("fail", func(r *Rows) error { return nil }); err == nil {
+		t.Errorf("expected the query error")
+	}
+}
+
+func TestCountExists(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select count", []string{"count"}, []driver.Value{int64(3)})
+	testHosts.setResult("select text count", []string{"count"}, []driver.Value{[]byte("4")})
+	testHosts.setResult("select null count", []string{"count"}, []driver.Value{nil})
+	testHosts.setResult("select nothing", []string{"count"})
+	testHosts.setResult("select name", []string{"name"}, []driver.Value{"john doe"})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]int64{"select count": 3, "select text count": 4, "select null count": 0, "select nothing": 0}
+	for query, want := range tests {
+		count, err := db.Count(query)
+		if err != nil {
+			t.Errorf("%q: %v", query, err)
+			continue
+		}
+		if count != want {
+			t.Errorf("expected %d for %q, got %d", want, query, count)
+		}
+	}
+	if _, err := db.Count("select name"); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected a conversion error, got %v", err)
+	}
+	exists, err := db.Exists("select people")
+	if err != nil || !exists {
+		t.Errorf("expected a row to exist, got %v %v", exists, err)
+	}
+	exists, err = db.Exists("select nothing")
+	if err != nil || exists {
+		t.Errorf("expected no row to exist, got %v %v", exists, err)
+	}
+	if _, err := db.Exists("fail"); err == nil {
+		t.Errorf("expected the query error")
+	}
+}
+
+func TestExecAffected(t *testing.T) {
+	defer Close()
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every statement of the test driver affects a single row
+	n, err := db.ExecAffected("update people set married=true")
+	if err != nil || n != 1 {
+		t.Errorf("expected 1 row affected, got %d %v", n, err)
+	}
+	if err := db.ExecOne("update people set married=true where id=1"); err != nil {
+		t.Errorf("expected exactly one row affected, got %v", err)
+	}
+	if err := db.ExecOne("fail"); err == nil || err == ErrNotOneRow {
+		t.Errorf("expected the statement error, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// Kinds of statements reported to hooks
+const (
+	OpQuery = "query" // Query and QueryRow
+	OpExec  = "exec"
+	// a transaction, from begin to commit or rollback, with a Query of "COMMIT" or
+	// "ROLLBACK" once it ends, empty when it fails to begin
+	OpTx = "tx"
+)
+
+// Details of a statement run through a DB, Tx or Stmt, as passed to hooks
+type QueryEvent struct {
+	DB           string        // name of the database connection
+	Op           string        // OpQuery, OpExec or OpTx
+	Query        string        // text of the statement
+	Args         []interface{} // arguments of the statement
+	Start        time.Time     // set before BeforeQuery is called
+	End          time.Time     // set before AfterQuery is called
+	RowsAffected int64         // rows affected by an exec, or -1 when unknown
+	Err          error         // error of the statement, set before AfterQuery is called
+	Slow         bool          // set before AfterQuery is called, see WithSlowQueryThreshold
+
+	hooks []Hook
+}
+
+// Get the time the statement took to run
+func (e *QueryEvent) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+// Hook is called around every statement run through a DB, Tx or Stmt. BeforeQuery may return
+// a derived context that is used to run the statement and passed to AfterQuery, or an error
+// that stops the statement from running. AfterQuery is called for every statement, including
+// the ones stopped by BeforeQuery.
+type Hook interface {
+	BeforeQuery(ctx context.Context, event *QueryEvent) (context.Context, error)
+	AfterQuery(ctx context.Context, event *QueryEvent)
+}
+
+// Hooks of every database connection
+var (
+	hooksMu sync.RWMutex
+	hooks   []Hook
+)
+
+// Add a hook called around the statements of every database connection
+func AddHook(h Hook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, h)
+}
+
+// Add a hook called around the statements of this database connection
+func WithHook(h Hook) Option {
+	return func(db *DB) error {
+		db.hooks = append(db.hooks, h)
+		return nil
+	}
+}
+
+// Flag the statements of this database connection that take at least the threshold as slow,
+// they are logged as such by WithLogger, and hooks can check QueryEvent.Slow
+func WithSlowQueryThreshold(threshold time.Duration) Option {
+	return func(db *DB) error {
+		db.slowThreshold = threshold
+		return nil
+	}
+}
+
+// Get the global hooks followed by the hooks of this database
+func (db *DB) allHooks() []Hook {
+	hooksMu.RLock()
+	all := hooks
+	hooksMu.RUnlock()
+	if db != nil && len(db.hooks) > 0 {
+		all = append(all[:len(all):len(all)], db.hooks...)
+	}
+	return all
+}
+
+// Start the event of a statement and call the BeforeQuery hooks. The event is nil when
+// there are no hooks to call.
+func (db *DB) beforeQuery(ctx context.Context, op, query string, args []interface{}) (context.Context, *QueryEvent, error) {
+	db.markUsed()
+	all := db.allHooks()
+	if len(all) == 0 {
+		return ctx, nil, nil
+	}
+	event := &QueryEvent{Op: op, Query: query, Args: args, Start: time.Now(), RowsAffected: -1, hooks: all}
+	if db != nil {
+		event.DB = db.name
+	}
+	for _, h := range all {
+		next, err := h.BeforeQuery(ctx, event)
+		if err != nil {
+			db.afterQuery(ctx, event, nil, err)
+			return ctx, nil, err
+		}
+		ctx = next
+	}
+	return ctx, event, nil
+}
+
+// Finish the event of a statement and call the AfterQuery hooks
+func (db *DB) afterQuery(ctx context.Context, event *QueryEvent, result sql.Result, err error) {
+	if event == nil {
+		return
+	}
+	event.End = time.Now()
+	event.Err = err
+	if db != nil && db.slowThreshold > 0 && event.Op != OpTx {
+		event.Slow = event.Duration() >= db.slowThreshold
+	}
+	if result != nil {
+		if n, err := result.RowsAffected(); err == nil {
+			event.RowsAffected = n
+		}
+	}
+	for _, h := range event.hooks {
+		h.AfterQuery(ctx, event)
+	}
+}
@@ -0,0 +1,149 @@
+package ksql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type ctxKey string
+
+// Hook recording the events it sees, and rejecting the statements of its policy
+type recordingHook struct {
+	before []QueryEvent
+	after  []QueryEvent
+	reject string
+}
+
+func (h *recordingHook) BeforeQuery(ctx context.Context, event *QueryEvent) (context.Context, error) {
+	h.before = append(h.before, *event)
+	if h.reject != "" && event.Query == h.reject {
+		return ctx, errors.New("rejected")
+	}
+	return context.WithValue(ctx, ctxKey("hook"), event), nil
+}
+
+func (h *recordingHook) AfterQuery(ctx context.Context, event *QueryEvent) {
+	if event.Err == nil && ctx.Value(ctxKey("hook")) != event {
+		panic("expected the context of BeforeQuery")
+	}
+	h.after = append(h.after, *event)
+}
+
+func TestHooks(t *testing.T) {
+	defer Close()
+	global := &recordingHook{}
+	AddHook(global)
+	defer func() { hooks = nil }()
+	local := &recordingHook{reject: "delete from people"}
+	db, err := New("hosts", "ksql_hosts", "hosts", WithHook(local))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("update people set married = $1", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.QueryRow("select * from people").GetString("name"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("fail"); err == nil {
+		t.Fatalf("expected the statement to fail")
+	}
+	if _, err := db.Exec("delete from people"); err == nil || err.Error() != "rejected" {
+		t.Fatalf("expected the hook to reject the statement, got %v", err)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := tx.Prepare("insert into people values ($1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stmt.Exec(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if len(global.after) != 6 || len(local.after) != 6 || len(local.before) != 6 {
+		t.Fatalf("expected 6 events in every hook, got %d, %d and %d", len(global.after), len(local.before), len(local.after))
+	}
+	if len(global.before) != 6 {
+		t.Errorf("expected the global hook before the rejecting hook, got %d events", len(global.before))
+	}
+	want := []struct {
+		op, query    string
+		rowsAffected int64
+		failed       bool
+	}{
+		{OpExec, "update people set married = $1", 1, false},
+		{OpQuery, "select * from people", -1, false},
+		{OpExec, "fail", -1, true},
+		{OpExec, "delete from people", -1, true},
+		{OpExec, "insert into people values ($1)", 1, false},
+		{OpTx, "COMMIT", -1, false},
+	}
+	for i, w := range want {
+		e := local.after[i]
+		if e.DB != "hosts" || e.Op != w.op || e.Query != w.query || e.RowsAffected != w.rowsAffected || (e.Err != nil) != w.failed {
+			t.Errorf("event %d: expected %+v, got %+v", i, w, e)
+		}
+		if e.End.Before(e.Start) {
+			t.Errorf("event %d: expected the end after the start", i)
+		}
+	}
+	if len(local.after[0].Args) != 1 || local.after[0].Args[0] != true {
+		t.Errorf("expected the arguments of the update, got %v", local.after[0].Args)
+	}
+}
+
+func TestReplicaHooks(t *testing.T) {
+	defer Close()
+	// three hooks leave spare capacity in the slice of the primary
+	primary := []Option{WithHook(&recordingHook{}), WithHook(&recordingHook{}), WithHook(&recordingHook{})}
+	if _, err := New("replica hooks", "ksql_hosts", "replica hooks", primary...); err != nil {
+		t.Fatal(err)
+	}
+	first, second := &recordingHook{}, &recordingHook{}
+	a, err := NewReplica("replica hooks", "ksql_hosts", "replica hooks a", WithHook(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewReplica("replica hooks", "ksql_hosts", "replica hooks b", WithHook(second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Exec("update people"); err != nil {
+		t.Fatal(err)
+	}
+	if len(first.after) != 1 || len(second.after) != 0 {
+		t.Errorf("expected the replica to run its own hook, got %d and %d events", len(first.after), len(second.after))
+	}
+}
+
+func TestReplicaSettings(t *testing.T) {
+	defer Close()
+	_, err := New("replica settings", "ksql_hosts", "replica settings", WithSlowQueryThreshold(time.Nanosecond),
+		WithConcurrencyLimit(2, 0), WithoutRowsPooling())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook := &recordingHook{}
+	replica, err := NewReplica("replica settings", "ksql_hosts", "replica settings a", WithHook(hook))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := replica.Exec("update people"); err != nil {
+		t.Fatal(err)
+	}
+	if len(hook.after) != 1 || !hook.after[0].Slow {
+		t.Errorf("expected the statement of the replica to be flagged slow, got %+v", hook.after)
+	}
+	if _, limit := replica.Concurrency(); limit != 2 {
+		t.Errorf("expected the replica to be limited like its primary, got %d", limit)
+	}
+	if !replica.noPool {
+		t.Errorf("expected the replica not to pool its rows like its primary")
+	}
+}
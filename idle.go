@@ -0,0 +1,65 @@
+package ksql
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Close and remove the database connection once it has not been used for the timeout, for
+// connections created dynamically such as the ones of tenants or shards, so the saved
+// connections don't grow without bound. The connection is in use while running statements
+// or transactions, including through its replicas, while rows are open, and when asked for
+// by Get or Resolve. It is checked about every half of the timeout.
+func WithIdleEviction(timeout time.Duration) Option {
+	return func(db *DB) error {
+		if timeout <= 0 {
+			return nil
+		}
+		db.used = new(atomic.Int64)
+		db.markUsed()
+		stop := make(chan struct{})
+		db.onClose = append(db.onClose, func() { close(stop) })
+		// started once saved, it stops when the database is no longer saved by name
+		db.onOpen = append(db.onOpen, func() { go db.evictIdle(timeout, stop) })
+		return nil
+	}
+}
+
+// Record the use of the database, when idle eviction is enabled
+func (db *DB) markUsed() {
+	if db != nil && db.used != nil {
+		db.used.Store(time.Now().UnixNano())
+	}
+}
+
+func (db *DB) evictIdle(timeout time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(timeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		if done := db.evictIfIdle(timeout); done {
+			return
+		}
+	}
+}
+
+// Close and remove the database when idle, and report whether its eviction is over, either
+// way. It is held by poolMu, so Get can't hand it out meanwhile.
+func (db *DB) evictIfIdle(timeout time.Duration) bool {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if pool[db.name] != db {
+		// closed or released
+		return true
+	}
+	if time.Since(time.Unix(0, db.used.Load())) < timeout || db.inUse() > 0 {
+		return false
+	}
+	delete(pool, db.name)
+	db.close()
+	return true
+}
@@ -0,0 +1,85 @@
+package ksql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdleEviction(t *testing.T) {
+	defer Close()
+	db, err := New("idle", "ksql_hosts", "idle", WithIdleEviction(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// statements keep the connection
+	for i := 0; i < 10; i++ {
+		if _, err := db.Exec("update people"); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, ok := lookup("idle"); !ok {
+		t.Fatal("expected the used connection to stay open")
+	}
+	// so do open rows
+	rows, err := db.Query("select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := lookup("idle"); !ok {
+		t.Fatal("expected the connection with open rows to stay open")
+	}
+	rows.Close()
+	waitEvicted(t, "idle")
+	if err := db.Ping(); err == nil {
+		t.Error("expected the evicted connection to be closed")
+	}
+	// the name can be taken again
+	if _, err := New("idle", "ksql_hosts", "idle"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIdleEvictionSlowOpen(t *testing.T) {
+	defer Close()
+	// an option slower than the first check, such as a ping
+	slow := func(db *DB) error {
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	}
+	if _, err := New("idle slow", "ksql_hosts", "idle slow", WithIdleEviction(20*time.Millisecond), slow); err != nil {
+		t.Fatal(err)
+	}
+	waitEvicted(t, "idle slow")
+}
+
+func TestIdleEvictionShards(t *testing.T) {
+	defer Close()
+	if _, err := NewShardGroup("idle shards", "ksql_hosts", map[string]string{"a": "idle a"}, nil,
+		WithIdleEviction(20*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	// asking for the connection by name counts as a use
+	for i := 0; i < 10; i++ {
+		if _, ok := Get("idle shards:a"); !ok {
+			t.Fatal("expected the shard to stay open")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	waitEvicted(t, "idle shards:a")
+}
+
+func waitEvicted(t *testing.T, name string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := lookup(name); !ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %q to be evicted once idle", name)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,89 @@
+package ksql
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+	"unicode/utf8"
+)
+
+// Formatting of the values of rows written as JSON
+type JSONOptions struct {
+	// Layout of times, time.RFC3339Nano when empty
+	TimeLayout string
+	// Write bytes as base64 strings even when they are valid UTF-8 text
+	Base64Bytes bool
+}
+
+// Consume every remaining row into a JSON array of objects keyed by column name, in column
+// order, and close the rows. NULL is written as null, bytes as text when valid UTF-8 and as
+// base64 otherwise, and times in RFC 3339 format.
+func (rs *Rows) WriteJSON(w io.Writer) error {
+	return rs.WriteJSONWith(w, JSONOptions{})
+}
+
+// Consume every remaining row into a JSON array of objects, formatting values with opts,
+// and close the rows
+func (rs *Rows) WriteJSONWith(w io.Writer, opts JSONOptions) error {
+	defer rs.Close()
+	bw := bufio.NewWriter(w)
+	bw.WriteByte('[')
+	for n := 0; rs.Next(); n++ {
+		if n > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteByte('{')
+		for i, column := range rs.columns {
+			if i > 0 {
+				bw.WriteByte(',')
+			}
+			key, err := json.Marshal(column)
+			if err != nil {
+				return err
+			}
+			bw.Write(key)
+			bw.WriteByte(':')
+			value, err := json.Marshal(jsonValue(rs.values[i], opts))
+			if err != nil {
+				return err
+			}
+			bw.Write(value)
+		}
+		bw.WriteByte('}')
+	}
+	if err := rs.Err(); err != nil {
+		return err
+	}
+	bw.WriteByte(']')
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return rs.Close()
+}
+
+// Consume every remaining row into a JSON array of objects, see Rows.WriteJSON
+func (rs *Rows) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := rs.WriteJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func jsonValue(value interface{}, opts JSONOptions) interface{} {
+	switch value := value.(type) {
+	case []byte:
+		if !opts.Base64Bytes && utf8.Valid(value) {
+			return string(value)
+		}
+	case time.Time:
+		layout := opts.TimeLayout
+		if layout == "" {
+			layout = time.RFC3339Nano
+		}
+		return value.Format(layout)
+	}
+	return value
+}
@@ -0,0 +1,72 @@
+package ksql
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWriteJSON(t *testing.T) {
+	defer Close()
+	modified := time.Date(2016, time.January, 2, 3, 4, 5, 0, time.UTC)
+	testHosts.setResult("select people", []string{"id", "name", "nickname", "avatar", "modified"},
+		[]driver.Value{int64(1), []byte("john doe"), nil, []byte{0xff, 0x00}, modified},
+		[]driver.Value{int64(2), "jane \"jd\" doe", "jd", nil, modified})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := rows.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"id":1,"name":"john doe","nickname":null,"avatar":"/wA=","modified":"2016-01-02T03:04:05Z"},` +
+		`{"id":2,"name":"jane \"jd\" doe","nickname":"jd","avatar":null,"modified":"2016-01-02T03:04:05Z"}]`
+	if buf.String() != want {
+		t.Errorf("expected %s, got %s", want, buf.String())
+	}
+	rows, err = db.Query("select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := rows.WriteJSONWith(&buf, JSONOptions{TimeLayout: "2006-01-02", Base64Bytes: true}); err != nil {
+		t.Fatal(err)
+	}
+	var result []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result[0]["name"] != "am9obiBkb2U=" || result[0]["modified"] != "2016-01-02" {
+		t.Errorf("expected base64 bytes and dates, got %v", result[0])
+	}
+	testHosts.setResult("select nobody", []string{"id"})
+	rows, err = db.Query("select nobody")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "[]" {
+		t.Errorf("expected an empty array, got %s", data)
+	}
+	testHosts.setResult("select nan", []string{"ratio"}, []driver.Value{math.NaN()})
+	rows, err = db.Query("select nan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unsupported *json.UnsupportedValueError
+	if err := rows.WriteJSON(&buf); !errors.As(err, &unsupported) {
+		t.Errorf("expected an unsupported value error, got %v", err)
+	}
+}
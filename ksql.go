@@ -6,11 +6,19 @@
 package ksql
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"log/slog"
+	"math/big"
 	"reflect"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -24,8 +32,25 @@ var (
 var (
 	ErrNoRows                      = sql.ErrNoRows
 	ErrDupConnName                 = errors.New("ksql: duplicate database connection name")
+	ErrConnNotFound                = errors.New("ksql: database connection not found")
+	ErrNoDataSource                = errors.New("ksql: no data source names given")
+	ErrInvalidBatch                = errors.New("ksql: batch rows must match the columns")
+	ErrInvalidUpsert               = errors.New("ksql: upsert values must be a map or struct holding the keys")
+	ErrUnsupportedDialect          = errors.New("ksql: not supported by the database dialect")
+	ErrNotOneRow                   = errors.New("ksql: statement did not affect exactly one row")
+	ErrDuplicateColumn             = errors.New("ksql: duplicate column name in result")
+	ErrQueryNotFound               = errors.New("ksql: named query not found")
+	ErrShuttingDown                = errors.New("ksql: database connection is shutting down")
+	ErrInvalidPageToken            = errors.New("ksql: invalid page token")
+	ErrSaturated                   = errors.New("ksql: too many concurrent statements")
+	ErrCircuitOpen                 = errors.New("ksql: circuit breaker is open")
 	ErrColumnNotFound              = errors.New("ksql: column not found in result")
 	ErrInvalidColumnTypeConversion = errors.New("ksql: invalid column type conversion")
+	ErrInvalidDestination          = errors.New("ksql: invalid scan destination")
+	ErrNamedParameterNotFound      = errors.New("ksql: named parameter not found")
+	ErrInvalidNamedArgument        = errors.New("ksql: named parameters must be bound from a map or struct")
+	ErrInvalidSavepointName        = errors.New("ksql: invalid savepoint name")
+	ErrEmptyPrefix                 = errors.New("ksql: resolver prefix must not be empty")
 )
 
 func init() {
@@ -44,117 +69,476 @@ func Databases() []string {
 	return list
 }
 
-// Get an open database connection by name
+// Get an open database connection by name, opening it first through the resolver of its
+// prefix when there is one, see Resolver
 func Get(name string) (*DB, bool) {
+	db, err := Resolve(context.Background(), name)
+	return db, err == nil
+}
+
+// Get an open database connection by name, without resolving it
+func lookup(name string) (*DB, bool) {
 	poolMu.RLock()
 	defer poolMu.RUnlock()
 	db, ok := pool[name]
 	return db, ok
 }
 
-// Open a new database connection, and save the reference by name
-func New(name, driver, dsn string) (*DB, error) {
-	poolMu.Lock()
-	defer poolMu.Unlock()
-	// check if the name already exists
-	if _, dup := pool[name]; dup {
+// Open a new database connection, apply the options, and save the reference by name
+func New(name, driver, dsn string, opts ...Option) (*DB, error) {
+	// check if the name already exists before opening anything
+	if _, dup := lookup(name); dup {
 		return nil, ErrDupConnName
 	}
-	db, err := sql.Open(driver, dsn)
+	sqldb, err := sql.Open(driver, dsn)
 	if err != nil {
 		return nil, err
 	}
-	pool[name] = &DB{db}
-	return pool[name], nil
+	db := &DB{DB: sqldb, name: name, bind: bindStyleFor(driver), dialect: dialectFor(driver), dsn: dsn}
+	if err := db.apply(opts); err != nil {
+		sqldb.Close()
+		return nil, err
+	}
+	if err := register(name, db); err != nil {
+		sqldb.Close()
+		return nil, err
+	}
+	return db, nil
 }
 
-// Manage an already open database, and save the reference by name
-func NewWithDB(name string, db *sql.DB) (*DB, error) {
+// Manage an already open database, apply the options, and save the reference by name
+func NewWithDB(name string, sqldb *sql.DB, opts ...Option) (*DB, error) {
+	if _, dup := lookup(name); dup {
+		return nil, ErrDupConnName
+	}
+	db := &DB{DB: sqldb, name: name, bind: bindStyleFor(driverPackage(sqldb)), dialect: dialectFor(driverPackage(sqldb))}
+	if err := db.apply(opts); err != nil {
+		return nil, err
+	}
+	if err := register(name, db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Save the database reference by name, unless the name is already taken
+func register(name string, db *DB) error {
 	poolMu.Lock()
-	defer poolMu.Unlock()
-	// check if the name already exists
 	if _, dup := pool[name]; dup {
-		return nil, ErrDupConnName
+		poolMu.Unlock()
+		return ErrDupConnName
+	}
+	pool[name] = db
+	poolMu.Unlock()
+	for _, fn := range db.onOpen {
+		fn()
+	}
+	db.logOpened()
+	return nil
+}
+
+// Remove the database connection from the saved references without closing it, for a
+// *sql.DB adopted by NewWithDB and still owned elsewhere. The returned connection can still
+// be used and closed by the caller, and the name can be taken again.
+func Release(name string) (*DB, bool) {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	db, ok := pool[name]
+	if ok {
+		delete(pool, name)
 	}
-	pool[name] = &DB{db}
-	return pool[name], nil
+	return db, ok
 }
 
-// Close all open databases connections.
+// Close the database connection saved by name and remove it, even when it fails to close
+func CloseByName(name string) error {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	db, ok := pool[name]
+	if !ok {
+		return ErrConnNotFound
+	}
+	delete(pool, name)
+	return db.close()
+}
+
+// Close all open databases connections, see CloseAll for their errors
 func Close() {
+	CloseAll()
+}
+
+// Close all open database connections and remove them all, including the ones that fail to
+// close, along with the shard groups. The errors are returned by name, or nil when every
+// connection closed.
+func CloseAll() map[string]error {
 	poolMu.Lock()
 	defer poolMu.Unlock()
-	for key := range pool {
-		err := pool[key].DB.Close()
-		if err == nil {
-			delete(pool, key)
+	clear(groups)
+	var errs map[string]error
+	for key, db := range pool {
+		if err := db.close(); err != nil {
+			if errs == nil {
+				errs = make(map[string]error)
+			}
+			errs[key] = err
 		}
+		delete(pool, key)
 	}
+	return errs
 }
 
 // Inherit database/sql.DB
 type DB struct {
 	*sql.DB
+	name    string
+	dsn     string // data source name given to New, for the connections made outside the pool
+	bind    BindStyle
+	dialect Dialect
+
+	mu       sync.RWMutex
+	replicas []*DB
+	next     uint32
+	readPref ReadPreference
+
+	failover  *failoverConnector
+	reconnect *reconnector
+	hooks     []Hook
+
+	logger        *slog.Logger
+	logLevels     LogLevels
+	slowThreshold time.Duration
+	batchSize     int
+	read          readOptions
+	queries       *Queries
+	tags          map[string]string
+	txOptions     *sql.TxOptions
+	limit         *limiter
+	breaker       *breaker
+	retry         *RetryPolicy
+	mapErrors     bool
+	comment       *commenter
+	noPool        bool
+	// time of the last use in unix nanoseconds, shared with the replicas, see WithIdleEviction
+	used *atomic.Int64
+	// set by Shutdown to refuse new statements and transactions
+	draining int32
+	// run once the database is saved by name, to start background work
+	onOpen []func()
+	// run once the database is closed, to stop background work
+	onClose []func()
+}
+
+// Get the name the database connection is saved by
+func (db *DB) Name() string {
+	return db.name
 }
 
-// Close this database connection
+// Get the data source name the database was opened with by New or NewReplica, or "" when it
+// was opened from a *sql.DB or a connector
+func (db *DB) DataSourceName() string {
+	return db.dsn
+}
+
+// Close this database connection and remove it, even when it fails to close
 func (db *DB) Close() error {
 	poolMu.Lock()
 	defer poolMu.Unlock()
-	err := db.DB.Close()
-	if err != nil {
-		return err
-	}
+	err := db.close()
 	for key := range pool {
 		if db == pool[key] {
 			delete(pool, key)
 			break
 		}
 	}
-	return nil
+	return err
+}
+
+// Close the replicas and then the database itself
+func (db *DB) close() error {
+	db.mu.Lock()
+	replicas := db.replicas
+	db.replicas = nil
+	db.mu.Unlock()
+	var first error
+	for _, replica := range replicas {
+		if err := replica.close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	// stop the background work even when closing fails, the database is not used anymore
+	err := db.DB.Close()
+	for _, fn := range db.onClose {
+		fn()
+	}
+	db.onClose = nil
+	if err != nil {
+		return err
+	}
+	return first
 }
 
 func (db *DB) Begin() (*Tx, error) {
-	tx, err := db.DB.Begin()
+	return db.BeginTx(context.Background(), nil)
+}
+
+// Begin a transaction with the options, or with the default options of WithTxOptions when nil
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	if db.isDraining() {
+		return nil, ErrShuttingDown
+	}
+	if opts == nil {
+		opts = db.txOptions
+	}
+	record, err := db.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+	release, err := db.limit.acquire(ctx)
+	if err != nil {
+		record(errNotRun)
+		return nil, err
+	}
+	ctx, event, err := db.beforeQuery(ctx, OpTx, "", nil)
 	if err != nil {
+		record(errNotRun)
+		release()
 		return nil, err
 	}
-	return &Tx{tx}, nil
+	tx, err := db.DB.BeginTx(ctx, opts)
+	record(err)
+	if err != nil {
+		release()
+		db.afterQuery(ctx, event, nil, err)
+		return nil, db.mapError(err)
+	}
+	return &Tx{Tx: tx, db: db, ctx: ctx, event: event, release: release}, nil
 }
 
 func (db *DB) Prepare(query string) (*Stmt, error) {
-	stmt, err := db.DB.Prepare(query)
+	return db.PrepareContext(context.Background(), query)
+}
+
+func (db *DB) PrepareContext(ctx context.Context, query string) (*Stmt, error) {
+	if db.isDraining() {
+		return nil, ErrShuttingDown
+	}
+	record, err := db.breaker.allow()
 	if err != nil {
 		return nil, err
 	}
-	return &Stmt{stmt}, nil
+	stmt, err := db.DB.PrepareContext(ctx, query)
+	record(err)
+	if err != nil {
+		return nil, db.mapError(err)
+	}
+	return &Stmt{Stmt: stmt, db: db, query: query, limit: db.limit, breaker: db.breaker}, nil
+}
+
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if db.isDraining() {
+		return nil, ErrShuttingDown
+	}
+	if db.retry == nil && db.reconnect == nil {
+		result, err := db.exec(ctx, query, args)
+		return result, db.mapError(err)
+	}
+	var result sql.Result
+	err := db.resilient(ctx, func() error {
+		var err error
+		result, err = db.exec(ctx, query, args)
+		return err
+	})
+	return result, db.mapError(err)
+}
+
+// Execute the statement once
+func (db *DB) exec(ctx context.Context, query string, args []interface{}) (sql.Result, error) {
+	record, err := db.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+	release, err := db.limit.acquire(ctx)
+	if err != nil {
+		record(errNotRun)
+		return nil, err
+	}
+	defer release()
+	ctx, event, err := db.beforeQuery(ctx, OpExec, query, args)
+	if err != nil {
+		record(errNotRun)
+		return nil, err
+	}
+	result, err := db.DB.ExecContext(ctx, db.annotate(ctx, query), args...)
+	record(err)
+	db.afterQuery(ctx, event, result, err)
+	return result, err
 }
 
 func (db *DB) Query(query string, args ...interface{}) (*Rows, error) {
-	rows, err := db.DB.Query(query, args...)
+	return db.QueryContext(context.Background(), query, args...)
+}
+
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	if db.isDraining() {
+		return nil, ErrShuttingDown
+	}
+	if db.readPref == ReadReplica {
+		if replica := db.Replica(); replica != db {
+			rows, err := replica.QueryContext(ctx, query, args...)
+			if err != ErrCircuitOpen {
+				return rows, err
+			}
+			// the replica is down, the primary takes its queries until it recovers
+		}
+	}
+	if db.retry == nil && db.reconnect == nil {
+		rows, err := db.query(ctx, query, args)
+		return rows, db.mapError(err)
+	}
+	var rows *Rows
+	err := db.resilient(ctx, func() error {
+		var err error
+		rows, err = db.query(ctx, query, args)
+		return err
+	})
+	return rows, db.mapError(err)
+}
+
+// Run the statement with the retries of WithRetryPolicy, and once more after reconnecting
+// for NewWithReconnect
+func (db *DB) resilient(ctx context.Context, fn func() error) error {
+	if db.retry != nil {
+		run := fn
+		fn = func() error { return db.retry.do(ctx, connectionFailure, run) }
+	}
+	if db.reconnect != nil {
+		return db.reconnecting(ctx, fn)
+	}
+	return fn()
+}
+
+// Run the query once
+func (db *DB) query(ctx context.Context, query string, args []interface{}) (*Rows, error) {
+	record, err := db.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+	release, err := db.limit.acquire(ctx)
 	if err != nil {
+		record(errNotRun)
 		return nil, err
 	}
-	return &Rows{Rows: rows}, nil
+	ctx, event, err := db.beforeQuery(ctx, OpQuery, query, args)
+	if err != nil {
+		record(errNotRun)
+		release()
+		return nil, err
+	}
+	start := time.Now()
+	rows, err := db.DB.QueryContext(ctx, db.annotate(ctx, query), args...)
+	record(err)
+	db.afterQuery(ctx, event, nil, err)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	rs := db.newRows(rows, start)
+	rs.release = release
+	return rs, nil
 }
 
 func (db *DB) QueryRow(query string, args ...interface{}) *Row {
-	rows, err := db.Query(query, args...)
+	return db.QueryRowContext(context.Background(), query, args...)
+}
+
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	rows, err := db.QueryContext(ctx, query, args...)
 	return &Row{rows: rows, err: err}
 }
 
-// Inherit database/sql.Rows
+// Inherit database/sql.Rows. The values of every row are scanned into the same buffers, so
+// reading a row allocates nothing beyond what database/sql does to copy the values out of
+// the driver, see BenchmarkRowsNext.
 type Rows struct {
 	*sql.Rows
+	db      *DB
 	err     error
 	columns []string
-	loader  []interface{}
-	values  map[string]interface{}
+	// values of the current row by column index, scanned in place through the loader, and
+	// the column indexes by name, kept from row to row of a result set
+	values []interface{}
+	loader []interface{}
+	index  map[string]int
+	folded map[string]int
+	opts   readOptions
+	// timing of the query
+	start    time.Time
+	firstRow time.Time
+	end      time.Time
+	read     int64
+	// gives back the slot of WithConcurrencyLimit once the rows are done
+	release func()
+	// columns of the prepared statement the rows come from
+	cache *atomic.Pointer[columnCache]
+	// pooled buffers of values and loader, kept on Close when retained
+	buffers *rowBuffers
+	retain  bool
+	// taken from the pool by ksql, rather than made by a Querier of the caller
+	pooled bool
+	// guards the values when the rows are shared by goroutines, see WithConcurrentRows
+	mu *sync.Mutex
+}
+
+// Settings of how the getters read the values of rows
+type readOptions struct {
+	durationUnit  time.Duration
+	foldCase      bool
+	strictColumns bool
+	nullAsZero    bool
+	lenient       bool
+	timeLayouts   []string
+	location      *time.Location
+	concurrent    bool
+}
+
+func (db *DB) newRows(rows *sql.Rows, start time.Time) *Rows {
+	rs := db.pooledRows()
+	*rs = Rows{Rows: rows, db: db, opts: db.read, start: start, retain: db.noPool, pooled: true}
+	if db.read.concurrent {
+		rs.mu = new(sync.Mutex)
+	}
+	return rs
+}
+
+// Lock the values of rows shared by goroutines, see WithConcurrentRows
+func (rs *Rows) lock() {
+	if rs.mu != nil {
+		rs.mu.Lock()
+	}
+}
+
+func (rs *Rows) unlock() {
+	if rs.mu != nil {
+		rs.mu.Unlock()
+	}
+}
+
+// Build rows holding a single row of values that is not read from a query, such as the
+// parameters of a stored procedure, for the getters
+func detachedRows(db *DB, opts readOptions, columns []string, values []interface{}) *Rows {
+	return &Rows{db: db, opts: opts, columns: columns, values: values, index: columnIndex(columns)}
 }
 
 func (rs *Rows) Err() error {
+	if rs.Rows == nil {
+		return rs.err
+	}
 	if err := rs.Rows.Err(); err != nil {
-		return err
+		return rs.db.mapError(err)
 	}
 	if err := rs.err; err != nil {
 		return err
@@ -163,131 +547,743 @@ func (rs *Rows) Err() error {
 }
 
 func (rs *Rows) Next() bool {
+	rs.lock()
+	defer rs.unlock()
 	if rs.err != nil {
 		return false
 	}
 	if more := rs.Rows.Next(); !more {
+		rs.finish()
 		return false
 	}
 	if rs.columns == nil {
-		if rs.columns, rs.err = rs.Rows.Columns(); rs.err != nil {
+		if rs.err = rs.setColumns(); rs.err != nil {
 			return false
 		}
-		rs.loader = make([]interface{}, len(rs.columns))
+	}
+	if rs.err = rs.Rows.Scan(rs.loader...); rs.err != nil {
+		return false
+	}
+	if rs.read == 0 {
+		rs.firstRow = time.Now()
+	}
+	rs.read++
+	return true
+}
+
+// Read the columns of the result set and set up the buffers of the values, reusing the
+// column names and indexes of the previous rows of the same statement when they match
+func (rs *Rows) setColumns() error {
+	columns, err := rs.Rows.Columns()
+	if err != nil {
+		return err
+	}
+	var cached *columnCache
+	if rs.cache != nil {
+		cached = rs.cache.Load()
+	}
+	if cached != nil && slices.Equal(cached.names, columns) {
+		rs.columns, rs.index = cached.columns, cached.index
+	} else {
+		unique, dup := uniqueColumns(columns)
+		if dup && rs.opts.strictColumns {
+			return ErrDuplicateColumn
+		}
+		rs.columns, rs.index = unique, columnIndex(unique)
+		if rs.cache != nil {
+			rs.cache.Store(&columnCache{names: columns, columns: rs.columns, index: rs.index})
+		}
+	}
+	if rs.retain {
+		// retained values outlive the rows, they can't come from the pool
+		n := len(rs.columns)
+		buf := make([]interface{}, 2*n)
+		rs.values, rs.loader = buf[:n:n], buf[n:]
 		for i := range rs.loader {
-			rs.loader[i] = new(interface{})
+			rs.loader[i] = &rs.values[i]
 		}
-		rs.values = make(map[string]interface{})
+		return nil
 	}
-	if rs.err = rs.Rows.Scan(rs.loader...); rs.err != nil {
+	rs.buffers = getBuffers(len(rs.columns))
+	rs.values, rs.loader = rs.buffers.values, rs.buffers.loader
+	return nil
+}
+
+// Columns of the results of a prepared statement, shared by the rows it returns and
+// never modified once stored
+type columnCache struct {
+	names   []string // as returned by the driver
+	columns []string // as saved by the rows, see uniqueColumns
+	index   map[string]int
+}
+
+func (rs *Rows) Close() error {
+	rs.finish()
+	err := rs.Rows.Close()
+	rs.lock()
+	rs.detachBuffers()
+	rs.unlock()
+	return err
+}
+
+// Advance to the next result set of a multi-statement query or stored procedure, and report
+// whether there is one. Next must then be called to read its first row. The columns of the
+// previous result set are forgotten, so the getters read the columns of the new one.
+func (rs *Rows) NextResultSet() bool {
+	rs.lock()
+	defer rs.unlock()
+	if rs.err != nil || !rs.Rows.NextResultSet() {
 		return false
 	}
-	for i := range rs.columns {
-		rs.values[rs.columns[i]] = *(rs.loader[i]).(*interface{})
+	rs.releaseBuffers()
+	rs.columns = nil
+	rs.values = nil
+	rs.loader = nil
+	rs.index = nil
+	rs.folded = nil
+	// the end of the previous result set was not the end of the query
+	rs.end = time.Time{}
+	return true
+}
+
+// Record the end of the query, once
+func (rs *Rows) finish() {
+	if rs.end.IsZero() {
+		rs.end = time.Now()
+	}
+	if rs.release != nil {
+		rs.release()
+		rs.release = nil
+	}
+}
+
+// Get the time from sending the query until the rows were exhausted or closed, or until
+// now while they are still being read
+func (rs *Rows) QueryDuration() time.Duration {
+	if rs.end.IsZero() {
+		return time.Since(rs.start)
+	}
+	return rs.end.Sub(rs.start)
+}
+
+// Get the number of rows read so far
+func (rs *Rows) RowsRead() int64 {
+	return rs.read
+}
+
+// Get the time the first row was read, or the zero time before any row was read
+func (rs *Rows) FirstRowTime() time.Time {
+	return rs.firstRow
+}
+
+// Get the keys the values of the columns are saved by. The first column by a name keeps it,
+// later ones get the lowest free name_2, name_3... so joined queries never lose values.
+func uniqueColumns(columns []string) ([]string, bool) {
+	seen := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		if seen[column] {
+			break
+		}
+		seen[column] = true
+	}
+	if len(seen) == len(columns) {
+		return columns, false
+	}
+	taken := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		taken[column] = true
+	}
+	keys := make([]string, len(columns))
+	used := make(map[string]bool, len(columns))
+	for i, column := range columns {
+		key := column
+		for n := 2; used[key]; n++ {
+			if key = column + "_" + strconv.Itoa(n); taken[key] {
+				key = column
+			}
+		}
+		used[key] = true
+		keys[i] = key
+	}
+	return keys, true
+}
+
+// Check that the rows have a current row with the column, and get the name of the column as
+// returned by the database
+func validateRows(rs *Rows, column string) (int, error) {
+	if err := rs.Err(); err != nil {
+		return 0, err
+	}
+	if rs.values == nil {
+		return 0, ErrNoRows
+	}
+	if i, ok := rs.index[column]; ok {
+		return i, nil
+	}
+	if rs.opts.foldCase {
+		if i, ok := rs.foldedColumns()[strings.ToLower(column)]; ok {
+			return i, nil
+		}
+	}
+	return 0, ErrColumnNotFound
+}
+
+// Get the indexes of the columns by name
+func columnIndex(columns []string) map[string]int {
+	index := make(map[string]int, len(columns))
+	for i, column := range columns {
+		index[column] = i
+	}
+	return index
+}
+
+// Get the lower case column names mapped to the first column by that name in the result
+func (rs *Rows) foldedColumns() map[string]int {
+	if rs.folded == nil {
+		rs.folded = make(map[string]int, len(rs.columns))
+		for i, column := range rs.columns {
+			if _, dup := rs.folded[strings.ToLower(column)]; !dup {
+				rs.folded[strings.ToLower(column)] = i
+			}
+		}
+	}
+	return rs.folded
+}
+
+// Match column names regardless of case, on top of exact matches. When several columns only
+// differ by case, the first one in the result wins.
+func (rs *Rows) SetCaseInsensitive(on bool) {
+	rs.opts.foldCase = on
+}
+
+// Return the zero value from the typed getters for NULL values, rather than
+// ErrInvalidColumnTypeConversion. The nullable getters and IsNull still tell NULL apart.
+func (rs *Rows) SetNullAsZero(on bool) {
+	rs.opts.nullAsZero = on
+}
+
+// Convert a value for a typed getter of the rows, which may read NULL as the zero value
+// and parse text leniently
+func convert[T any](rs *Rows, value interface{}, fn func(interface{}) (T, error)) (T, error) {
+	var zero T
+	if value == nil && rs.opts.nullAsZero {
+		return zero, nil
+	}
+	if rs.opts.lenient {
+		value = lenientValue(value, zero)
+	}
+	return fn(value)
+}
+
+// Read a native bool, the 0 and 1 of a TINYINT(1) or BIT, or the text forms t, f, true,
+// false, 1 and 0 that drivers return for booleans
+func convertToBool(value interface{}) (bool, error) {
+	var s string
+	switch value := value.(type) {
+	case bool:
+		return value, nil
+	case int, int8, int16, int32, int64:
+		switch reflect.ValueOf(value).Int() {
+		case 0:
+			return false, nil
+		case 1:
+			return true, nil
+		}
+		return false, ErrInvalidColumnTypeConversion
+	case []byte:
+		s = string(value)
+	case string:
+		s = value
+	default:
+		return false, ErrInvalidColumnTypeConversion
+	}
+	switch strings.ToLower(s) {
+	case "t", "true", "1":
+		return true, nil
+	case "f", "false", "0":
+		return false, nil
+	}
+	return false, ErrInvalidColumnTypeConversion
+}
+
+func convertToInt(value interface{}) (int64, error) {
+	switch value.(type) {
+	case int, int8, int16, int32, int64:
+		return reflect.ValueOf(value).Int(), nil
+	}
+	return 0, ErrInvalidColumnTypeConversion
+}
+
+func convertToDouble(value interface{}) (float64, error) {
+	switch value.(type) {
+	case float32, float64:
+		return reflect.ValueOf(value).Float(), nil
+	}
+	return 0, ErrInvalidColumnTypeConversion
+}
+
+func convertToString(value interface{}) (string, error) {
+	switch value.(type) {
+	case string:
+		return value.(string), nil
+	case []byte:
+		// mysql and others return text columns as bytes
+		return string(value.([]byte)), nil
+	}
+	return "", ErrInvalidColumnTypeConversion
+}
+
+func convertToBytes(value interface{}) ([]byte, error) {
+	switch value := value.(type) {
+	case []byte:
+		return append([]byte{}, value...), nil
+	case string:
+		return []byte(value), nil
+	case nil:
+		return nil, nil
+	}
+	return nil, ErrInvalidColumnTypeConversion
+}
+
+func convertToDecimal(value interface{}) (string, error) {
+	var s string
+	switch value := value.(type) {
+	case []byte:
+		s = string(value)
+	case string:
+		s = value
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	default:
+		return "", ErrInvalidColumnTypeConversion
+	}
+	// big.Rat also parses fractions, which are not decimal numbers
+	if _, ok := new(big.Rat).SetString(s); !ok || strings.Contains(s, "/") {
+		return "", ErrInvalidColumnTypeConversion
+	}
+	return s, nil
+}
+
+func convertToTime(value interface{}) (time.Time, error) {
+	switch value.(type) {
+	case time.Time:
+		return value.(time.Time), nil
+	}
+	return time.Time{}, ErrInvalidColumnTypeConversion
+}
+
+// Read the calendar date of a time, or of text starting with yyyy-mm-dd, as midnight UTC
+func convertToDate(value interface{}) (time.Time, error) {
+	var s string
+	switch value := value.(type) {
+	case time.Time:
+		year, month, day := value.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC), nil
+	case []byte:
+		s = string(value)
+	case string:
+		s = value
+	default:
+		return time.Time{}, ErrInvalidColumnTypeConversion
+	}
+	if len(s) > 10 && s[10] != ' ' && s[10] != 'T' {
+		return time.Time{}, ErrInvalidColumnTypeConversion
+	}
+	if len(s) > 10 {
+		s = s[:10]
+	}
+	date, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, ErrInvalidColumnTypeConversion
+	}
+	return date, nil
+}
+
+// Copy every column value of this row into dest, keyed by column name
+func (rs *Rows) MapScan(dest map[string]interface{}) error {
+	rs.lock()
+	defer rs.unlock()
+	if err := rs.Err(); err != nil {
+		return err
+	}
+	if rs.values == nil {
+		return ErrNoRows
+	}
+	for i, column := range rs.columns {
+		dest[column] = rs.values[i]
+	}
+	return nil
+}
+
+// Get a copy of the values of this row keyed by column name, including copies of the []byte
+// values, so it can be kept and modified freely. It is nil before the first row, and once
+// the rows failed.
+func (rs *Rows) Values() map[string]interface{} {
+	rs.lock()
+	defer rs.unlock()
+	if rs.values == nil || rs.Err() != nil {
+		return nil
+	}
+	values := make(map[string]interface{}, len(rs.columns))
+	for i, column := range rs.columns {
+		value := rs.values[i]
+		if b, ok := value.([]byte); ok {
+			value = append([]byte{}, b...)
+		}
+		values[column] = value
+	}
+	return values
+}
+
+// Check whether the value in this row is NULL by column name
+func (rs *Rows) IsNull(column string) (bool, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return false, err
+	}
+	return v == nil, nil
+}
+
+// Get the value in this row by column name as the driver returned it, for conversions the
+// typed getters don't cover. A []byte value is shared with the row and must not be modified.
+func (rs *Rows) GetRaw(column string) (interface{}, error) {
+	return rs.value(column)
+}
+
+// Get the boolean value in this row by column name
+func (rs *Rows) GetBoolean(column string) (bool, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return false, err
+	}
+	value, err := convert(rs, v, convertToBool)
+	if err != nil {
+		return false, err
+	}
+	return value, nil
+}
+
+// Get the integer  value in this row by column name
+func (rs *Rows) GetInteger(column string) (int64, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return 0, err
+	}
+	value, err := convert(rs, v, convertToInt)
+	if err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+// Get the float value in this row by column name
+func (rs *Rows) GetDouble(column string) (float64, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return 0, err
+	}
+	value, err := convert(rs, v, convertToDouble)
+	if err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+// Get the string value in this row by column name
+func (rs *Rows) GetString(column string) (string, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return "", err
+	}
+	value, err := convert(rs, v, convertToString)
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// Get a copy of the binary value in this row by column name, nil when NULL
+func (rs *Rows) GetBytes(column string) ([]byte, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return nil, err
+	}
+	return convertToBytes(v)
+}
+
+// Decode the JSON value in this row by column name into dest, a NULL decodes as JSON null
+func (rs *Rows) GetJSON(column string, dest interface{}) error {
+	v, err := rs.value(column)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	switch value := v.(type) {
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	case nil:
+		data = []byte("null")
+	default:
+		return ErrInvalidColumnTypeConversion
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// Get the UUID value in this row by column name, from its text or 16 byte binary form
+func (rs *Rows) GetUUID(column string) (UUID, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return UUID{}, err
+	}
+	return convert(rs, v, convertToUUID)
+}
+
+// Get the exact numeric value in this row by column name, as the text the database sent
+func (rs *Rows) GetDecimal(column string) (string, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return "", err
+	}
+	return convert(rs, v, convertToDecimal)
+}
+
+// Get the exact numeric value in this row by column name as a rational number
+func (rs *Rows) GetRat(column string) (*big.Rat, error) {
+	value, err := rs.GetDecimal(column)
+	if err != nil {
+		return nil, err
+	}
+	rat, _ := new(big.Rat).SetString(value)
+	return rat, nil
+}
+
+// Get the duration value in this row by column name, from a postgres interval or a number
+// in the unit set by WithDurationUnit
+func (rs *Rows) GetDuration(column string) (time.Duration, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return 0, err
+	}
+	return convert(rs, v, func(value interface{}) (time.Duration, error) {
+		return convertToDuration(value, rs.durationUnit())
+	})
+}
+
+// Get the date value in this row by column name, as midnight UTC of that day
+func (rs *Rows) GetDate(column string) (time.Time, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return convert(rs, v, convertToDate)
+}
+
+// Get the string array value in this row by column name, nil when NULL
+func (rs *Rows) GetStringSlice(column string) ([]string, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return nil, err
+	}
+	return convertToStringSlice(v)
+}
+
+// Get the int64 array value in this row by column name, nil when NULL
+func (rs *Rows) GetInt64Slice(column string) ([]int64, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return nil, err
+	}
+	return convertToInt64Slice(v)
+}
+
+// Get the float64 array value in this row by column name, nil when NULL
+func (rs *Rows) GetFloat64Slice(column string) ([]float64, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return nil, err
+	}
+	return convertToFloat64Slice(v)
+}
+
+// Get the time.Time value in this row by column name
+func (rs *Rows) GetTime(column string) (time.Time, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return time.Time{}, err
+	}
+	value, err := convert(rs, v, rs.convertToTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return value, nil
+}
+
+// Get the value of the column at index i of this row, the first column being 0
+func validateIndex(rs *Rows, i int) (interface{}, error) {
+	rs.lock()
+	defer rs.unlock()
+	if err := rs.Err(); err != nil {
+		return nil, err
+	}
+	if rs.values == nil {
+		return nil, ErrNoRows
 	}
-	return true
+	if i < 0 || i >= len(rs.columns) {
+		return nil, ErrColumnNotFound
+	}
+	return rs.values[i], nil
 }
 
-func validateRows(rs *Rows, column string) error {
-	if err := rs.Err(); err != nil {
-		return err
+// Get the value in this row by column index as the driver returned it, see GetRaw
+func (rs *Rows) GetRawAt(i int) (interface{}, error) {
+	return validateIndex(rs, i)
+}
+
+// Check whether the value in this row is NULL by column index
+func (rs *Rows) IsNullAt(i int) (bool, error) {
+	value, err := validateIndex(rs, i)
+	if err != nil {
+		return false, err
 	}
-	if rs.values == nil {
-		return ErrNoRows
+	return value == nil, nil
+}
+
+// Get the boolean value in this row by column index
+func (rs *Rows) GetBooleanAt(i int) (bool, error) {
+	value, err := validateIndex(rs, i)
+	if err != nil {
+		return false, err
 	}
-	if _, ok := rs.values[column]; !ok {
-		return ErrColumnNotFound
+	return convert(rs, value, convertToBool)
+}
+
+// Get the integer value in this row by column index
+func (rs *Rows) GetIntegerAt(i int) (int64, error) {
+	value, err := validateIndex(rs, i)
+	if err != nil {
+		return 0, err
 	}
-	return nil
+	return convert(rs, value, convertToInt)
 }
 
-func convertToInt(value interface{}) (int64, error) {
-	switch value.(type) {
-	case int, int8, int16, int32, int64:
-		return reflect.ValueOf(value).Int(), nil
+// Get the double value in this row by column index
+func (rs *Rows) GetDoubleAt(i int) (float64, error) {
+	value, err := validateIndex(rs, i)
+	if err != nil {
+		return 0, err
 	}
-	return 0, ErrInvalidColumnTypeConversion
+	return convert(rs, value, convertToDouble)
 }
 
-func convertToDouble(value interface{}) (float64, error) {
-	switch value.(type) {
-	case float32, float64:
-		return reflect.ValueOf(value).Float(), nil
+// Get the string value in this row by column index
+func (rs *Rows) GetStringAt(i int) (string, error) {
+	value, err := validateIndex(rs, i)
+	if err != nil {
+		return "", err
 	}
-	return 0, ErrInvalidColumnTypeConversion
+	return convert(rs, value, convertToString)
 }
 
-func convertToString(value interface{}) (string, error) {
-	switch value.(type) {
-	case string:
-		return value.(string), nil
+// Get the time.Time value in this row by column index
+func (rs *Rows) GetTimeAt(i int) (time.Time, error) {
+	value, err := validateIndex(rs, i)
+	if err != nil {
+		return time.Time{}, err
 	}
-	return "", ErrInvalidColumnTypeConversion
+	return convert(rs, value, rs.convertToTime)
 }
 
-func convertToTime(value interface{}) (time.Time, error) {
-	switch value.(type) {
-	case time.Time:
-		return value.(time.Time), nil
+// Get the time.Time value in this row by column name, in the location loc
+func (rs *Rows) GetTimeIn(column string, loc *time.Location) (time.Time, error) {
+	value, err := rs.GetTime(column)
+	if err != nil {
+		return time.Time{}, err
 	}
-	return time.Time{}, ErrInvalidColumnTypeConversion
+	return value.In(loc), nil
 }
 
-// Get the boolean value in this row by column name
-func (rs *Rows) GetBoolean(column string) (bool, error) {
-	value, ok := rs.values[column].(bool)
-	if !ok {
-		return false, ErrInvalidColumnTypeConversion
+// Get the nullable boolean value in this row by column name
+func (rs *Rows) GetNullBoolean(column string) (sql.NullBool, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return sql.NullBool{}, err
 	}
-	return value, nil
+	if v == nil {
+		return sql.NullBool{}, nil
+	}
+	value, err := convert(rs, v, convertToBool)
+	if err != nil {
+		return sql.NullBool{}, err
+	}
+	return sql.NullBool{Bool: value, Valid: true}, nil
 }
 
-// Get the integer  value in this row by column name
-func (rs *Rows) GetInteger(column string) (int64, error) {
-	if err := validateRows(rs, column); err != nil {
-		return 0, err
+// Get the nullable integer value in this row by column name
+func (rs *Rows) GetNullInteger(column string) (sql.NullInt64, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return sql.NullInt64{}, err
+	}
+	if v == nil {
+		return sql.NullInt64{}, nil
 	}
-	value, err := convertToInt(rs.values[column])
+	value, err := convert(rs, v, convertToInt)
 	if err != nil {
-		return 0, err
+		return sql.NullInt64{}, err
 	}
-	return value, nil
+	return sql.NullInt64{Int64: value, Valid: true}, nil
 }
 
-// Get the float value in this row by column name
-func (rs *Rows) GetDouble(column string) (float64, error) {
-	if err := validateRows(rs, column); err != nil {
-		return 0, err
+// Get the nullable float value in this row by column name
+func (rs *Rows) GetNullDouble(column string) (sql.NullFloat64, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return sql.NullFloat64{}, err
 	}
-	value, err := convertToDouble(rs.values[column])
+	if v == nil {
+		return sql.NullFloat64{}, nil
+	}
+	value, err := convert(rs, v, convertToDouble)
 	if err != nil {
-		return 0, err
+		return sql.NullFloat64{}, err
 	}
-	return value, nil
+	return sql.NullFloat64{Float64: value, Valid: true}, nil
 }
 
-// Get the string value in this row by column name
-func (rs *Rows) GetString(column string) (string, error) {
-	if err := validateRows(rs, column); err != nil {
-		return "", err
+// Get the nullable string value in this row by column name
+func (rs *Rows) GetNullString(column string) (sql.NullString, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	if v == nil {
+		return sql.NullString{}, nil
 	}
-	value, err := convertToString(rs.values[column])
+	value, err := convert(rs, v, convertToString)
 	if err != nil {
-		return "", err
+		return sql.NullString{}, err
 	}
-	return value, nil
+	return sql.NullString{String: value, Valid: true}, nil
 }
 
-// Get the time.Time value in this row by column name
-func (rs *Rows) GetTime(column string) (time.Time, error) {
-	if err := validateRows(rs, column); err != nil {
-		return time.Time{}, err
+// Get the nullable time.Time value in this row by column name
+func (rs *Rows) GetNullTime(column string) (sql.NullTime, error) {
+	v, err := rs.value(column)
+	if err != nil {
+		return sql.NullTime{}, err
 	}
-	value, err := convertToTime(rs.values[column])
+	if v == nil {
+		return sql.NullTime{}, nil
+	}
+	value, err := rs.convertToTime(v)
 	if err != nil {
-		return time.Time{}, err
+		return sql.NullTime{}, err
 	}
-	return value, nil
+	return sql.NullTime{Time: value, Valid: true}, nil
 }
 
 type Row struct {
@@ -318,10 +1314,15 @@ func (r *Row) Scan(dest ...interface{}) error {
 }
 
 func next(r *Row) error {
+	if r.err != nil {
+		return r.err
+	}
 	if r.next {
 		return nil
 	}
 	r.next = true
+	// the getters of the row read the values once the rows are closed
+	r.rows.Retain()
 	defer r.rows.Close()
 	if !r.rows.Next() {
 		if err := r.rows.Err(); err != nil {
@@ -335,6 +1336,30 @@ func next(r *Row) error {
 	return nil
 }
 
+// Copy every column value of this row into dest, keyed by column name
+func (r *Row) MapScan(dest map[string]interface{}) error {
+	if err := next(r); err != nil {
+		return err
+	}
+	return r.rows.MapScan(dest)
+}
+
+// Check whether the value in this row is NULL by column name
+func (r *Row) IsNull(column string) (bool, error) {
+	if err := next(r); err != nil {
+		return false, err
+	}
+	return r.rows.IsNull(column)
+}
+
+// Get the value in this row by column name as the driver returned it, see Rows.GetRaw
+func (r *Row) GetRaw(column string) (interface{}, error) {
+	if err := next(r); err != nil {
+		return nil, err
+	}
+	return r.rows.GetRaw(column)
+}
+
 // Get the boolean value in this row by column name
 func (r *Row) GetBoolean(column string) (bool, error) {
 	if err := next(r); err != nil {
@@ -367,6 +1392,86 @@ func (r *Row) GetString(column string) (string, error) {
 	return r.rows.GetString(column)
 }
 
+// Get a copy of the binary value in this row by column name, nil when NULL
+func (r *Row) GetBytes(column string) ([]byte, error) {
+	if err := next(r); err != nil {
+		return nil, err
+	}
+	return r.rows.GetBytes(column)
+}
+
+// Decode the JSON value in this row by column name into dest, see Rows.GetJSON
+func (r *Row) GetJSON(column string, dest interface{}) error {
+	if err := next(r); err != nil {
+		return err
+	}
+	return r.rows.GetJSON(column, dest)
+}
+
+// Get the UUID value in this row by column name
+func (r *Row) GetUUID(column string) (UUID, error) {
+	if err := next(r); err != nil {
+		return UUID{}, err
+	}
+	return r.rows.GetUUID(column)
+}
+
+// Get the exact numeric value in this row by column name, see Rows.GetDecimal
+func (r *Row) GetDecimal(column string) (string, error) {
+	if err := next(r); err != nil {
+		return "", err
+	}
+	return r.rows.GetDecimal(column)
+}
+
+// Get the exact numeric value in this row by column name as a rational number
+func (r *Row) GetRat(column string) (*big.Rat, error) {
+	if err := next(r); err != nil {
+		return nil, err
+	}
+	return r.rows.GetRat(column)
+}
+
+// Get the duration value in this row by column name, see Rows.GetDuration
+func (r *Row) GetDuration(column string) (time.Duration, error) {
+	if err := next(r); err != nil {
+		return 0, err
+	}
+	return r.rows.GetDuration(column)
+}
+
+// Get the date value in this row by column name, as midnight UTC of that day
+func (r *Row) GetDate(column string) (time.Time, error) {
+	if err := next(r); err != nil {
+		return time.Time{}, err
+	}
+	return r.rows.GetDate(column)
+}
+
+// Get the string array value in this row by column name, nil when NULL
+func (r *Row) GetStringSlice(column string) ([]string, error) {
+	if err := next(r); err != nil {
+		return nil, err
+	}
+	return r.rows.GetStringSlice(column)
+}
+
+// Get the int64 array value in this row by column name, nil when NULL
+func (r *Row) GetInt64Slice(column string) ([]int64, error) {
+	if err := next(r); err != nil {
+		return nil, err
+	}
+	return r.rows.GetInt64Slice(column)
+}
+
+// Get the float64 array value in this row by column name, nil when NULL
+func (r *Row) GetFloat64Slice(column string) ([]float64, error) {
+	if err := next(r); err != nil {
+		return nil, err
+	}
+	return r.rows.GetFloat64Slice(column)
+}
+
 // Get the time.Time value in this row by column name
 func (r *Row) GetTime(column string) (time.Time, error) {
 	if err := next(r); err != nil {
@@ -375,48 +1480,271 @@ func (r *Row) GetTime(column string) (time.Time, error) {
 	return r.rows.GetTime(column)
 }
 
+// Get the time.Time value in this row by column name, in the location loc
+func (r *Row) GetTimeIn(column string, loc *time.Location) (time.Time, error) {
+	if err := next(r); err != nil {
+		return time.Time{}, err
+	}
+	return r.rows.GetTimeIn(column, loc)
+}
+
+// Get the nullable boolean value in this row by column name
+func (r *Row) GetNullBoolean(column string) (sql.NullBool, error) {
+	if err := next(r); err != nil {
+		return sql.NullBool{}, err
+	}
+	return r.rows.GetNullBoolean(column)
+}
+
+// Get the nullable integer value in this row by column name
+func (r *Row) GetNullInteger(column string) (sql.NullInt64, error) {
+	if err := next(r); err != nil {
+		return sql.NullInt64{}, err
+	}
+	return r.rows.GetNullInteger(column)
+}
+
+// Get the nullable float value in this row by column name
+func (r *Row) GetNullDouble(column string) (sql.NullFloat64, error) {
+	if err := next(r); err != nil {
+		return sql.NullFloat64{}, err
+	}
+	return r.rows.GetNullDouble(column)
+}
+
+// Get the nullable string value in this row by column name
+func (r *Row) GetNullString(column string) (sql.NullString, error) {
+	if err := next(r); err != nil {
+		return sql.NullString{}, err
+	}
+	return r.rows.GetNullString(column)
+}
+
+// Get the nullable time.Time value in this row by column name
+func (r *Row) GetNullTime(column string) (sql.NullTime, error) {
+	if err := next(r); err != nil {
+		return sql.NullTime{}, err
+	}
+	return r.rows.GetNullTime(column)
+}
+
+// Check whether the value in this row is NULL by column index
+func (r *Row) IsNullAt(i int) (bool, error) {
+	if err := next(r); err != nil {
+		return false, err
+	}
+	return r.rows.IsNullAt(i)
+}
+
+// Get the boolean value in this row by column index
+func (r *Row) GetBooleanAt(i int) (bool, error) {
+	if err := next(r); err != nil {
+		return false, err
+	}
+	return r.rows.GetBooleanAt(i)
+}
+
+// Get the integer value in this row by column index
+func (r *Row) GetIntegerAt(i int) (int64, error) {
+	if err := next(r); err != nil {
+		return 0, err
+	}
+	return r.rows.GetIntegerAt(i)
+}
+
+// Get the double value in this row by column index
+func (r *Row) GetDoubleAt(i int) (float64, error) {
+	if err := next(r); err != nil {
+		return 0, err
+	}
+	return r.rows.GetDoubleAt(i)
+}
+
+// Get the string value in this row by column index
+func (r *Row) GetStringAt(i int) (string, error) {
+	if err := next(r); err != nil {
+		return "", err
+	}
+	return r.rows.GetStringAt(i)
+}
+
+// Get the time.Time value in this row by column index
+func (r *Row) GetTimeAt(i int) (time.Time, error) {
+	if err := next(r); err != nil {
+		return time.Time{}, err
+	}
+	return r.rows.GetTimeAt(i)
+}
+
+// Get the value in this row by column index as the driver returned it, see Rows.GetRaw
+func (r *Row) GetRawAt(i int) (interface{}, error) {
+	if err := next(r); err != nil {
+		return nil, err
+	}
+	return r.rows.GetRawAt(i)
+}
+
 type Stmt struct {
 	*sql.Stmt
+	db    *DB
+	query string
+	// limit and circuit breaker of the database, nil for the statements of a transaction
+	limit   *limiter
+	breaker *breaker
+	// prepared for a transaction, which runs to its end when the database shuts down
+	tx bool
+	// columns of the results, kept from one execution to the next
+	columns atomic.Pointer[columnCache]
 }
 
 func (s *Stmt) Query(args ...interface{}) (*Rows, error) {
-	rows, err := s.Stmt.Query(args...)
+	return s.QueryContext(context.Background(), args...)
+}
+
+func (s *Stmt) QueryContext(ctx context.Context, args ...interface{}) (*Rows, error) {
+	if !s.tx && s.db.isDraining() {
+		return nil, ErrShuttingDown
+	}
+	record, err := s.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+	release, err := s.limit.acquire(ctx)
+	if err != nil {
+		record(errNotRun)
+		return nil, err
+	}
+	ctx, event, err := s.db.beforeQuery(ctx, OpQuery, s.query, args)
+	if err != nil {
+		record(errNotRun)
+		release()
+		return nil, err
+	}
+	start := time.Now()
+	rows, err := s.Stmt.QueryContext(ctx, args...)
+	record(err)
+	s.db.afterQuery(ctx, event, nil, err)
 	if err != nil {
+		release()
+		return nil, s.db.mapError(err)
+	}
+	rs := s.db.newRows(rows, start)
+	rs.release = release
+	rs.cache = &s.columns
+	return rs, nil
+}
+
+func (s *Stmt) Exec(args ...interface{}) (sql.Result, error) {
+	return s.ExecContext(context.Background(), args...)
+}
+
+func (s *Stmt) ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error) {
+	if !s.tx && s.db.isDraining() {
+		return nil, ErrShuttingDown
+	}
+	record, err := s.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+	release, err := s.limit.acquire(ctx)
+	if err != nil {
+		record(errNotRun)
+		return nil, err
+	}
+	defer release()
+	ctx, event, err := s.db.beforeQuery(ctx, OpExec, s.query, args)
+	if err != nil {
+		record(errNotRun)
 		return nil, err
 	}
-	return &Rows{Rows: rows}, nil
+	result, err := s.Stmt.ExecContext(ctx, args...)
+	record(err)
+	s.db.afterQuery(ctx, event, result, err)
+	return result, s.db.mapError(err)
 }
 
 func (s *Stmt) QueryRow(args ...interface{}) *Row {
-	rows, err := s.Query(args...)
+	return s.QueryRowContext(context.Background(), args...)
+}
+
+func (s *Stmt) QueryRowContext(ctx context.Context, args ...interface{}) *Row {
+	rows, err := s.QueryContext(ctx, args...)
 	return &Row{rows: rows, err: err}
 }
 
 type Tx struct {
 	*sql.Tx
+	db *DB
+	// context of the transaction, used by the methods without a context argument
+	ctx   context.Context
+	event *QueryEvent
+	// set on pseudo-transactions backed by a savepoint of the outer transaction
+	root      *Tx
+	savepoint string
+	done      bool
+	// number of savepoints created by Begin, used to name them uniquely
+	savepoints int
+	// gives back the slot of WithConcurrencyLimit once the transaction ends
+	release func()
 }
 
 func (tx *Tx) Prepare(query string) (*Stmt, error) {
-	stmt, err := tx.Tx.Prepare(query)
+	return tx.PrepareContext(tx.context(), query)
+}
+
+func (tx *Tx) PrepareContext(ctx context.Context, query string) (*Stmt, error) {
+	stmt, err := tx.Tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &Stmt{Stmt: stmt, db: tx.db, query: query, tx: true}, nil
+}
+
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.ExecContext(tx.context(), query, args...)
+}
+
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, event, err := tx.db.beforeQuery(ctx, OpExec, query, args)
 	if err != nil {
 		return nil, err
 	}
-	return &Stmt{stmt}, nil
+	result, err := tx.Tx.ExecContext(ctx, tx.db.annotate(ctx, query), args...)
+	tx.db.afterQuery(ctx, event, result, err)
+	return result, tx.db.mapError(err)
 }
 
 func (tx *Tx) Query(query string, args ...interface{}) (*Rows, error) {
-	rows, err := tx.Tx.Query(query, args...)
+	return tx.QueryContext(tx.context(), query, args...)
+}
+
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	ctx, event, err := tx.db.beforeQuery(ctx, OpQuery, query, args)
 	if err != nil {
 		return nil, err
 	}
-	return &Rows{Rows: rows}, nil
+	start := time.Now()
+	rows, err := tx.Tx.QueryContext(ctx, tx.db.annotate(ctx, query), args...)
+	tx.db.afterQuery(ctx, event, nil, err)
+	if err != nil {
+		return nil, tx.db.mapError(err)
+	}
+	return tx.db.newRows(rows, start), nil
 }
 
 func (tx *Tx) QueryRow(query string, args ...interface{}) *Row {
-	rows, err := tx.Query(query, args...)
+	return tx.QueryRowContext(tx.context(), query, args...)
+}
+
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	rows, err := tx.QueryContext(ctx, query, args...)
 	return &Row{rows: rows, err: err}
 }
 
 func (tx *Tx) Stmt(stmt *Stmt) *Stmt {
-	return &Stmt{tx.Tx.Stmt(stmt.Stmt)}
+	return tx.StmtContext(tx.context(), stmt)
+}
+
+func (tx *Tx) StmtContext(ctx context.Context, stmt *Stmt) *Stmt {
+	return &Stmt{Stmt: tx.Tx.StmtContext(ctx, stmt.Stmt), db: tx.db, query: stmt.query, tx: true}
 }
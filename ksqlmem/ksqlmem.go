@@ -0,0 +1,206 @@
+// In-memory database driver for deterministic unit tests of code built on ksql, registered
+// as "ksqlmem":
+//
+//	import _ "github.com/kahoon/ksql/ksqlmem"
+//
+//	db, err := ksql.New("test", "ksqlmem", "people")
+//
+// Connections with the same data source name share the same tables until Reset. Only a
+// small subset of SQL is understood, enough to exercise the getters and scanning:
+//
+//	CREATE TABLE [IF NOT EXISTS] t (column type, ...)
+//	DROP TABLE [IF EXISTS] t
+//	INSERT INTO t [(column, ...)] VALUES (value, ...), ...
+//	SELECT * | COUNT(*) | column [AS alias], ... FROM t [WHERE ...] [ORDER BY column [ASC|DESC], ...] [LIMIT n [OFFSET n]]
+//	UPDATE t SET column = value, ... [WHERE ...]
+//	DELETE FROM t [WHERE ...]
+//
+// where conditions compare columns to values with =, <>, !=, <, <=, >, >=, IS NULL and
+// IS NOT NULL, joined by AND. Values are literals or ? and $1 placeholders. Column types are
+// mapped to integers, reals, booleans, text, times and bytes by name, and constraints are
+// ignored. Transactions are rolled back by restoring the tables as they were at their start,
+// without any isolation.
+package ksqlmem
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+)
+
+// Name the driver is registered as
+const DriverName = "ksqlmem"
+
+func init() {
+	sql.Register(DriverName, &memDriver{})
+}
+
+// Databases by data source name
+var (
+	databasesMu sync.Mutex
+	databases   = make(map[string]*database)
+)
+
+// Drop every table of the database of the data source name
+func Reset(dsn string) {
+	databasesMu.Lock()
+	db, ok := databases[dsn]
+	databasesMu.Unlock()
+	if ok {
+		// open connections keep the database, so its tables are dropped in place
+		db.mu.Lock()
+		db.tables = make(map[string]*table)
+		db.mu.Unlock()
+	}
+}
+
+func open(dsn string) *database {
+	databasesMu.Lock()
+	defer databasesMu.Unlock()
+	db, ok := databases[dsn]
+	if !ok {
+		db = &database{tables: make(map[string]*table)}
+		databases[dsn] = db
+	}
+	return db
+}
+
+type database struct {
+	mu     sync.Mutex
+	tables map[string]*table
+}
+
+// Copy the tables, to restore them on rollback
+func (db *database) snapshot() map[string]*table {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	tables := make(map[string]*table, len(db.tables))
+	for name, t := range db.tables {
+		c := *t
+		c.rows = make([][]driver.Value, len(t.rows))
+		for i, row := range t.rows {
+			c.rows[i] = append([]driver.Value(nil), row...)
+		}
+		tables[name] = &c
+	}
+	return tables
+}
+
+type memDriver struct{}
+
+func (*memDriver) Open(dsn string) (driver.Conn, error) {
+	return &conn{db: open(dsn)}, nil
+}
+
+type conn struct {
+	db *database
+	// tables at the start of the transaction in progress
+	saved map[string]*table
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	res, err := c.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return result{lastInsertID: res.lastInsertID, rowsAffected: res.affected}, nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	res, err := c.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{columns: res.columns, values: res.rows}, nil
+}
+
+func (c *conn) run(query string, args []driver.NamedValue) (*outcome, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	stmt, err := parse(query, values)
+	if err != nil {
+		return nil, err
+	}
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	return stmt.run(c.db)
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	c.saved = c.db.snapshot()
+	return c, nil
+}
+
+func (c *conn) Commit() error {
+	c.saved = nil
+	return nil
+}
+
+func (c *conn) Rollback() error {
+	if c.saved != nil {
+		c.db.mu.Lock()
+		c.db.tables = c.saved
+		c.db.mu.Unlock()
+		c.saved = nil
+	}
+	return nil
+}
+
+func (c *conn) Close() error { return nil }
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	values := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		values[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return values
+}
+
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r result) LastInsertId() (int64, error) { return r.lastInsertID, nil }
+func (r result) RowsAffected() (int64, error) { return r.rowsAffected, nil }
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
@@ -0,0 +1,184 @@
+package ksqlmem
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kahoon/ksql"
+)
+
+func openMem(t *testing.T, dsn string) *ksql.DB {
+	t.Helper()
+	Reset(dsn)
+	db, err := ksql.New("mem", DriverName, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		Reset(dsn)
+	})
+	mustExec(t, db, `CREATE TABLE people (
+		id INTEGER PRIMARY KEY,
+		name VARCHAR(100) NOT NULL,
+		married BOOLEAN DEFAULT FALSE,
+		height DOUBLE PRECISION,
+		born TIMESTAMP,
+		photo BLOB
+	)`)
+	return db
+}
+
+func mustExec(t *testing.T, db *ksql.DB, query string, args ...interface{}) int64 {
+	t.Helper()
+	res, err := db.Exec(query, args...)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	n, _ := res.RowsAffected()
+	return n
+}
+
+func TestGetters(t *testing.T) {
+	db := openMem(t, "getters")
+	day := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	mustExec(t, db, "INSERT INTO people (id, name, married, height, born, photo) VALUES (?, ?, ?, ?, ?, ?)",
+		1, "john doe", true, 1.82, day, []byte{1, 2})
+	mustExec(t, db, "INSERT INTO people (id, name) VALUES (2, 'jane o''hara')")
+
+	row := db.QueryRow("SELECT * FROM people WHERE id = $1", 1)
+	if name, err := row.GetString("name"); err != nil || name != "john doe" {
+		t.Errorf("expected john doe, got %q %v", name, err)
+	}
+	if married, err := row.GetBoolean("married"); err != nil || !married {
+		t.Errorf("expected married, got %v %v", married, err)
+	}
+	if height, err := row.GetDouble("height"); err != nil || height != 1.82 {
+		t.Errorf("expected the height, got %v %v", height, err)
+	}
+	if born, err := row.GetTime("born"); err != nil || !born.Equal(day) {
+		t.Errorf("expected the birth day, got %v %v", born, err)
+	}
+	if photo, err := row.GetBytes("photo"); err != nil || len(photo) != 2 {
+		t.Errorf("expected the photo, got %v %v", photo, err)
+	}
+
+	row = db.QueryRow("SELECT name AS full_name, height FROM people WHERE id = 2")
+	if name, err := row.GetString("full_name"); err != nil || name != "jane o'hara" {
+		t.Errorf("expected the alias, got %q %v", name, err)
+	}
+	if null, err := row.IsNull("height"); err != nil || !null {
+		t.Errorf("expected a null height, got %v %v", null, err)
+	}
+	if _, err := db.QueryRow("SELECT * FROM people WHERE id = 3").GetString("name"); err != ksql.ErrNoRows {
+		t.Errorf("expected no rows, got %v", err)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	db := openMem(t, "select")
+	mustExec(t, db, "INSERT INTO people (id, name, height) VALUES (1, 'carol', 1.6), (2, 'alice', 1.7), (3, 'bob', NULL), (4, 'dave', 1.9)")
+
+	rows, err := db.Query("SELECT id FROM people WHERE height IS NOT NULL AND height >= ? ORDER BY name DESC LIMIT 2 OFFSET 1", 1.65)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for rows.Next() {
+		id, err := rows.GetInteger("id")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("expected alice after dave, got %v", ids)
+	}
+
+	count, err := db.QueryRow("SELECT COUNT(*) FROM people WHERE height <> 1.6").GetInteger("count")
+	if err != nil || count != 2 {
+		t.Errorf("expected 2 rows without nulls, got %v %v", count, err)
+	}
+	if _, err := db.Query("SELECT missing FROM people"); err == nil {
+		t.Errorf("expected an unknown column error")
+	}
+	if _, err := db.Query("SELECT * FROM nobody"); err == nil {
+		t.Errorf("expected an unknown table error")
+	}
+	if _, err := db.Query("SELECT * FROM people GROUP BY name"); err == nil {
+		t.Errorf("expected an unsupported statement error")
+	}
+}
+
+func TestUpdateDelete(t *testing.T) {
+	db := openMem(t, "update")
+	mustExec(t, db, "INSERT INTO people (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c')")
+	if n := mustExec(t, db, "UPDATE people SET married = ?, height = 1.5 WHERE id > 1", true); n != 2 {
+		t.Errorf("expected 2 updated rows, got %d", n)
+	}
+	if n := mustExec(t, db, "DELETE FROM people WHERE married = TRUE AND id <> 3"); n != 1 {
+		t.Errorf("expected 1 deleted row, got %d", n)
+	}
+	names, err := db.QueryMaps("SELECT name, married FROM people ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0]["name"] != "a" || names[1]["married"] != true {
+		t.Errorf("expected a and a married c, got %v", names)
+	}
+	if _, err := db.Exec("UPDATE people SET id = 'x'"); err == nil {
+		t.Errorf("expected a conversion error")
+	}
+	mustExec(t, db, "DROP TABLE people")
+	mustExec(t, db, "DROP TABLE IF EXISTS people")
+	if _, err := db.Exec("DROP TABLE people"); err == nil {
+		t.Errorf("expected an unknown table error")
+	}
+}
+
+func TestTransactions(t *testing.T) {
+	db := openMem(t, "tx")
+	fail := errors.New("fail")
+	err := db.WithTx(context.Background(), func(tx *ksql.Tx) error {
+		if _, err := tx.Exec("INSERT INTO people (id, name) VALUES (1, 'john')"); err != nil {
+			return err
+		}
+		return fail
+	})
+	if err != fail {
+		t.Fatalf("expected the failure, got %v", err)
+	}
+	err = db.WithTx(context.Background(), func(tx *ksql.Tx) error {
+		_, err := tx.Exec("INSERT INTO people (id, name) VALUES (2, 'jane')")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := db.QueryRow("SELECT COUNT(*) AS n FROM people").GetInteger("n")
+	if err != nil || count != 1 {
+		t.Errorf("expected only the committed row, got %v %v", count, err)
+	}
+}
+
+func TestReset(t *testing.T) {
+	db := openMem(t, "reset")
+	other, err := ksql.New("other", DriverName, "reset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	mustExec(t, db, "INSERT INTO people (id, name) VALUES (1, 'john')")
+	if name, err := other.QueryRow("SELECT name FROM people").GetString("name"); err != nil || name != "john" {
+		t.Errorf("expected the tables to be shared by data source name, got %q %v", name, err)
+	}
+	Reset("reset")
+	if _, err := other.Query("SELECT * FROM people"); err == nil {
+		t.Errorf("expected the tables to be dropped")
+	}
+}
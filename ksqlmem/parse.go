@@ -0,0 +1,891 @@
+package ksqlmem
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	tokIdent = iota
+	tokNumber
+	tokString
+	tokPunct
+	tokParam
+	tokEOF
+)
+
+type token struct {
+	kind  int
+	text  string
+	param int // 1 based index of a $1 placeholder, 0 for ?
+}
+
+// Split a statement into tokens, dropping comments
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ';':
+			i++
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case c == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; ; j++ {
+				if j >= len(query) {
+					return nil, errors.New("ksqlmem: unterminated string")
+				}
+				if query[j] == '\'' {
+					if j+1 < len(query) && query[j+1] == '\'' {
+						b.WriteByte('\'')
+						j++
+						continue
+					}
+					break
+				}
+				b.WriteByte(query[j])
+			}
+			tokens = append(tokens, token{kind: tokString, text: b.String()})
+			i = j + 1
+		case c == '"' || c == '`':
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				return nil, errors.New("ksqlmem: unterminated identifier")
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: query[i+1 : i+1+end]})
+			i += end + 2
+		case c == '?':
+			tokens = append(tokens, token{kind: tokParam})
+			i++
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			j := i + 1
+			for j < len(query) && isDigit(query[j]) {
+				j++
+			}
+			n, _ := strconv.Atoi(query[i+1 : j])
+			tokens = append(tokens, token{kind: tokParam, param: n})
+			i = j
+		case isDigit(c) || c == '.' && i+1 < len(query) && isDigit(query[i+1]):
+			j := i
+			for j < len(query) && (isDigit(query[j]) || query[j] == '.' || query[j] == 'e' || query[j] == 'E') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: query[i:j]})
+			i = j
+		case isIdent(c):
+			j := i
+			for j < len(query) && (isIdent(query[j]) || isDigit(query[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: query[i:j]})
+			i = j
+		case strings.HasPrefix(query[i:], "<>"), strings.HasPrefix(query[i:], "!="),
+			strings.HasPrefix(query[i:], "<="), strings.HasPrefix(query[i:], ">="):
+			tokens = append(tokens, token{kind: tokPunct, text: query[i : i+2]})
+			i += 2
+		case strings.IndexByte("(),*=<>-.", c) >= 0:
+			tokens = append(tokens, token{kind: tokPunct, text: string(c)})
+			i++
+		default:
+			return nil, fmt.Errorf("ksqlmem: unexpected character %q", c)
+		}
+	}
+	return append(tokens, token{kind: tokEOF}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	args   []driver.Value
+	// number of ? placeholders read so far
+	params int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// Consume the keyword when it comes next
+func (p *parser) keyword(words ...string) bool {
+	for i, word := range words {
+		t := p.tokens[min(p.pos+i, len(p.tokens)-1)]
+		if t.kind != tokIdent || !strings.EqualFold(t.text, word) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+// Consume the punctuation when it comes next
+func (p *parser) punct(text string) bool {
+	if t := p.peek(); t.kind == tokPunct && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(words ...string) error {
+	if !p.keyword(words...) {
+		return p.unexpected(strings.ToUpper(strings.Join(words, " ")))
+	}
+	return nil
+}
+
+func (p *parser) expectPunct(text string) error {
+	if !p.punct(text) {
+		return p.unexpected(text)
+	}
+	return nil
+}
+
+func (p *parser) unexpected(want string) error {
+	t := p.peek()
+	if t.kind == tokEOF {
+		return fmt.Errorf("ksqlmem: expected %s at the end of the statement", want)
+	}
+	return fmt.Errorf("ksqlmem: expected %s, got %q", want, t.text)
+}
+
+func (p *parser) ident() (string, error) {
+	t := p.peek()
+	if t.kind != tokIdent {
+		return "", p.unexpected("a name")
+	}
+	p.pos++
+	// a qualified name is read by its last part
+	for p.punct(".") {
+		t = p.peek()
+		if t.kind != tokIdent {
+			return "", p.unexpected("a name")
+		}
+		p.pos++
+	}
+	return t.text, nil
+}
+
+// Read a literal or placeholder value
+func (p *parser) value() (driver.Value, error) {
+	negative := p.punct("-")
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		text := t.text
+		if negative {
+			text = "-" + text
+		}
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ksqlmem: invalid number %s", text)
+		}
+		return f, nil
+	case tokString:
+		if !negative {
+			return t.text, nil
+		}
+	case tokParam:
+		if negative {
+			break
+		}
+		n := t.param
+		if n == 0 {
+			p.params++
+			n = p.params
+		}
+		if n > len(p.args) {
+			return nil, fmt.Errorf("ksqlmem: missing argument %d", n)
+		}
+		return p.args[n-1], nil
+	case tokIdent:
+		switch strings.ToLower(t.text) {
+		case "null":
+			return nil, nil
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	p.pos--
+	return nil, p.unexpected("a value")
+}
+
+// Parsed statement
+type statement interface {
+	run(db *database) (*outcome, error)
+}
+
+// Outcome of a statement
+type outcome struct {
+	columns      []string
+	rows         [][]driver.Value
+	affected     int64
+	lastInsertID int64
+}
+
+// Parse the statement, binding the arguments of its placeholders
+func parse(query string, args []driver.Value) (statement, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, args: args}
+	var stmt statement
+	switch {
+	case p.keyword("create", "table"):
+		stmt, err = p.create()
+	case p.keyword("drop", "table"):
+		stmt, err = p.drop()
+	case p.keyword("insert", "into"):
+		stmt, err = p.insert()
+	case p.keyword("select"):
+		stmt, err = p.selectFrom()
+	case p.keyword("update"):
+		stmt, err = p.update()
+	case p.keyword("delete", "from"):
+		stmt, err = p.deleteFrom()
+	default:
+		return nil, p.unexpected("CREATE TABLE, DROP TABLE, INSERT, SELECT, UPDATE or DELETE")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.unexpected("the end of the statement")
+	}
+	return stmt, nil
+}
+
+// Get the table by name
+func (db *database) table(name string) (*table, error) {
+	t, ok := db.tables[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("ksqlmem: no table %s", name)
+	}
+	return t, nil
+}
+
+type createStmt struct {
+	table       string
+	columns     []column
+	ifNotExists bool
+}
+
+func (p *parser) create() (statement, error) {
+	s := &createStmt{ifNotExists: p.keyword("if", "not", "exists")}
+	var err error
+	if s.table, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	for {
+		if p.keyword("primary", "key") || p.keyword("unique") || p.keyword("foreign", "key") ||
+			p.keyword("constraint") || p.keyword("check") {
+			// table constraints are ignored
+			p.skipDefinition()
+		} else {
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			typ := p.peek()
+			if typ.kind != tokIdent {
+				return nil, p.unexpected("a column type")
+			}
+			s.columns = append(s.columns, column{name: name, kind: kindOf(typ.text)})
+			p.skipDefinition()
+		}
+		if !p.punct(",") {
+			break
+		}
+	}
+	if err := p.expectPunct(")"); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Skip the rest of a column or constraint definition, up to the next comma or closing
+// parenthesis outside of nested parentheses
+func (p *parser) skipDefinition() {
+	depth := 0
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokEOF:
+			return
+		case t.kind == tokPunct && t.text == "(":
+			depth++
+		case t.kind == tokPunct && t.text == ")":
+			if depth == 0 {
+				return
+			}
+			depth--
+		case t.kind == tokPunct && t.text == "," && depth == 0:
+			return
+		}
+		p.pos++
+	}
+}
+
+func (s *createStmt) run(db *database) (*outcome, error) {
+	key := strings.ToLower(s.table)
+	if _, ok := db.tables[key]; ok {
+		if s.ifNotExists {
+			return &outcome{}, nil
+		}
+		return nil, fmt.Errorf("ksqlmem: table %s already exists", s.table)
+	}
+	db.tables[key] = &table{name: s.table, columns: s.columns}
+	return &outcome{}, nil
+}
+
+type dropStmt struct {
+	table    string
+	ifExists bool
+}
+
+func (p *parser) drop() (statement, error) {
+	s := &dropStmt{ifExists: p.keyword("if", "exists")}
+	var err error
+	s.table, err = p.ident()
+	return s, err
+}
+
+func (s *dropStmt) run(db *database) (*outcome, error) {
+	key := strings.ToLower(s.table)
+	if _, ok := db.tables[key]; !ok && !s.ifExists {
+		return nil, fmt.Errorf("ksqlmem: no table %s", s.table)
+	}
+	delete(db.tables, key)
+	return &outcome{}, nil
+}
+
+type insertStmt struct {
+	table   string
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (p *parser) insert() (statement, error) {
+	s := &insertStmt{}
+	var err error
+	if s.table, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if p.punct("(") {
+		for {
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			s.columns = append(s.columns, name)
+			if !p.punct(",") {
+				break
+			}
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectKeyword("values"); err != nil {
+		return nil, err
+	}
+	for {
+		if err := p.expectPunct("("); err != nil {
+			return nil, err
+		}
+		var row []driver.Value
+		for {
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, v)
+			if !p.punct(",") {
+				break
+			}
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+		s.rows = append(s.rows, row)
+		if !p.punct(",") {
+			break
+		}
+	}
+	return s, nil
+}
+
+func (s *insertStmt) run(db *database) (*outcome, error) {
+	t, err := db.table(s.table)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]int, len(t.columns))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	if s.columns != nil {
+		indexes = indexes[:0]
+		for _, name := range s.columns {
+			i, err := t.column(name)
+			if err != nil {
+				return nil, err
+			}
+			indexes = append(indexes, i)
+		}
+	}
+	// convert every row first, so a statement inserts all of its rows or none
+	rows := make([][]driver.Value, len(s.rows))
+	for r, values := range s.rows {
+		if len(values) != len(indexes) {
+			return nil, fmt.Errorf("ksqlmem: %d values for %d columns", len(values), len(indexes))
+		}
+		row := make([]driver.Value, len(t.columns))
+		for i, v := range values {
+			if row[indexes[i]], err = convert(t.columns[indexes[i]].kind, v); err != nil {
+				return nil, err
+			}
+		}
+		rows[r] = row
+	}
+	t.rows = append(t.rows, rows...)
+	t.lastID += int64(len(rows))
+	return &outcome{affected: int64(len(rows)), lastInsertID: t.lastID}, nil
+}
+
+// Condition of a WHERE clause
+type condition struct {
+	column string
+	op     string
+	value  driver.Value
+}
+
+func (p *parser) where() ([]condition, error) {
+	if !p.keyword("where") {
+		return nil, nil
+	}
+	var conditions []condition
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		c := condition{column: name}
+		switch {
+		case p.keyword("is", "not", "null"):
+			c.op = "is not null"
+		case p.keyword("is", "null"):
+			c.op = "is null"
+		default:
+			t := p.next()
+			if t.kind != tokPunct || strings.IndexByte("=<>!", t.text[0]) < 0 {
+				p.pos--
+				return nil, p.unexpected("a comparison")
+			}
+			c.op = t.text
+			if c.value, err = p.value(); err != nil {
+				return nil, err
+			}
+		}
+		conditions = append(conditions, c)
+		if !p.keyword("and") {
+			return conditions, nil
+		}
+	}
+}
+
+// Get the rows of the table matching every condition
+func (t *table) filter(conditions []condition) ([][]driver.Value, error) {
+	type bound struct {
+		index int
+		op    string
+		value driver.Value
+	}
+	bounds := make([]bound, len(conditions))
+	for i, c := range conditions {
+		index, err := t.column(c.column)
+		if err != nil {
+			return nil, err
+		}
+		value, err := convert(t.columns[index].kind, c.value)
+		if err != nil {
+			return nil, err
+		}
+		bounds[i] = bound{index: index, op: c.op, value: value}
+	}
+	var matches [][]driver.Value
+rows:
+	for _, row := range t.rows {
+		for _, b := range bounds {
+			v := row[b.index]
+			switch b.op {
+			case "is null":
+				if v != nil {
+					continue rows
+				}
+				continue
+			case "is not null":
+				if v == nil {
+					continue rows
+				}
+				continue
+			}
+			// comparisons with NULL are never true
+			if v == nil || b.value == nil {
+				continue rows
+			}
+			n, err := compare(v, b.value)
+			if err != nil {
+				return nil, err
+			}
+			var ok bool
+			switch b.op {
+			case "=":
+				ok = n == 0
+			case "<>", "!=":
+				ok = n != 0
+			case "<":
+				ok = n < 0
+			case "<=":
+				ok = n <= 0
+			case ">":
+				ok = n > 0
+			case ">=":
+				ok = n >= 0
+			}
+			if !ok {
+				continue rows
+			}
+		}
+		matches = append(matches, row)
+	}
+	return matches, nil
+}
+
+type order struct {
+	column string
+	desc   bool
+}
+
+type selectStmt struct {
+	table      string
+	all        bool
+	count      bool
+	columns    []string
+	aliases    []string
+	conditions []condition
+	orderBy    []order
+	limit      int64
+	offset     int64
+}
+
+func (p *parser) selectFrom() (statement, error) {
+	s := &selectStmt{limit: -1}
+	switch {
+	case p.punct("*"):
+		s.all = true
+	case p.keyword("count"):
+		if err := p.expectPunct("("); err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct("*"); err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+		s.count = true
+		s.aliases = []string{"count"}
+		if p.keyword("as") {
+			alias, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			s.aliases[0] = alias
+		}
+	default:
+		for {
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			alias := name
+			if p.keyword("as") {
+				if alias, err = p.ident(); err != nil {
+					return nil, err
+				}
+			}
+			s.columns = append(s.columns, name)
+			s.aliases = append(s.aliases, alias)
+			if !p.punct(",") {
+				break
+			}
+		}
+	}
+	if err := p.expectKeyword("from"); err != nil {
+		return nil, err
+	}
+	var err error
+	if s.table, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if s.conditions, err = p.where(); err != nil {
+		return nil, err
+	}
+	if p.keyword("order", "by") {
+		for {
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			o := order{column: name, desc: p.keyword("desc")}
+			if !o.desc {
+				p.keyword("asc")
+			}
+			s.orderBy = append(s.orderBy, o)
+			if !p.punct(",") {
+				break
+			}
+		}
+	}
+	if p.keyword("limit") {
+		if s.limit, err = p.count(); err != nil {
+			return nil, err
+		}
+		if p.keyword("offset") {
+			if s.offset, err = p.count(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// Read a non negative integer value
+func (p *parser) count() (int64, error) {
+	v, err := p.value()
+	if err != nil {
+		return 0, err
+	}
+	n, err := convert(kindInteger, v)
+	if err != nil || n == nil || n.(int64) < 0 {
+		return 0, fmt.Errorf("ksqlmem: invalid count %v", v)
+	}
+	return n.(int64), nil
+}
+
+func (s *selectStmt) run(db *database) (*outcome, error) {
+	t, err := db.table(s.table)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := t.filter(s.conditions)
+	if err != nil {
+		return nil, err
+	}
+	if s.count {
+		return &outcome{columns: s.aliases, rows: [][]driver.Value{{int64(len(matches))}}}, nil
+	}
+	if len(s.orderBy) > 0 {
+		indexes := make([]int, len(s.orderBy))
+		for i, o := range s.orderBy {
+			if indexes[i], err = t.column(o.column); err != nil {
+				return nil, err
+			}
+		}
+		sort.SliceStable(matches, func(a, b int) bool {
+			for i, o := range s.orderBy {
+				n, _ := compare(matches[a][indexes[i]], matches[b][indexes[i]])
+				if n != 0 {
+					return n < 0 != o.desc
+				}
+			}
+			return false
+		})
+	}
+	if s.offset > 0 {
+		matches = matches[min(s.offset, int64(len(matches))):]
+	}
+	if s.limit >= 0 && s.limit < int64(len(matches)) {
+		matches = matches[:s.limit]
+	}
+	out := &outcome{}
+	var indexes []int
+	if s.all {
+		for i, c := range t.columns {
+			out.columns = append(out.columns, c.name)
+			indexes = append(indexes, i)
+		}
+	} else {
+		out.columns = s.aliases
+		for _, name := range s.columns {
+			i, err := t.column(name)
+			if err != nil {
+				return nil, err
+			}
+			indexes = append(indexes, i)
+		}
+	}
+	for _, match := range matches {
+		row := make([]driver.Value, len(indexes))
+		for i, index := range indexes {
+			row[i] = copyValue(match[index])
+		}
+		out.rows = append(out.rows, row)
+	}
+	return out, nil
+}
+
+// Copy the bytes of a value, so the rows read can't change the table
+func copyValue(v driver.Value) driver.Value {
+	if b, ok := v.([]byte); ok {
+		return append([]byte(nil), b...)
+	}
+	return v
+}
+
+type assignment struct {
+	column string
+	value  driver.Value
+}
+
+type updateStmt struct {
+	table       string
+	assignments []assignment
+	conditions  []condition
+}
+
+func (p *parser) update() (statement, error) {
+	s := &updateStmt{}
+	var err error
+	if s.table, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("set"); err != nil {
+		return nil, err
+	}
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct("="); err != nil {
+			return nil, err
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		s.assignments = append(s.assignments, assignment{column: name, value: v})
+		if !p.punct(",") {
+			break
+		}
+	}
+	s.conditions, err = p.where()
+	return s, err
+}
+
+func (s *updateStmt) run(db *database) (*outcome, error) {
+	t, err := db.table(s.table)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]int, len(s.assignments))
+	values := make([]driver.Value, len(s.assignments))
+	for i, a := range s.assignments {
+		if indexes[i], err = t.column(a.column); err != nil {
+			return nil, err
+		}
+		if values[i], err = convert(t.columns[indexes[i]].kind, a.value); err != nil {
+			return nil, err
+		}
+	}
+	matches, err := t.filter(s.conditions)
+	if err != nil {
+		return nil, err
+	}
+	// the matching rows share their backing arrays with the table
+	for _, row := range matches {
+		for i, index := range indexes {
+			row[index] = copyValue(values[i])
+		}
+	}
+	return &outcome{affected: int64(len(matches))}, nil
+}
+
+type deleteStmt struct {
+	table      string
+	conditions []condition
+}
+
+func (p *parser) deleteFrom() (statement, error) {
+	s := &deleteStmt{}
+	var err error
+	if s.table, err = p.ident(); err != nil {
+		return nil, err
+	}
+	s.conditions, err = p.where()
+	return s, err
+}
+
+func (s *deleteStmt) run(db *database) (*outcome, error) {
+	t, err := db.table(s.table)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := t.filter(s.conditions)
+	if err != nil {
+		return nil, err
+	}
+	deleted := make(map[*driver.Value]bool, len(matches))
+	for _, row := range matches {
+		if len(row) > 0 {
+			deleted[&row[0]] = true
+		}
+	}
+	kept := t.rows[:0]
+	for _, row := range t.rows {
+		if len(row) == 0 || !deleted[&row[0]] {
+			kept = append(kept, row)
+		}
+	}
+	// clear the tail, so the deleted rows are not kept alive
+	for i := len(kept); i < len(t.rows); i++ {
+		t.rows[i] = nil
+	}
+	n := int64(len(t.rows) - len(kept))
+	t.rows = kept
+	return &outcome{affected: n}, nil
+}
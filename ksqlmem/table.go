@@ -0,0 +1,206 @@
+package ksqlmem
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type of the values of a column
+type kind int
+
+const (
+	kindText kind = iota
+	kindInteger
+	kindReal
+	kindBool
+	kindTime
+	kindBytes
+)
+
+// Map a column type name to the kind of its values, text when unknown
+func kindOf(typ string) kind {
+	typ = strings.ToLower(typ)
+	switch {
+	case strings.Contains(typ, "int"), strings.Contains(typ, "serial"):
+		return kindInteger
+	case strings.Contains(typ, "real"), strings.Contains(typ, "double"), strings.Contains(typ, "float"),
+		strings.Contains(typ, "numeric"), strings.Contains(typ, "decimal"):
+		return kindReal
+	case strings.HasPrefix(typ, "bool"):
+		return kindBool
+	case strings.Contains(typ, "time"), strings.Contains(typ, "date"):
+		return kindTime
+	case typ == "blob", typ == "bytea", strings.Contains(typ, "binary"):
+		return kindBytes
+	}
+	return kindText
+}
+
+type column struct {
+	name string
+	kind kind
+}
+
+type table struct {
+	name    string
+	columns []column
+	rows    [][]driver.Value
+	// id of the last row inserted, as returned by LastInsertId
+	lastID int64
+}
+
+// Get the index of the column, case insensitively
+func (t *table) column(name string) (int, error) {
+	for i, c := range t.columns {
+		if strings.EqualFold(c.name, name) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("ksqlmem: no column %s in table %s", name, t.name)
+}
+
+// Layouts of the times given as text
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999Z07:00", "2006-01-02 15:04:05.999999999", "2006-01-02"}
+
+// Convert a value to the kind of a column
+func convert(k kind, v driver.Value) (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if b, ok := v.([]byte); ok && k != kindBytes {
+		v = string(b)
+	}
+	switch k {
+	case kindInteger:
+		switch v := v.(type) {
+		case int64:
+			return v, nil
+		case float64:
+			if v == float64(int64(v)) {
+				return int64(v), nil
+			}
+		case bool:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case string:
+			if i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return i, nil
+			}
+		}
+	case kindReal:
+		switch v := v.(type) {
+		case float64:
+			return v, nil
+		case int64:
+			return float64(v), nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return f, nil
+			}
+		}
+	case kindBool:
+		switch v := v.(type) {
+		case bool:
+			return v, nil
+		case int64:
+			return v != 0, nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "t", "true", "1":
+				return true, nil
+			case "f", "false", "0":
+				return false, nil
+			}
+		}
+	case kindTime:
+		switch v := v.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			for _, layout := range timeLayouts {
+				if t, err := time.Parse(layout, v); err == nil {
+					return t, nil
+				}
+			}
+		}
+	case kindBytes:
+		switch v := v.(type) {
+		case []byte:
+			return append([]byte(nil), v...), nil
+		case string:
+			return []byte(v), nil
+		}
+	default:
+		switch v := v.(type) {
+		case string:
+			return v, nil
+		case time.Time:
+			return v.Format(time.RFC3339Nano), nil
+		default:
+			return fmt.Sprint(v), nil
+		}
+	}
+	return nil, fmt.Errorf("ksqlmem: invalid value %v for a column of type %s", v, k)
+}
+
+func (k kind) String() string {
+	return [...]string{"text", "integer", "real", "boolean", "time", "bytes"}[k]
+}
+
+var errIncomparable = errors.New("ksqlmem: values of different types can't be compared")
+
+// Compare two values of the same kind, nil first
+func compare(a, b driver.Value) (int, error) {
+	switch {
+	case a == nil && b == nil:
+		return 0, nil
+	case a == nil:
+		return -1, nil
+	case b == nil:
+		return 1, nil
+	}
+	switch a := a.(type) {
+	case int64:
+		if b, ok := b.(int64); ok {
+			return cmp(a < b, a > b), nil
+		}
+	case float64:
+		if b, ok := b.(float64); ok {
+			return cmp(a < b, a > b), nil
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), nil
+		}
+	case bool:
+		if b, ok := b.(bool); ok {
+			return cmp(!a && b, a && !b), nil
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b), nil
+		}
+	case []byte:
+		if b, ok := b.([]byte); ok {
+			return bytes.Compare(a, b), nil
+		}
+	}
+	return 0, errIncomparable
+}
+
+func cmp(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,180 @@
+// Test helpers for code built on ksql: mock databases answering the statements a test
+// expects, see NewMock, and fixtures. Fixtures are rows loaded into tables of a named
+// database connection before a test, from JSON files like
+//
+//	[
+//		{"table": "people", "rows": [{"id": 1, "name": "john doe"}]},
+//		{"table": "orders", "rows": [{"id": 1, "person_id": 1}]}
+//	]
+//
+// The tables are emptied and the rows inserted in a single transaction, in the order of the
+// files and of the tables within them, so tables referenced by foreign keys come first.
+package ksqltest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kahoon/ksql"
+)
+
+// Rows of a table
+type Fixture struct {
+	Table string                   `json:"table" yaml:"table"`
+	Rows  []map[string]interface{} `json:"rows" yaml:"rows"`
+}
+
+// Decoders of fixture files by format, JSON is built in
+var (
+	formatsMu sync.RWMutex
+	formats   = map[string]func([]byte, interface{}) error{"json": unmarshalJSON}
+)
+
+// Register the decoder of a fixture file format, named like the file extension, e.g.
+// RegisterFormat("yaml", yaml.Unmarshal)
+func RegisterFormat(format string, unmarshal func([]byte, interface{}) error) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[strings.ToLower(format)] = unmarshal
+}
+
+// Decode JSON keeping integers exact
+func unmarshalJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// Read the fixtures of the files, in the format of their extension
+func ReadFiles(paths ...string) ([]Fixture, error) {
+	var fixtures []Fixture
+	for _, path := range paths {
+		format := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+		formatsMu.RLock()
+		unmarshal, ok := formats[format]
+		if !ok && format == "yml" {
+			unmarshal, ok = formats["yaml"]
+		}
+		formatsMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("ksqltest: unsupported fixture format %q", format)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var file []Fixture
+		if err := unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("ksqltest: %s: %w", path, err)
+		}
+		fixtures = append(fixtures, file...)
+	}
+	return fixtures, nil
+}
+
+// Load the fixtures of the files into the database connection saved by name
+func Load(name string, paths ...string) error {
+	return LoadContext(context.Background(), name, paths...)
+}
+
+func LoadContext(ctx context.Context, name string, paths ...string) error {
+	db, ok := ksql.Get(name)
+	if !ok {
+		return ksql.ErrConnNotFound
+	}
+	fixtures, err := ReadFiles(paths...)
+	if err != nil {
+		return err
+	}
+	return Apply(ctx, db, fixtures)
+}
+
+// Load the fixtures of the files like Load, failing the test on error
+func MustLoad(t testing.TB, name string, paths ...string) {
+	t.Helper()
+	if err := Load(name, paths...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Empty the tables of the fixtures, in reverse order, and insert their rows, in a single
+// transaction. A table listed more than once is emptied once.
+func Apply(ctx context.Context, db *ksql.DB, fixtures []Fixture) error {
+	return db.WithTx(ctx, func(tx *ksql.Tx) error {
+		emptied := make(map[string]bool)
+		for i := len(fixtures) - 1; i >= 0; i-- {
+			table := fixtures[i].Table
+			if emptied[table] {
+				continue
+			}
+			emptied[table] = true
+			if _, err := tx.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+				return err
+			}
+		}
+		for _, fixture := range fixtures {
+			if err := insert(ctx, tx, fixture); err != nil {
+				return fmt.Errorf("ksqltest: table %s: %w", fixture.Table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Insert the rows of a fixture, batching the consecutive rows with the same columns, so the
+// columns left out of a row get their default value
+func insert(ctx context.Context, tx *ksql.Tx, fixture Fixture) error {
+	var columns []string
+	var batch [][]interface{}
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		_, err := tx.BatchInsert(ctx, fixture.Table, columns, batch)
+		batch = nil
+		return err
+	}
+	for _, row := range fixture.Rows {
+		keys := make([]string, 0, len(row))
+		for key := range row {
+			keys = append(keys, key)
+		}
+		if len(keys) == 0 {
+			return errors.New("row without columns")
+		}
+		sort.Strings(keys)
+		if strings.Join(keys, ",") != strings.Join(columns, ",") {
+			if err := flush(); err != nil {
+				return err
+			}
+			columns = keys
+		}
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			values[i] = value(row[key])
+		}
+		batch = append(batch, values)
+	}
+	return flush()
+}
+
+// Convert a decoded value to an argument of the driver
+func value(v interface{}) interface{} {
+	if n, ok := v.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i
+		}
+		f, _ := n.Float64()
+		return f
+	}
+	return v
+}
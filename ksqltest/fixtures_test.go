@@ -0,0 +1,158 @@
+package ksqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kahoon/ksql"
+)
+
+// Driver recording the statements it runs, and whether transactions are committed
+type recordingDriver struct {
+	mu         sync.Mutex
+	statements []string
+	commits    int
+	rollbacks  int
+}
+
+func (d *recordingDriver) Open(dsn string) (driver.Conn, error) { return recordingConn{d}, nil }
+
+func (d *recordingDriver) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements, d.commits, d.rollbacks = nil, 0, 0
+}
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if strings.Contains(query, "broken") {
+		return nil, errors.New("no such table")
+	}
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = fmt.Sprintf("%T(%v)", arg.Value, arg.Value)
+	}
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.statements = append(c.d.statements, query+" "+strings.Join(values, " "))
+	return driver.RowsAffected(1), nil
+}
+
+func (c recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (recordingConn) Close() error                { return nil }
+func (c recordingConn) Begin() (driver.Tx, error) { return recordingTx(c), nil }
+
+type recordingTx struct{ d *recordingDriver }
+
+func (tx recordingTx) Commit() error {
+	tx.d.mu.Lock()
+	defer tx.d.mu.Unlock()
+	tx.d.commits++
+	return nil
+}
+
+func (tx recordingTx) Rollback() error {
+	tx.d.mu.Lock()
+	defer tx.d.mu.Unlock()
+	tx.d.rollbacks++
+	return nil
+}
+
+var recorder = &recordingDriver{}
+
+func init() {
+	sql.Register("ksqltest_recording", recorder)
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	defer ksql.Close()
+	recorder.reset()
+	if _, err := ksql.New("fixtures", "ksqltest_recording", "fixtures"); err != nil {
+		t.Fatal(err)
+	}
+	people := writeFile(t, "people.json", `[
+		{"table": "people", "rows": [
+			{"id": 1, "name": "john doe"},
+			{"id": 2, "name": "jane doe"},
+			{"id": 3, "name": "baby doe", "ratio": 0.5}
+		]}
+	]`)
+	orders := writeFile(t, "orders.json", `[{"table": "orders", "rows": [{"id": 1, "person_id": 1}]}]`)
+	MustLoad(t, "fixtures", people, orders)
+	want := []string{
+		"DELETE FROM orders ",
+		"DELETE FROM people ",
+		"INSERT INTO people (id, name) VALUES (?, ?), (?, ?) int64(1) string(john doe) int64(2) string(jane doe)",
+		"INSERT INTO people (id, name, ratio) VALUES (?, ?, ?) int64(3) string(baby doe) float64(0.5)",
+		"INSERT INTO orders (id, person_id) VALUES (?, ?) int64(1) int64(1)",
+	}
+	if !reflect.DeepEqual(recorder.statements, want) {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(recorder.statements, "\n"))
+	}
+	if recorder.commits != 1 {
+		t.Errorf("expected a single committed transaction, got %d", recorder.commits)
+	}
+}
+
+func TestLoadFailure(t *testing.T) {
+	defer ksql.Close()
+	recorder.reset()
+	if _, err := ksql.New("fixtures", "ksqltest_recording", "fixtures"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load("missing", "people.json"); err != ksql.ErrConnNotFound {
+		t.Errorf("expected the connection not to be found, got %v", err)
+	}
+	broken := writeFile(t, "broken.json", `[{"table": "people", "rows": [{"id": 1}]}, {"table": "broken", "rows": [{"id": 1}]}]`)
+	if err := Load("fixtures", broken); err == nil {
+		t.Errorf("expected the broken table to fail")
+	}
+	if recorder.rollbacks != 1 || recorder.commits != 0 {
+		t.Errorf("expected the transaction to be rolled back, got %d rollbacks", recorder.rollbacks)
+	}
+	if err := Load("fixtures", writeFile(t, "people.ini", "")); err == nil {
+		t.Errorf("expected an unsupported format")
+	}
+	empty := writeFile(t, "empty.json", `[{"table": "people", "rows": [{}]}]`)
+	if err := Load("fixtures", empty); err == nil {
+		t.Errorf("expected a row without columns to fail")
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat("tables", func(data []byte, v interface{}) error {
+		fixtures := v.(*[]Fixture)
+		for _, table := range strings.Fields(string(data)) {
+			*fixtures = append(*fixtures, Fixture{Table: table})
+		}
+		return nil
+	})
+	fixtures, err := ReadFiles(writeFile(t, "people.tables", "people orders"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) != 2 || fixtures[1].Table != "orders" {
+		t.Errorf("expected the tables of the file, got %v", fixtures)
+	}
+}
@@ -0,0 +1,336 @@
+package ksqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kahoon/ksql"
+)
+
+// Name of the driver of the mock databases
+const mockDriverName = "ksqltest_mock"
+
+// Mock databases by data source name
+var (
+	mocksMu sync.Mutex
+	mocks   = make(map[string]*Mock)
+	mockSeq uint64
+)
+
+func init() {
+	sql.Register(mockDriverName, mockDriver{})
+}
+
+// Expected statements of a mock database, answered in the order they were added. Statements
+// are matched by their text, ignoring differences in whitespace, and by their arguments when
+// set with WithArgs.
+type Mock struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	next         int
+}
+
+// Kinds of expectations
+const (
+	expectQuery = "query"
+	expectExec  = "exec"
+	expectBegin = "begin"
+	expectEnd   = "commit"
+	expectUndo  = "rollback"
+)
+
+// Statement expected by a Mock, and the answer to it
+type Expectation struct {
+	kind    string
+	query   string
+	args    []driver.Value
+	hasArgs bool
+	columns []string
+	rows    [][]driver.Value
+	result  driver.Result
+	err     error
+}
+
+// Open a mock database and save it by name, like ksql.New. Its placeholder style and dialect
+// can be set with the options, e.g. ksql.WithDialect(ksql.DialectPostgres).
+func NewMock(name string, opts ...ksql.Option) (*ksql.DB, *Mock, error) {
+	m := &Mock{}
+	dsn := "mock-" + strconv.FormatUint(atomic.AddUint64(&mockSeq, 1), 10)
+	mocksMu.Lock()
+	mocks[dsn] = m
+	mocksMu.Unlock()
+	db, err := ksql.New(name, mockDriverName, dsn, opts...)
+	if err != nil {
+		mocksMu.Lock()
+		delete(mocks, dsn)
+		mocksMu.Unlock()
+		return nil, nil, err
+	}
+	return db, m, nil
+}
+
+// Get the rows of a query answered with the columns and values, to test code reading them
+// without a database
+func NewRows(columns []string, rows ...[]interface{}) (*ksql.Rows, error) {
+	name := "ksqltest rows " + strconv.FormatUint(atomic.AddUint64(&mockSeq, 1), 10)
+	db, m, err := NewMock(name)
+	if err != nil {
+		return nil, err
+	}
+	// the rows are all there is to the database, it is not kept by name
+	ksql.Release(name)
+	m.ExpectQuery("rows").WillReturnRows(columns, rows...)
+	return db.Query("rows")
+}
+
+func (m *Mock) expect(kind, query string) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &Expectation{kind: kind, query: normalize(query), result: driver.RowsAffected(0)}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// Expect a query, answered with no rows unless set with WillReturnRows
+func (m *Mock) ExpectQuery(query string) *Expectation {
+	return m.expect(expectQuery, query)
+}
+
+// Expect a statement, answered with no rows affected unless set with WillReturnResult
+func (m *Mock) ExpectExec(query string) *Expectation {
+	return m.expect(expectExec, query)
+}
+
+func (m *Mock) ExpectBegin() *Expectation {
+	return m.expect(expectBegin, "")
+}
+
+func (m *Mock) ExpectCommit() *Expectation {
+	return m.expect(expectEnd, "")
+}
+
+func (m *Mock) ExpectRollback() *Expectation {
+	return m.expect(expectUndo, "")
+}
+
+// Check that every expected statement was run
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.next < len(m.expectations) {
+		e := m.expectations[m.next]
+		return fmt.Errorf("ksqltest: %d expectations were not met, the next one is %s", len(m.expectations)-m.next, e)
+	}
+	return nil
+}
+
+// Take the next expectation, failing when it doesn't match the statement
+func (m *Mock) match(kind, query string, args []driver.NamedValue) (*Expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	query = normalize(query)
+	if m.next >= len(m.expectations) {
+		return nil, fmt.Errorf("ksqltest: unexpected %s %q, all expectations were met", kind, query)
+	}
+	e := m.expectations[m.next]
+	if e.kind != kind || e.query != query {
+		return nil, fmt.Errorf("ksqltest: unexpected %s %q, expected %s", kind, query, e)
+	}
+	if e.hasArgs {
+		values := make([]driver.Value, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+		}
+		if !reflect.DeepEqual(values, e.args) {
+			return nil, fmt.Errorf("ksqltest: %s %q with arguments %v, expected %v", kind, query, values, e.args)
+		}
+	}
+	m.next++
+	return e, nil
+}
+
+// Expect the arguments of the statement
+func (e *Expectation) WithArgs(args ...interface{}) *Expectation {
+	e.args = values(args)
+	e.hasArgs = true
+	return e
+}
+
+// Answer the query with the columns and rows
+func (e *Expectation) WillReturnRows(columns []string, rows ...[]interface{}) *Expectation {
+	e.columns = columns
+	for _, row := range rows {
+		e.rows = append(e.rows, values(row))
+	}
+	return e
+}
+
+// Answer the statement with the result
+func (e *Expectation) WillReturnResult(lastInsertID, rowsAffected int64) *Expectation {
+	e.result = mockResult{lastInsertID: lastInsertID, rowsAffected: rowsAffected}
+	return e
+}
+
+// Answer the statement with an error
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+func (e *Expectation) String() string {
+	if e.query == "" {
+		return e.kind
+	}
+	return e.kind + " " + strconv.Quote(e.query)
+}
+
+// Convert values like the arguments of a statement are converted by database/sql
+func values(args []interface{}) []driver.Value {
+	converted := make([]driver.Value, len(args))
+	for i, arg := range args {
+		v, err := driver.DefaultParameterConverter.ConvertValue(arg)
+		if err != nil {
+			v = arg
+		}
+		converted[i] = v
+	}
+	return converted
+}
+
+func normalize(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+type mockResult struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r mockResult) LastInsertId() (int64, error) { return r.lastInsertID, nil }
+func (r mockResult) RowsAffected() (int64, error) { return r.rowsAffected, nil }
+
+type mockDriver struct{}
+
+func (mockDriver) Open(dsn string) (driver.Conn, error) {
+	mocksMu.Lock()
+	defer mocksMu.Unlock()
+	m, ok := mocks[dsn]
+	if !ok {
+		return nil, errors.New("ksqltest: unknown mock " + dsn)
+	}
+	return mockConn{m}, nil
+}
+
+type mockConn struct {
+	mock *Mock
+}
+
+func (c mockConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	e, err := c.mock.match(expectQuery, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &mockRows{columns: e.columns, rows: e.rows}, nil
+}
+
+func (c mockConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, err := c.mock.match(expectExec, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.result, nil
+}
+
+func (c mockConn) Prepare(query string) (driver.Stmt, error) {
+	return mockStmt{conn: c, query: query}, nil
+}
+
+func (c mockConn) Begin() (driver.Tx, error) {
+	e, err := c.mock.match(expectBegin, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return mockTx(c), nil
+}
+
+func (mockConn) Ping(ctx context.Context) error { return nil }
+func (mockConn) Close() error                   { return nil }
+
+type mockTx struct {
+	mock *Mock
+}
+
+func (tx mockTx) Commit() error {
+	e, err := tx.mock.match(expectEnd, "", nil)
+	if err != nil {
+		return err
+	}
+	return e.err
+}
+
+func (tx mockTx) Rollback() error {
+	e, err := tx.mock.match(expectUndo, "", nil)
+	if err != nil {
+		return err
+	}
+	return e.err
+}
+
+type mockStmt struct {
+	conn  mockConn
+	query string
+}
+
+func (s mockStmt) Close() error  { return nil }
+func (s mockStmt) NumInput() int { return -1 }
+
+func (s mockStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s mockStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	values := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		values[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return values
+}
+
+type mockRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *mockRows) Columns() []string { return r.columns }
+func (r *mockRows) Close() error      { return nil }
+
+func (r *mockRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
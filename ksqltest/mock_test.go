@@ -0,0 +1,105 @@
+package ksqltest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kahoon/ksql"
+)
+
+func TestMock(t *testing.T) {
+	defer ksql.Close()
+	db, mock, err := NewMock("mocked", ksql.WithDialect(ksql.DialectPostgres), ksql.WithBindStyle(ksql.BindDollar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := ksql.Get("mocked"); !ok || got != db {
+		t.Fatal("expected the mock to be saved by name")
+	}
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("select name, married, born from people where id = $1").WithArgs(1).
+		WillReturnRows([]string{"name", "married", "born"}, []interface{}{"john doe", true, day})
+	mock.ExpectBegin()
+	mock.ExpectExec("update people set name = $1").WithArgs("jane doe").WillReturnResult(0, 2)
+	mock.ExpectCommit()
+	mock.ExpectExec("delete from people").WillReturnError(errors.New("permission denied"))
+
+	row := db.QueryRow("select name, married, born\n  from people where id = $1", 1)
+	name, err := row.GetString("name")
+	if err != nil || name != "john doe" {
+		t.Errorf("expected john doe, got %q %v", name, err)
+	}
+	if married, err := row.GetBoolean("married"); err != nil || !married {
+		t.Errorf("expected married, got %v %v", married, err)
+	}
+	if born, err := row.GetTime("born"); err != nil || !born.Equal(day) {
+		t.Errorf("expected the birth day, got %v %v", born, err)
+	}
+	err = db.WithTx(context.Background(), func(tx *ksql.Tx) error {
+		result, err := tx.Exec("update people set name = $1", "jane doe")
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n != 2 {
+			t.Errorf("expected 2 rows affected, got %d", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Errorf("expected the delete to be pending")
+	}
+	if _, err := db.Exec("delete from people"); err == nil || err.Error() != "permission denied" {
+		t.Errorf("expected the error of the expectation, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if _, err := db.Exec("drop table people"); err == nil {
+		t.Errorf("expected an unexpected statement to fail")
+	}
+}
+
+func TestMockArgs(t *testing.T) {
+	defer ksql.Close()
+	db, mock, err := NewMock("mocked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectExec("delete from people where id = ?").WithArgs(1)
+	if _, err := db.Exec("delete from people where id = ?", 2); err == nil {
+		t.Errorf("expected the arguments not to match")
+	}
+	if _, err := db.Exec("select 1"); err == nil {
+		t.Errorf("expected the statement not to match")
+	}
+	if _, _, err := NewMock("mocked"); err != ksql.ErrDupConnName {
+		t.Errorf("expected a duplicate name, got %v", err)
+	}
+}
+
+func TestNewRows(t *testing.T) {
+	rows, err := NewRows([]string{"id", "name"}, []interface{}{1, "john doe"}, []interface{}{2, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		name, err := rows.GetNullString("name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name.String)
+	}
+	if len(names) != 2 || names[0] != "john doe" || names[1] != "" {
+		t.Errorf("expected both rows, got %v", names)
+	}
+	if len(ksql.Databases()) != 0 {
+		t.Errorf("expected the rows not to be saved by name")
+	}
+}
@@ -0,0 +1,70 @@
+package ksql
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Parse text into numbers and booleans for the getters of the database, and convert
+// between integers and floating point numbers when no precision is lost, see Rows.SetLenient
+func WithLenientConversion() Option {
+	return func(db *DB) error {
+		db.read.lenient = true
+		return nil
+	}
+}
+
+// Parse text into numbers ("42", "3.14") and booleans ("yes", "off") for GetInteger, GetDouble
+// and GetBoolean, and convert between integers and floating point numbers when no precision
+// is lost
+func (rs *Rows) SetLenient(on bool) {
+	rs.opts.lenient = on
+}
+
+// Turn the value into the kind of want when it can be parsed or converted losslessly, or
+// return it unchanged for the strict conversion to reject
+func lenientValue(value interface{}, want interface{}) interface{} {
+	var s string
+	switch v := value.(type) {
+	case []byte:
+		s = strings.TrimSpace(string(v))
+	case string:
+		s = strings.TrimSpace(v)
+	}
+	switch want.(type) {
+	case int64:
+		switch v := value.(type) {
+		case []byte, string:
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return n
+			}
+		case float64:
+			if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+				return int64(v)
+			}
+		}
+	case float64:
+		switch v := value.(type) {
+		case []byte, string:
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f
+			}
+		case int64:
+			return float64(v)
+		}
+	case bool:
+		switch value.(type) {
+		case []byte, string:
+			switch strings.ToLower(s) {
+			case "y", "yes", "on":
+				return true
+			case "n", "no", "off":
+				return false
+			}
+			// GetBoolean reads t, true, 1 and their opposites itself
+			return s
+		}
+	}
+	return value
+}
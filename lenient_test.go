@@ -0,0 +1,64 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestLenientValue(t *testing.T) {
+	tests := []struct {
+		value, want, got interface{}
+	}{
+		{"42", int64(0), int64(42)},
+		{[]byte(" -7 "), int64(0), int64(-7)},
+		{3.0, int64(0), int64(3)},
+		{3.5, int64(0), 3.5},
+		{"4x", int64(0), "4x"},
+		{"3.14", float64(0), 3.14},
+		{int64(2), float64(0), float64(2)},
+		{"yes", false, true},
+		{[]byte(" OFF "), false, false},
+		{" t ", false, "t"},
+		{"maybe", false, "maybe"},
+		{"42", "", "42"},
+	}
+	for _, test := range tests {
+		got := lenientValue(test.value, test.want)
+		if b, ok := got.([]byte); ok {
+			got = string(b)
+		}
+		if got != test.got {
+			t.Errorf("expected %#v for %#v, got %#v", test.got, test.value, got)
+		}
+	}
+}
+
+func TestLenientConversion(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select text", []string{"count", "ratio", "flag"},
+		[]driver.Value{[]byte("42"), "3.14", "yes"})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.QueryRow("select text").GetInteger("count"); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected strict conversion by default, got %v", err)
+	}
+	lenient, err := New("lenient", "ksql_hosts", "hosts", WithLenientConversion())
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := lenient.QueryRow("select text")
+	if v, err := row.GetInteger("count"); err != nil || v != 42 {
+		t.Errorf("expected 42 for \"count\", got %v %v", v, err)
+	}
+	if v, err := row.GetDouble("ratio"); err != nil || v != 3.14 {
+		t.Errorf("expected 3.14 for \"ratio\", got %v %v", v, err)
+	}
+	if v, err := row.GetBoolean("flag"); err != nil || !v {
+		t.Errorf("expected true for \"flag\", got %v %v", v, err)
+	}
+	if _, err := row.GetInteger("ratio"); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected a conversion error for \"ratio\", got %v", err)
+	}
+}
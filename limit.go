@@ -0,0 +1,81 @@
+package ksql
+
+import (
+	"context"
+	"time"
+)
+
+// Cap on the statements of a database running at once
+type limiter struct {
+	slots chan struct{}
+	wait  time.Duration
+}
+
+// Allow at most n statements and transactions of the database to run at once, counting
+// queries until their rows are closed and transactions until they end. Statements beyond the
+// limit wait up to the given time for a slot, then fail with ErrSaturated, a zero wait fails
+// them right away. The statements of a transaction are not limited, the transaction holds
+// its slot. Replicas get a limit of the same size of their own, unless set by their options.
+func WithConcurrencyLimit(n int, wait time.Duration) Option {
+	return func(db *DB) error {
+		if n <= 0 {
+			db.limit = nil
+			return nil
+		}
+		db.limit = &limiter{slots: make(chan struct{}, n), wait: wait}
+		return nil
+	}
+}
+
+// Get the number of statements and transactions of the database running within the limit of
+// WithConcurrencyLimit, and the limit itself, zero when there is none
+func (db *DB) Concurrency() (running, limit int) {
+	if db.limit == nil {
+		return 0, 0
+	}
+	return len(db.limit.slots), cap(db.limit.slots)
+}
+
+// Get a limiter with the same settings, and none of the slots taken
+func (l *limiter) clone() *limiter {
+	if l == nil {
+		return nil
+	}
+	return &limiter{slots: make(chan struct{}, cap(l.slots)), wait: l.wait}
+}
+
+// Take a slot, waiting for one up to the wait time or until the context is done. The
+// returned function gives the slot back, it can be called more than once.
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return l.releaser(), nil
+	default:
+	}
+	if l.wait <= 0 {
+		return nil, ErrSaturated
+	}
+	timer := time.NewTimer(l.wait)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return l.releaser(), nil
+	case <-timer.C:
+		return nil, ErrSaturated
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *limiter) releaser() func() {
+	released := false
+	return func() {
+		if !released {
+			released = true
+			<-l.slots
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package ksql
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	defer Close()
+	db, err := New("limited", "ksql_hosts", "limited", WithConcurrencyLimit(2, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if running, limit := db.Concurrency(); running != 2 || limit != 2 {
+		t.Errorf("expected 2 of 2 slots taken, got %d of %d", running, limit)
+	}
+	if _, err := db.Exec("update people"); err != ErrSaturated {
+		t.Errorf("expected the database to be saturated, got %v", err)
+	}
+	if _, err := tx.Exec("update people"); err != nil {
+		t.Errorf("expected the statements of the transaction to run, got %v", err)
+	}
+	// reading every row gives the slot back
+	for rows.Next() {
+	}
+	if _, err := db.Exec("update people"); err != nil {
+		t.Errorf("expected a free slot, got %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if running, _ := db.Concurrency(); running != 0 {
+		t.Errorf("expected every slot back, got %d", running)
+	}
+}
+
+func TestConcurrencyLimitWait(t *testing.T) {
+	defer Close()
+	db, err := New("waiting", "ksql_hosts", "waiting", WithConcurrencyLimit(1, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := db.Prepare("select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	rows, err := stmt.Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		rows.Close()
+	}()
+	if _, err := db.Exec("update people"); err != nil {
+		t.Errorf("expected to wait for the slot, got %v", err)
+	}
+
+	rows, err = db.Query("select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := stmt.ExecContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected the context to end the wait, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package ksql
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Levels of the messages logged by WithLogger
+type LogLevels struct {
+	Query     slog.Level // statements that succeeded
+	Error     slog.Level // statements that failed
+	Slow      slog.Level // statements slower than the threshold of WithSlowQueryThreshold
+	Lifecycle slog.Level // opening and closing of the database connection
+}
+
+// Log levels used by WithLogger
+var DefaultLogLevels = LogLevels{Query: slog.LevelDebug, Error: slog.LevelError, Slow: slog.LevelWarn, Lifecycle: slog.LevelInfo}
+
+// Log the statements of the database connection, along with their duration and errors,
+// and the opening and closing of the connection, at the default levels
+func WithLogger(logger *slog.Logger) Option {
+	return WithLoggerLevels(logger, DefaultLogLevels)
+}
+
+// Log like WithLogger, at the given levels
+func WithLoggerLevels(logger *slog.Logger, levels LogLevels) Option {
+	return func(db *DB) error {
+		db.logger = logger
+		db.logLevels = levels
+		db.hooks = append(db.hooks, &logHook{db: db})
+		db.onClose = append(db.onClose, func() {
+			logger.LogAttrs(context.Background(), levels.Lifecycle, "ksql: database closed", slog.String("db", db.name))
+		})
+		return nil
+	}
+}
+
+// Log the opening of the database, once every option applied and it is ready for use
+func (db *DB) logOpened() {
+	if db.logger != nil {
+		db.logger.LogAttrs(context.Background(), db.logLevels.Lifecycle, "ksql: database opened", slog.String("db", db.name))
+	}
+}
+
+// Hook logging every statement to the logger of the database
+type logHook struct {
+	db *DB
+}
+
+func (h *logHook) BeforeQuery(ctx context.Context, event *QueryEvent) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *logHook) AfterQuery(ctx context.Context, event *QueryEvent) {
+	attrs := []slog.Attr{
+		slog.String("db", event.DB),
+		slog.String("op", event.Op),
+		slog.String("query", event.Query),
+		slog.Int("args", len(event.Args)),
+		slog.Duration("duration", event.Duration()),
+	}
+	if event.RowsAffected >= 0 {
+		attrs = append(attrs, slog.Int64("rows_affected", event.RowsAffected))
+	}
+	if event.Err != nil {
+		attrs = append(attrs, slog.Any("error", event.Err))
+		h.db.logger.LogAttrs(ctx, h.db.logLevels.Error, "ksql: statement failed", attrs...)
+		return
+	}
+	if event.Slow {
+		h.db.logger.LogAttrs(ctx, h.db.logLevels.Slow, "ksql: slow statement", attrs...)
+		return
+	}
+	h.db.logger.LogAttrs(ctx, h.db.logLevels.Query, "ksql: statement", attrs...)
+}
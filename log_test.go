@@ -0,0 +1,97 @@
+package ksql
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithLogger(t *testing.T) {
+	defer Close()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	db, err := New("hosts", "ksql_hosts", "hosts", WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("update people set married = $1", true); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("fail"); err == nil {
+		t.Fatalf("expected the statement to fail")
+	}
+	tx.Rollback()
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 log lines, got %q", lines)
+	}
+	want := []string{
+		`level=INFO msg="ksql: database opened" db=hosts`,
+		`level=DEBUG msg="ksql: statement" db=hosts op=exec query="update people set married = $1" args=1 duration=`,
+		`level=ERROR msg="ksql: statement failed" db=hosts op=exec query=fail args=0 duration=`,
+		`level=DEBUG msg="ksql: statement" db=hosts op=tx query=ROLLBACK args=0 duration=`,
+		`level=INFO msg="ksql: database closed" db=hosts`,
+	}
+	for i, w := range want {
+		if !strings.Contains(lines[i], w) {
+			t.Errorf("expected line %d to contain %q, got %q", i, w, lines[i])
+		}
+	}
+	if !strings.Contains(lines[1], "rows_affected=1") || !strings.Contains(lines[2], `error="statement failed"`) {
+		t.Errorf("expected the rows affected and error to be logged, got %q", lines[1:3])
+	}
+}
+
+func TestLoggerOpenFailure(t *testing.T) {
+	defer Close()
+	testHosts.setDown("log down", true)
+	defer testHosts.setDown("log down", false)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	if _, err := New("down", "ksql_hosts", "log down", WithLogger(logger), WithPingOnOpen()); err == nil {
+		t.Fatal("expected the ping to fail")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing logged for the database that failed to open, got %q", buf.String())
+	}
+}
+
+func TestSlowQueryThreshold(t *testing.T) {
+	defer Close()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	hook := &recordingHook{}
+	slow, err := New("slow", "ksql_hosts", "slow", WithLogger(logger), WithSlowQueryThreshold(time.Nanosecond), WithHook(hook))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, err := New("fast", "ksql_hosts", "fast", WithLogger(logger), WithSlowQueryThreshold(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := slow.Exec("update people set married = $1", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fast.Exec("update people set married = $1", false); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected only the slow statement to be logged, got %q", lines)
+	}
+	if !strings.Contains(lines[0], `level=WARN msg="ksql: slow statement" db=slow op=exec query="update people set married = $1" args=1 duration=`) {
+		t.Errorf("expected the slow statement, got %q", lines[0])
+	}
+	if len(hook.after) != 1 || !hook.after[0].Slow {
+		t.Errorf("expected the hook to see a slow statement, got %+v", hook.after)
+	}
+}
@@ -0,0 +1,51 @@
+package ksql
+
+import "context"
+
+// Consume every remaining row into a slice of column maps, and close the rows
+func (rs *Rows) AllMaps() ([]map[string]interface{}, error) {
+	defer rs.Close()
+	var result []map[string]interface{}
+	for rs.Next() {
+		m := make(map[string]interface{}, len(rs.columns))
+		if err := rs.MapScan(m); err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, err
+	}
+	if err := rs.Close(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Run the query and load the entire result into a slice of column maps
+func (db *DB) QueryMaps(query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return db.QueryMapsContext(context.Background(), query, args...)
+}
+
+func (db *DB) QueryMapsContext(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	// the rows never leave this function, they can be recycled
+	defer putRows(rows)
+	return rows.AllMaps()
+}
+
+// Run the query and load its first row into a column map, or return ErrNoRows
+func (db *DB) QueryRowMap(query string, args ...interface{}) (map[string]interface{}, error) {
+	return db.QueryRowMapContext(context.Background(), query, args...)
+}
+
+func (db *DB) QueryRowMapContext(ctx context.Context, query string, args ...interface{}) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	if err := db.QueryRowContext(ctx, query, args...).MapScan(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,30 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestQueryRowMap(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select settings", []string{"key", "value"},
+		[]driver.Value{"theme", "dark"}, []driver.Value{"size", "12"})
+	testHosts.setResult("select nothing", []string{"key", "value"})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := db.QueryRowMap("select settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 || m["key"] != "theme" || m["value"] != "dark" {
+		t.Errorf("expected the first row, got %v", m)
+	}
+	if _, err := db.QueryRowMap("select nothing"); err != ErrNoRows {
+		t.Errorf("expected no rows, got %v", err)
+	}
+	if _, err := db.QueryRowMap("fail"); err == nil {
+		t.Errorf("expected the query error")
+	}
+}
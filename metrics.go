@@ -0,0 +1,147 @@
+package ksql
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Number of latest durations kept per statement to estimate the percentiles
+const metricsSamples = 1024
+
+// Hook tracking the count, errors and latency of the statements by database and fingerprint,
+// so statements differing only by their literal values are counted together, e.g.
+//
+//	metrics := ksql.NewQueryMetrics()
+//	ksql.AddHook(metrics)
+//	...
+//	for _, r := range metrics.Report() { ... }
+type QueryMetrics struct {
+	mu         sync.Mutex
+	statements map[metricsKey]*statementMetrics
+}
+
+type metricsKey struct {
+	db          string
+	fingerprint string
+}
+
+type statementMetrics struct {
+	count   int64
+	errors  int64
+	total   time.Duration
+	max     time.Duration
+	samples []time.Duration // ring of the latest durations
+	next    int
+}
+
+// Metrics of a statement, as returned by QueryMetrics.Report
+type QueryReport struct {
+	DB          string        `json:"db"`
+	Fingerprint string        `json:"fingerprint"`
+	Count       int64         `json:"count"`
+	Errors      int64         `json:"errors"`
+	ErrorRate   float64       `json:"error_rate"` // errors over count, between 0 and 1
+	Total       time.Duration `json:"total"`
+	Mean        time.Duration `json:"mean"`
+	P50         time.Duration `json:"p50"` // percentiles of the latest durations
+	P90         time.Duration `json:"p90"`
+	P99         time.Duration `json:"p99"`
+	Max         time.Duration `json:"max"`
+}
+
+// Create an empty metrics registry, to add as a hook with AddHook or WithHook
+func NewQueryMetrics() *QueryMetrics {
+	return &QueryMetrics{statements: make(map[metricsKey]*statementMetrics)}
+}
+
+func (m *QueryMetrics) BeforeQuery(ctx context.Context, event *QueryEvent) (context.Context, error) {
+	return ctx, nil
+}
+
+// Record the statement, transactions are not tracked
+func (m *QueryMetrics) AfterQuery(ctx context.Context, event *QueryEvent) {
+	if event.Op == OpTx {
+		return
+	}
+	key := metricsKey{db: event.DB, fingerprint: Fingerprint(event.Query)}
+	d := event.Duration()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.statements[key]
+	if !ok {
+		s = &statementMetrics{}
+		m.statements[key] = s
+	}
+	s.count++
+	if event.Err != nil {
+		s.errors++
+	}
+	s.total += d
+	if d > s.max {
+		s.max = d
+	}
+	if len(s.samples) < metricsSamples {
+		s.samples = append(s.samples, d)
+	} else {
+		s.samples[s.next] = d
+		s.next = (s.next + 1) % metricsSamples
+	}
+}
+
+// Get the metrics of every statement, the most run first
+func (m *QueryMetrics) Report() []QueryReport {
+	m.mu.Lock()
+	reports := make([]QueryReport, 0, len(m.statements))
+	sorted := make([][]time.Duration, 0, len(m.statements))
+	for key, s := range m.statements {
+		reports = append(reports, QueryReport{
+			DB:          key.db,
+			Fingerprint: key.fingerprint,
+			Count:       s.count,
+			Errors:      s.errors,
+			ErrorRate:   float64(s.errors) / float64(s.count),
+			Total:       s.total,
+			Mean:        s.total / time.Duration(s.count),
+			Max:         s.max,
+		})
+		sorted = append(sorted, append([]time.Duration(nil), s.samples...))
+	}
+	m.mu.Unlock()
+	for i, samples := range sorted {
+		sort.Slice(samples, func(a, b int) bool { return samples[a] < samples[b] })
+		reports[i].P50 = percentile(samples, 50)
+		reports[i].P90 = percentile(samples, 90)
+		reports[i].P99 = percentile(samples, 99)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Count != reports[j].Count {
+			return reports[i].Count > reports[j].Count
+		}
+		if reports[i].DB != reports[j].DB {
+			return reports[i].DB < reports[j].DB
+		}
+		return reports[i].Fingerprint < reports[j].Fingerprint
+	})
+	return reports
+}
+
+// Forget every statement
+func (m *QueryMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statements = make(map[metricsKey]*statementMetrics)
+}
+
+// Get the nearest rank percentile of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
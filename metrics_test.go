@@ -0,0 +1,65 @@
+package ksql
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQueryMetrics(t *testing.T) {
+	defer Close()
+	metrics := NewQueryMetrics()
+	db, err := New("hosts", "ksql_hosts", "hosts", WithHook(metrics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		rows, err := db.Query("select * from people where id = 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows.Close()
+	}
+	if _, err := db.Exec("update people set name = 'x' where id = 2"); err != nil {
+		t.Fatal(err)
+	}
+	db.Exec("fail")
+	if err := db.WithTx(context.Background(), func(tx *Tx) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	report := metrics.Report()
+	if len(report) != 3 {
+		t.Fatalf("expected 3 statements, got %+v", report)
+	}
+	if r := report[0]; r.Fingerprint != "select * from people where id = ?" || r.Count != 3 || r.Errors != 0 || r.DB != "hosts" {
+		t.Errorf("expected the select first, got %+v", r)
+	}
+	for _, r := range report[1:] {
+		if r.Fingerprint == "fail" && (r.Errors != 1 || r.ErrorRate != 1) {
+			t.Errorf("expected the failed statement to be counted, got %+v", r)
+		}
+	}
+	metrics.Reset()
+	if report := metrics.Report(); len(report) != 0 {
+		t.Errorf("expected no statements after a reset, got %d", len(report))
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i))
+	}
+	if p := percentile(sorted, 50); p != 50 {
+		t.Errorf("expected the 50th sample, got %v", p)
+	}
+	if p := percentile(sorted, 99); p != 99 {
+		t.Errorf("expected the 99th sample, got %v", p)
+	}
+	if p := percentile(sorted[:1], 90); p != 1 {
+		t.Errorf("expected the only sample, got %v", p)
+	}
+	if p := percentile(nil, 50); p != 0 {
+		t.Errorf("expected no percentile, got %v", p)
+	}
+}
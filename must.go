@@ -0,0 +1,106 @@
+package ksql
+
+// The Must getters panic instead of returning an error, for tests, fixtures and one-off
+// tools. They are kept in this file only, so that production code can lint against them.
+
+import (
+	"fmt"
+	"time"
+)
+
+func mustGet[T any](column string, value T, err error) T {
+	if err != nil {
+		panic(fmt.Errorf("ksql: column %q: %w", column, err))
+	}
+	return value
+}
+
+// Get the boolean value in this row by column name, panicking on error
+func (rs *Rows) MustGetBoolean(column string) bool {
+	value, err := rs.GetBoolean(column)
+	return mustGet(column, value, err)
+}
+
+// Get the integer value in this row by column name, panicking on error
+func (rs *Rows) MustGetInteger(column string) int64 {
+	value, err := rs.GetInteger(column)
+	return mustGet(column, value, err)
+}
+
+// Get the float value in this row by column name, panicking on error
+func (rs *Rows) MustGetDouble(column string) float64 {
+	value, err := rs.GetDouble(column)
+	return mustGet(column, value, err)
+}
+
+// Get the string value in this row by column name, panicking on error
+func (rs *Rows) MustGetString(column string) string {
+	value, err := rs.GetString(column)
+	return mustGet(column, value, err)
+}
+
+// Get the time.Time value in this row by column name, panicking on error
+func (rs *Rows) MustGetTime(column string) time.Time {
+	value, err := rs.GetTime(column)
+	return mustGet(column, value, err)
+}
+
+// Get the boolean value in this row by column name, panicking on error
+func (r *Row) MustGetBoolean(column string) bool {
+	value, err := r.GetBoolean(column)
+	return mustGet(column, value, err)
+}
+
+// Get the integer value in this row by column name, panicking on error
+func (r *Row) MustGetInteger(column string) int64 {
+	value, err := r.GetInteger(column)
+	return mustGet(column, value, err)
+}
+
+// Get the float value in this row by column name, panicking on error
+func (r *Row) MustGetDouble(column string) float64 {
+	value, err := r.GetDouble(column)
+	return mustGet(column, value, err)
+}
+
+// Get the string value in this row by column name, panicking on error
+func (r *Row) MustGetString(column string) string {
+	value, err := r.GetString(column)
+	return mustGet(column, value, err)
+}
+
+// Get the time.Time value in this row by column name, panicking on error
+func (r *Row) MustGetTime(column string) time.Time {
+	value, err := r.GetTime(column)
+	return mustGet(column, value, err)
+}
+
+// Get the boolean value in this row by column name, panicking on error
+func (v *RowView) MustGetBoolean(column string) bool {
+	value, err := v.GetBoolean(column)
+	return mustGet(column, value, err)
+}
+
+// Get the integer value in this row by column name, panicking on error
+func (v *RowView) MustGetInteger(column string) int64 {
+	value, err := v.GetInteger(column)
+	return mustGet(column, value, err)
+}
+
+// Get the float value in this row by column name, panicking on error
+func (v *RowView) MustGetDouble(column string) float64 {
+	value, err := v.GetDouble(column)
+	return mustGet(column, value, err)
+}
+
+// Get the string value in this row by column name, panicking on error
+func (v *RowView) MustGetString(column string) string {
+	value, err := v.GetString(column)
+	return mustGet(column, value, err)
+}
+
+// Get the time.Time value in this row by column name, panicking on error
+func (v *RowView) MustGetTime(column string) time.Time {
+	value, err := v.GetTime(column)
+	return mustGet(column, value, err)
+}
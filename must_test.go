@@ -0,0 +1,28 @@
+package ksql
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMustGetters(t *testing.T) {
+	defer Close()
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := db.QueryRow("select people").MustGetInteger("id"); id != 1 {
+		t.Errorf("expected 1 for \"id\", got %d", id)
+	}
+	if name := db.QueryRow("select people").MustGetString("name"); name != "john doe" {
+		t.Errorf("expected \"john doe\" for \"name\", got %q", name)
+	}
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrColumnNotFound) || err.Error() != `ksql: column "missing": `+ErrColumnNotFound.Error() {
+			t.Errorf("expected a column not found panic, got %v", err)
+		}
+	}()
+	db.QueryRow("select people").MustGetString("missing")
+	t.Errorf("expected a panic")
+}
@@ -0,0 +1,70 @@
+package ksql
+
+import (
+	"os"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// Open the mysql test database named by MYSQL_DSN, skipping the test when it is not set
+func openMySQLTestConn(t *testing.T) *DB {
+	dsn := os.Getenv("MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_DSN not set")
+	}
+	db, err := New("mysql", "mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, query := range []string{
+		"drop table if exists people",
+		"create table people (id integer not null,name varchar(100) not null,bio text null,primary key(id))",
+		"insert into people values (1,'john doe',null)",
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestMySQLStrings(t *testing.T) {
+	db := openMySQLTestConn(t)
+	defer Close()
+	if db.BindStyle() != BindQuestion || db.Dialect() != DialectMySQL {
+		t.Errorf("expected ? placeholders and the mysql dialect, got %v and %v", db.BindStyle(), db.Dialect())
+	}
+	// the text protocol of unprepared queries returns every column as bytes
+	rows, err := db.Query("select name, bio from people where id=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatalf("expected a row of results, got none!")
+	}
+	name, err := rows.GetString("name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "john doe" {
+		t.Errorf("expected \"john doe\" for \"name\", got \"%s\"", name)
+	}
+	bio, err := rows.GetNullString("bio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bio.Valid {
+		t.Errorf("expected NULL for \"bio\", got %q", bio.String)
+	}
+	var p struct {
+		Name string
+	}
+	if err := db.QueryRow("select name from people where id=?", 1).ScanStruct(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "john doe" {
+		t.Errorf("expected \"john doe\" scanned into Name, got \"%s\"", p.Name)
+	}
+}
@@ -0,0 +1,247 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Placeholder style used by a driver for positional query arguments
+type BindStyle int
+
+const (
+	BindQuestion BindStyle = iota // ? as used by mysql and sqlite
+	BindDollar                    // $1 as used by postgres
+	BindColon                     // :1 as used by oracle
+	BindAt                        // @p1 as used by sql server
+)
+
+// Guess the placeholder style from a driver name or driver package path
+func bindStyleFor(driver string) BindStyle {
+	driver = strings.ToLower(driver)
+	switch {
+	case strings.Contains(driver, "postgres"), strings.Contains(driver, "pgx"), strings.HasSuffix(driver, "pq"):
+		return BindDollar
+	case strings.Contains(driver, "sqlserver"), strings.Contains(driver, "mssql"):
+		return BindAt
+	case strings.Contains(driver, "oci8"), strings.Contains(driver, "godror"), strings.Contains(driver, "goracle"), strings.Contains(driver, "oracle"):
+		return BindColon
+	}
+	return BindQuestion
+}
+
+// Get the package path of the driver of an already open database
+func driverPackage(db *sql.DB) string {
+	t := reflect.TypeOf(db.Driver())
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.PkgPath()
+}
+
+// Override the placeholder style guessed from the driver
+func WithBindStyle(style BindStyle) Option {
+	return func(db *DB) error {
+		db.bind = style
+		return nil
+	}
+}
+
+// Write the placeholder for the n-th (1 based) argument
+func (style BindStyle) placeholder(b *strings.Builder, n int) {
+	switch style {
+	case BindDollar:
+		b.WriteByte('$')
+	case BindColon:
+		b.WriteByte(':')
+	case BindAt:
+		b.WriteString("@p")
+	default:
+		b.WriteByte('?')
+		return
+	}
+	b.WriteString(strconv.Itoa(n))
+}
+
+// Rewrite the :name parameters of query into positional placeholders of the given style,
+// and collect the matching arguments from arg, a map[string]interface{} or a struct whose
+// fields are matched by tag or snake_case name like Rows.ScanStruct
+func BindNamed(style BindStyle, query string, arg interface{}) (string, []interface{}, error) {
+	rewritten, names := compileNamed(style, query)
+	args := make([]interface{}, len(names))
+	if len(names) == 0 {
+		return rewritten, args, nil
+	}
+	lookup, err := namedLookup(arg)
+	if err != nil {
+		return "", nil, err
+	}
+	for i, name := range names {
+		value, ok := lookup(name)
+		if !ok {
+			return "", nil, ErrNamedParameterNotFound
+		}
+		args[i] = value
+	}
+	return rewritten, args, nil
+}
+
+// Find the :name parameters of query, skipping quoted text, comments and :: casts
+func compileNamed(style BindStyle, query string) (string, []string) {
+	var b strings.Builder
+	var names []string
+	for i := 0; i < len(query); i++ {
+		if end := skipLiteral(query, i); end > i {
+			b.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+		c := query[i]
+		switch {
+		case c == ':' && i+1 < len(query) && query[i+1] == ':':
+			b.WriteString("::")
+			i++
+		case c == ':' && i+1 < len(query) && isNameStart(query[i+1]):
+			j := i + 1
+			for j < len(query) && isNamePart(query[j]) {
+				j++
+			}
+			names = append(names, query[i+1:j])
+			style.placeholder(&b, len(names))
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), names
+}
+
+// Get the end offset of the quoted text or comment starting at offset i of query,
+// or i itself when there is none
+func skipLiteral(query string, i int) int {
+	c := query[i]
+	switch {
+	case c == '\'' || c == '"' || c == '`':
+		if end := strings.IndexByte(query[i+1:], c); end >= 0 {
+			return i + end + 2
+		}
+		return len(query)
+	case c == '-' && strings.HasPrefix(query[i:], "--"):
+		if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+			return i + end + 1
+		}
+		return len(query)
+	case c == '/' && strings.HasPrefix(query[i:], "/*"):
+		if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+			return i + end + 4
+		}
+		return len(query)
+	case c == '$' && (i == 0 || !isNamePart(query[i-1]) && query[i-1] != '$'):
+		// postgres dollar quoting, $$text$$ or $tag$text$tag$, but not the $1 placeholders
+		j := strings.IndexByte(query[i+1:], '$')
+		if j < 0 {
+			return i
+		}
+		tag := query[i+1 : i+1+j]
+		for k := 0; k < len(tag); k++ {
+			if !isNameStart(tag[k]) && (k == 0 || tag[k] < '0' || tag[k] > '9') {
+				return i
+			}
+		}
+		delim := query[i : i+j+2]
+		if end := strings.Index(query[i+len(delim):], delim); end >= 0 {
+			return i + len(delim) + end + len(delim)
+		}
+		return len(query)
+	}
+	return i
+}
+
+// Rewrite the ? placeholders of query into the given style, skipping quoted text and comments
+func Rebind(style BindStyle, query string) string {
+	if style == BindQuestion {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if end := skipLiteral(query, i); end > i {
+			b.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+		if query[i] == '?' {
+			n++
+			style.placeholder(&b, n)
+			continue
+		}
+		b.WriteByte(query[i])
+	}
+	return b.String()
+}
+
+// Get the placeholder style used by this database
+func (db *DB) BindStyle() BindStyle {
+	return db.bind
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isNamePart(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9' || c == '.'
+}
+
+// Build a lookup of parameter values by name from a map or struct
+func namedLookup(arg interface{}) (func(string) (interface{}, bool), error) {
+	if m, ok := arg.(map[string]interface{}); ok {
+		return func(name string) (interface{}, bool) {
+			value, ok := m[name]
+			return value, ok
+		}, nil
+	}
+	v := reflect.ValueOf(arg)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, ErrInvalidNamedArgument
+	}
+	fields := fieldsOf(v.Type())
+	return func(name string) (interface{}, bool) {
+		index, ok := fields[name]
+		if !ok {
+			return nil, false
+		}
+		return v.FieldByIndex(index).Interface(), true
+	}, nil
+}
+
+// Run a query with :name parameters bound from a map or struct
+func (db *DB) NamedQuery(query string, arg interface{}) (*Rows, error) {
+	return db.NamedQueryContext(context.Background(), query, arg)
+}
+
+func (db *DB) NamedQueryContext(ctx context.Context, query string, arg interface{}) (*Rows, error) {
+	query, args, err := BindNamed(db.bind, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return db.QueryContext(ctx, query, args...)
+}
+
+// Execute a statement with :name parameters bound from a map or struct
+func (db *DB) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	return db.NamedExecContext(context.Background(), query, arg)
+}
+
+func (db *DB) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	query, args, err := BindNamed(db.bind, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return db.ExecContext(ctx, query, args...)
+}
@@ -0,0 +1,68 @@
+package ksql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBindNamed(t *testing.T) {
+	type person struct {
+		ID   int64
+		Name string `ksql:"full_name"`
+	}
+	p := person{ID: 1, Name: "john doe"}
+	tests := []struct {
+		style BindStyle
+		query string
+		arg   interface{}
+		want  string
+		args  []interface{}
+	}{
+		{BindDollar, "insert into people values (:id,:full_name)", p, "insert into people values ($1,$2)", []interface{}{int64(1), "john doe"}},
+		{BindQuestion, "insert into people values (:id,:full_name)", &p, "insert into people values (?,?)", []interface{}{int64(1), "john doe"}},
+		{BindColon, "select * from people where id=:id or id=:id", p, "select * from people where id=:1 or id=:2", []interface{}{int64(1), int64(1)}},
+		{BindAt, "select * from people where name=:name", map[string]interface{}{"name": "jd"}, "select * from people where name=@p1", []interface{}{"jd"}},
+		{BindDollar, "select ':id', \":id\", id::text from people -- :id\nwhere id=:id /* :id */", p, "select ':id', \":id\", id::text from people -- :id\nwhere id=$1 /* :id */", []interface{}{int64(1)}},
+		{BindDollar, "select 1", nil, "select 1", []interface{}{}},
+		{BindDollar, "select $$:id$$, $tag$:id$tag$, :id", p, "select $$:id$$, $tag$:id$tag$, $1", []interface{}{int64(1)}},
+	}
+	for _, test := range tests {
+		query, args, err := BindNamed(test.style, test.query, test.arg)
+		if err != nil {
+			t.Errorf("%q: %v", test.query, err)
+			continue
+		}
+		if query != test.want {
+			t.Errorf("expected %q, got %q", test.want, query)
+		}
+		if !reflect.DeepEqual(args, test.args) {
+			t.Errorf("%q: expected args %v, got %v", test.query, test.args, args)
+		}
+	}
+	if _, _, err := BindNamed(BindDollar, "select :missing", p); err != ErrNamedParameterNotFound {
+		t.Errorf("expected a parameter not found error, got %v", err)
+	}
+	if _, _, err := BindNamed(BindDollar, "select :id", 1); err != ErrInvalidNamedArgument {
+		t.Errorf("expected an invalid argument error, got %v", err)
+	}
+}
+
+func TestBindStyleFor(t *testing.T) {
+	tests := map[string]BindStyle{
+		"postgres":                        BindDollar,
+		"pgx":                             BindDollar,
+		"github.com/lib/pq":               BindDollar,
+		"github.com/jackc/pgx/v5/stdlib":  BindDollar,
+		"mysql":                           BindQuestion,
+		"sqlite3":                         BindQuestion,
+		"sqlserver":                       BindAt,
+		"github.com/godror/godror":        BindColon,
+		"github.com/go-sql-driver/mysql":  BindQuestion,
+		"github.com/microsoft/go-mssqldb": BindAt,
+	}
+	for driver, want := range tests {
+		if got := bindStyleFor(driver); got != want {
+			t.Errorf("%s: expected bind style %d, got %d", driver, want, got)
+		}
+	}
+}
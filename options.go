@@ -0,0 +1,102 @@
+package ksql
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Option configures a named database connection when it is created by New or NewWithDB
+type Option func(*DB) error
+
+// Set the maximum number of open connections to the database
+func WithMaxOpenConns(n int) Option {
+	return func(db *DB) error {
+		db.SetMaxOpenConns(n)
+		return nil
+	}
+}
+
+// Set the maximum number of connections kept in the idle pool
+func WithMaxIdleConns(n int) Option {
+	return func(db *DB) error {
+		db.SetMaxIdleConns(n)
+		return nil
+	}
+}
+
+// Set the maximum amount of time a connection may be reused
+func WithConnMaxLifetime(d time.Duration) Option {
+	return func(db *DB) error {
+		db.SetConnMaxLifetime(d)
+		return nil
+	}
+}
+
+// Verify the connection with a ping before the database is saved by name
+func WithPingOnOpen() Option {
+	return WithVerifyConnection(0)
+}
+
+// Verify the connection with a ping before the database is saved by name, giving up after
+// the timeout. A zero timeout waits as long as the driver does.
+func WithVerifyConnection(timeout time.Duration) Option {
+	return func(db *DB) error {
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		if err := db.PingContext(ctx); err != nil {
+			return fmt.Errorf("ksql: unable to connect to %q: %w", db.name, err)
+		}
+		return nil
+	}
+}
+
+// Match column names regardless of case in the rows of the database, see Rows.SetCaseInsensitive
+func WithCaseInsensitiveColumns() Option {
+	return func(db *DB) error {
+		db.read.foldCase = true
+		return nil
+	}
+}
+
+// Guard the values of the rows of the database with a mutex, so the getters, Values, MapScan
+// and Snapshot of rows may be called from several goroutines, such as workers the rows are fanned
+// out to, while one goroutine calls Next. Next, NextResultSet, Err and Close still belong to a
+// single goroutine.
+func WithConcurrentRows() Option {
+	return func(db *DB) error {
+		db.read.concurrent = true
+		return nil
+	}
+}
+
+// Fail the rows of the database with ErrDuplicateColumn when several columns have the same name,
+// rather than saving the later ones as name_2, name_3...
+func WithStrictColumns() Option {
+	return func(db *DB) error {
+		db.read.strictColumns = true
+		return nil
+	}
+}
+
+// Return the zero value from the typed getters of the database for NULL values, rather than
+// ErrInvalidColumnTypeConversion, see Rows.SetNullAsZero
+func WithNullAsZero() Option {
+	return func(db *DB) error {
+		db.read.nullAsZero = true
+		return nil
+	}
+}
+
+func (db *DB) apply(opts []Option) error {
+	for _, opt := range opts {
+		if err := opt(db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package ksql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyConnectionError(t *testing.T) {
+	defer Close()
+	testHosts.setDown("down", true)
+	defer testHosts.setDown("down", false)
+	_, err := New("hosts", "ksql_hosts", "down", WithVerifyConnection(time.Second))
+	if err == nil || err.Error() != `ksql: unable to connect to "hosts": host unreachable: down` {
+		t.Errorf("expected a connection error naming the database, got %v", err)
+	}
+	if _, ok := Get("hosts"); ok {
+		t.Errorf("a connection that failed verification should not be saved by name")
+	}
+}
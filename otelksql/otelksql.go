@@ -0,0 +1,130 @@
+// OpenTelemetry tracing for ksql. A Hook creates a client span for every statement and
+// transaction run through a ksql DB, Tx or Stmt, e.g.
+//
+//	ksql.AddHook(otelksql.NewHook(otelksql.WithDBSystem("postgresql")))
+//
+// or for a single named connection
+//
+//	ksql.New("master", "postgres", dsn, ksql.WithHook(otelksql.NewHook()))
+//
+// Statements run within a transaction through the methods without a context argument are
+// traced as children of the span of the transaction.
+package otelksql
+
+import (
+	"context"
+
+	"github.com/kahoon/ksql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/kahoon/ksql/otelksql"
+	defaultSystemName   = "other_sql"
+)
+
+// Attribute keys of the spans
+const (
+	DBSystemKey     = attribute.Key("db.system")
+	DBStatementKey  = attribute.Key("db.statement")
+	ConnectionKey   = attribute.Key("ksql.connection")
+	RowsAffectedKey = attribute.Key("db.rows_affected")
+	TxCompletionKey = attribute.Key("ksql.tx.completion")
+)
+
+// Hook creating a span per statement and transaction, see ksql.Hook
+type Hook struct {
+	tracer    trace.Tracer
+	system    string
+	statement func(query string) string
+}
+
+// Option configures a Hook
+type Option func(*Hook)
+
+// Use the tracer provider instead of the global one
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(h *Hook) {
+		h.tracer = tp.Tracer(instrumentationName)
+	}
+}
+
+// Set the db.system attribute, e.g. "postgresql" or "mysql"
+func WithDBSystem(system string) Option {
+	return func(h *Hook) {
+		h.system = system
+	}
+}
+
+// Record the statements with their literal values replaced by ?, see ksql.Fingerprint
+func WithRedactedStatements() Option {
+	return func(h *Hook) {
+		h.statement = ksql.Fingerprint
+	}
+}
+
+// Don't record the text of the statements at all
+func WithoutStatements() Option {
+	return func(h *Hook) {
+		h.statement = nil
+	}
+}
+
+// Create a tracing hook
+func NewHook(opts ...Option) *Hook {
+	h := &Hook{
+		tracer:    otel.GetTracerProvider().Tracer(instrumentationName),
+		system:    defaultSystemName,
+		statement: func(query string) string { return query },
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Span of an event, kept in the context between BeforeQuery and AfterQuery
+type spanKey struct{}
+
+type eventSpan struct {
+	event *ksql.QueryEvent
+	span  trace.Span
+}
+
+func (h *Hook) BeforeQuery(ctx context.Context, event *ksql.QueryEvent) (context.Context, error) {
+	attrs := []attribute.KeyValue{
+		DBSystemKey.String(h.system),
+		ConnectionKey.String(event.DB),
+	}
+	if h.statement != nil && event.Query != "" {
+		attrs = append(attrs, DBStatementKey.String(h.statement(event.Query)))
+	}
+	ctx, span := h.tracer.Start(ctx, "ksql."+event.Op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(event.Start),
+		trace.WithAttributes(attrs...),
+	)
+	return context.WithValue(ctx, spanKey{}, eventSpan{event, span}), nil
+}
+
+func (h *Hook) AfterQuery(ctx context.Context, event *ksql.QueryEvent) {
+	// the span is missing when an earlier hook stopped the statement
+	es, ok := ctx.Value(spanKey{}).(eventSpan)
+	if !ok || es.event != event {
+		return
+	}
+	if event.Op == ksql.OpTx && event.Query != "" {
+		es.span.SetAttributes(TxCompletionKey.String(event.Query))
+	}
+	if event.RowsAffected >= 0 {
+		es.span.SetAttributes(RowsAffectedKey.Int64(event.RowsAffected))
+	}
+	if event.Err != nil {
+		es.span.RecordError(event.Err)
+		es.span.SetStatus(codes.Error, event.Err.Error())
+	}
+	es.span.End(trace.WithTimestamp(event.End))
+}
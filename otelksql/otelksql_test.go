@@ -0,0 +1,88 @@
+package otelksql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kahoon/ksql"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func run(h *Hook, ctx context.Context, event *ksql.QueryEvent) context.Context {
+	event.Start = time.Now()
+	ctx, err := h.BeforeQuery(ctx, event)
+	if err != nil {
+		panic(err)
+	}
+	return ctx
+}
+
+func finish(h *Hook, ctx context.Context, event *ksql.QueryEvent, err error) {
+	event.End = time.Now()
+	event.Err = err
+	h.AfterQuery(ctx, event)
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestHook(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	h := NewHook(WithTracerProvider(tp), WithDBSystem("postgresql"), WithRedactedStatements())
+	tx := &ksql.QueryEvent{DB: "master", Op: ksql.OpTx, RowsAffected: -1}
+	txCtx := run(h, context.Background(), tx)
+	exec := &ksql.QueryEvent{DB: "master", Op: ksql.OpExec, Query: "update people set name = 'jd' where id = 1", RowsAffected: -1}
+	ctx := run(h, txCtx, exec)
+	exec.RowsAffected = 1
+	finish(h, ctx, exec, nil)
+	query := &ksql.QueryEvent{DB: "master", Op: ksql.OpQuery, Query: "select * from missing", RowsAffected: -1}
+	ctx = run(h, txCtx, query)
+	finish(h, ctx, query, errors.New("relation does not exist"))
+	// an event whose BeforeQuery was never called must not end the span of the transaction
+	rejected := &ksql.QueryEvent{DB: "master", Op: ksql.OpExec, Query: "delete from people", RowsAffected: -1}
+	finish(h, txCtx, rejected, errors.New("rejected"))
+	tx.Query = "COMMIT"
+	finish(h, txCtx, tx, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	execSpan, querySpan, txSpan := spans[0], spans[1], spans[2]
+	if execSpan.Name() != "ksql.exec" || querySpan.Name() != "ksql.query" || txSpan.Name() != "ksql.tx" {
+		t.Errorf("expected exec, query and tx spans, got %s, %s and %s", execSpan.Name(), querySpan.Name(), txSpan.Name())
+	}
+	if execSpan.Parent().SpanID() != txSpan.SpanContext().SpanID() || querySpan.Parent().SpanID() != txSpan.SpanContext().SpanID() {
+		t.Errorf("expected the statements to be children of the transaction")
+	}
+	attrs := attributes(execSpan)
+	if attrs[DBSystemKey].AsString() != "postgresql" || attrs[ConnectionKey].AsString() != "master" {
+		t.Errorf("expected the system and connection name, got %v", attrs)
+	}
+	if got := attrs[DBStatementKey].AsString(); got != "update people set name = ? where id = ?" {
+		t.Errorf("expected a redacted statement, got %q", got)
+	}
+	if attrs[RowsAffectedKey].AsInt64() != 1 {
+		t.Errorf("expected 1 row affected, got %v", attrs[RowsAffectedKey])
+	}
+	if querySpan.Status().Code != codes.Error {
+		t.Errorf("expected the failed query to have an error status, got %v", querySpan.Status())
+	}
+	if _, ok := attributes(txSpan)[DBStatementKey]; ok {
+		t.Errorf("expected no statement on the transaction")
+	}
+	if attributes(txSpan)[TxCompletionKey].AsString() != "COMMIT" {
+		t.Errorf("expected the transaction to be committed, got %v", attributes(txSpan))
+	}
+}
@@ -0,0 +1,204 @@
+package ksql
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Number of rows of a page when Pagination.Size is not set
+const DefaultPageSize = 50
+
+// How the rows of a query are split into pages. Without OrderBy, pages are read with
+// LIMIT and OFFSET, and the query is expected to order its rows itself. With OrderBy,
+// pages are read with keyset pagination: the query is wrapped to seek past the last row
+// of the previous page, so the columns must be columns of the result that identify a row
+// together, e.g. "created_at", "id".
+type Pagination struct {
+	Size    int
+	OrderBy []string
+	Desc    bool
+}
+
+// Page of the rows of a query, along with the token of the next page, empty on the last one
+type Page struct {
+	Rows []map[string]interface{}
+	Next string
+}
+
+// Position of a page, encoded in the tokens
+type pageToken struct {
+	Offset int64         `json:"o,omitempty"`
+	Keys   []interface{} `json:"k,omitempty"`
+}
+
+// Read the page of the query following the token, or the first page when the token is empty
+func (db *DB) Paginate(query string, p Pagination, token string, args ...interface{}) (*Page, error) {
+	return db.PaginateContext(context.Background(), query, p, token, args...)
+}
+
+func (db *DB) PaginateContext(ctx context.Context, query string, p Pagination, token string, args ...interface{}) (*Page, error) {
+	if p.Size <= 0 {
+		p.Size = DefaultPageSize
+	}
+	var position pageToken
+	if token != "" {
+		var err error
+		if position, err = decodePageToken(token); err != nil {
+			return nil, err
+		}
+		if len(p.OrderBy) == 0 && position.Keys != nil || len(p.OrderBy) > 0 && len(position.Keys) != len(p.OrderBy) {
+			return nil, ErrInvalidPageToken
+		}
+	}
+	query, args = PageSQL(db.dialect, db.bind, query, p, position.Offset, position.Keys, args)
+	rows, err := db.QueryMapsContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	page := &Page{Rows: rows}
+	if len(rows) <= p.Size {
+		return page, nil
+	}
+	// one more row than the page was read, to know there is a next page
+	page.Rows = rows[:p.Size]
+	next := pageToken{Offset: position.Offset + int64(p.Size)}
+	if len(p.OrderBy) > 0 {
+		last := page.Rows[p.Size-1]
+		next = pageToken{Keys: make([]interface{}, len(p.OrderBy))}
+		for i, column := range p.OrderBy {
+			value, ok := last[column]
+			if !ok {
+				return nil, ErrColumnNotFound
+			}
+			if b, ok := value.([]byte); ok {
+				value = string(b)
+			}
+			next.Keys[i] = value
+		}
+	}
+	if page.Next, err = encodePageToken(next); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// Build the statement reading one page of the query, and one more row, starting at offset,
+// or after the keys of the last row when the pagination has OrderBy columns. The arguments
+// of the keys are appended to args.
+func PageSQL(dialect Dialect, style BindStyle, query string, p Pagination, offset int64, keys []interface{}, args []interface{}) (string, []interface{}) {
+	if p.Size <= 0 {
+		p.Size = DefaultPageSize
+	}
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	var b strings.Builder
+	if len(p.OrderBy) == 0 {
+		b.WriteString(query)
+		writeLimit(&b, dialect, p.Size+1, offset)
+		return b.String(), args
+	}
+	b.WriteString("SELECT * FROM (")
+	b.WriteString(query)
+	b.WriteString(") ksql_page")
+	op, order := " > ", ""
+	if p.Desc {
+		op, order = " < ", " DESC"
+	}
+	if len(keys) == len(p.OrderBy) {
+		// (a, b) > (x, y) spelled out as a > x OR (a = x AND b > y), for every dialect,
+		// with every key bound again in each comparison
+		args = args[:len(args):len(args)]
+		b.WriteString(" WHERE ")
+		for i := range p.OrderBy {
+			if i > 0 {
+				b.WriteString(" OR ")
+			}
+			b.WriteByte('(')
+			for j := 0; j <= i; j++ {
+				if j > 0 {
+					b.WriteString(" AND ")
+				}
+				b.WriteString(p.OrderBy[j])
+				if j < i {
+					b.WriteString(" = ")
+				} else {
+					b.WriteString(op)
+				}
+				args = append(args, keys[j])
+				style.placeholder(&b, len(args))
+			}
+			b.WriteByte(')')
+		}
+	}
+	b.WriteString(" ORDER BY ")
+	for i, column := range p.OrderBy {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(column)
+		b.WriteString(order)
+	}
+	writeLimit(&b, dialect, p.Size+1, 0)
+	return b.String(), args
+}
+
+// Write the clause limiting the rows in the syntax of the dialect, leaving the number of
+// rows unlimited when limit is negative
+func writeLimit(b *strings.Builder, dialect Dialect, limit int, offset int64) {
+	switch dialect {
+	case DialectSQLServer, DialectOracle:
+		b.WriteString(" OFFSET " + strconv.FormatInt(offset, 10) + " ROWS")
+		if limit >= 0 {
+			b.WriteString(" FETCH NEXT " + strconv.Itoa(limit) + " ROWS ONLY")
+		}
+	default:
+		switch {
+		case limit >= 0:
+			b.WriteString(" LIMIT " + strconv.Itoa(limit))
+		case offset > 0 && dialect == DialectSQLite:
+			// an OFFSET needs a LIMIT, which is unlimited when negative
+			b.WriteString(" LIMIT -1")
+		case offset > 0 && dialect == DialectMySQL:
+			// an OFFSET needs a LIMIT, the largest one stands for all rows
+			b.WriteString(" LIMIT 18446744073709551615")
+		}
+		if offset > 0 {
+			b.WriteString(" OFFSET " + strconv.FormatInt(offset, 10))
+		}
+	}
+}
+
+func encodePageToken(token pageToken) (string, error) {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodePageToken(token string) (pageToken, error) {
+	var position pageToken
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return position, ErrInvalidPageToken
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&position); err != nil || position.Offset < 0 {
+		return position, ErrInvalidPageToken
+	}
+	// integers are kept exact, other numbers are bound as floats
+	for i, key := range position.Keys {
+		if n, ok := key.(json.Number); ok {
+			if v, err := n.Int64(); err == nil {
+				position.Keys[i] = v
+			} else if v, err := n.Float64(); err == nil {
+				position.Keys[i] = v
+			}
+		}
+	}
+	return position, nil
+}
@@ -0,0 +1,76 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestPageSQL(t *testing.T) {
+	query, args := PageSQL(DialectPostgres, BindDollar, "select * from people order by id;", Pagination{Size: 10}, 20, nil, []interface{}{true})
+	if query != "select * from people order by id LIMIT 11 OFFSET 20" || len(args) != 1 {
+		t.Errorf("unexpected offset page %q %v", query, args)
+	}
+	query, _ = PageSQL(DialectSQLServer, BindAt, "select * from people order by id", Pagination{Size: 10}, 0, nil, nil)
+	if query != "select * from people order by id OFFSET 0 ROWS FETCH NEXT 11 ROWS ONLY" {
+		t.Errorf("unexpected sql server page %q", query)
+	}
+	p := Pagination{Size: 2, OrderBy: []string{"created", "id"}, Desc: true}
+	query, args = PageSQL(DialectPostgres, BindDollar, "select * from people where married = $1", p, 0, []interface{}{"2024-01-01", int64(7)}, []interface{}{true})
+	want := "SELECT * FROM (select * from people where married = $1) ksql_page WHERE (created < $2) OR (created = $3 AND id < $4) ORDER BY created DESC, id DESC LIMIT 3"
+	if query != want {
+		t.Errorf("expected %q, got %q", want, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{true, "2024-01-01", "2024-01-01", int64(7)}) {
+		t.Errorf("unexpected arguments %v", args)
+	}
+	query, _ = PageSQL(DialectMySQL, BindQuestion, "select * from people", Pagination{OrderBy: []string{"id"}}, 0, nil, nil)
+	if query != "SELECT * FROM (select * from people) ksql_page ORDER BY id LIMIT 51" {
+		t.Errorf("unexpected first keyset page %q", query)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	defer Close()
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := func(id int64) []driver.Value { return []driver.Value{id, "name"} }
+	testHosts.setResult("select people LIMIT 3", []string{"id", "name"}, row(1), row(2), row(3))
+	page, err := db.Paginate("select people", Pagination{Size: 2}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Rows) != 2 || page.Next == "" {
+		t.Fatalf("expected a full page and a next one, got %d rows and %q", len(page.Rows), page.Next)
+	}
+	testHosts.setResult("select people LIMIT 3 OFFSET 2", []string{"id", "name"}, row(3))
+	page, err = db.Paginate("select people", Pagination{Size: 2}, page.Next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Rows) != 1 || page.Next != "" {
+		t.Errorf("expected the last page, got %d rows and %q", len(page.Rows), page.Next)
+	}
+	keyset := Pagination{Size: 2, OrderBy: []string{"id"}}
+	testHosts.setResult("SELECT * FROM (select people) ksql_page ORDER BY id LIMIT 3", []string{"id", "name"}, row(1), row(2), row(3))
+	page, err = db.Paginate("select people", keyset, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	position, err := decodePageToken(page.Next)
+	if err != nil || !reflect.DeepEqual(position.Keys, []interface{}{int64(2)}) {
+		t.Errorf("expected the token to seek past id 2, got %v %v", position.Keys, err)
+	}
+	if _, err := db.Paginate("select people", Pagination{Size: 2}, page.Next); err != ErrInvalidPageToken {
+		t.Errorf("expected a keyset token to be refused for offset pages, got %v", err)
+	}
+	if _, err := db.Paginate("select people", keyset, "not a token!"); err != ErrInvalidPageToken {
+		t.Errorf("expected an invalid token, got %v", err)
+	}
+	testHosts.setResult("SELECT * FROM (select people) ksql_page ORDER BY missing LIMIT 3", []string{"id", "name"}, row(1), row(2), row(3))
+	if _, err := db.Paginate("select people", Pagination{Size: 2, OrderBy: []string{"missing"}}, ""); err != ErrColumnNotFound {
+		t.Errorf("expected the order column not to be found, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestRelease(t *testing.T) {
+	defer Close()
+	sqldb, err := sql.Open("ksql_hosts", "shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqldb.Close()
+	if _, err := NewWithDB("shared", sqldb); err != nil {
+		t.Fatal(err)
+	}
+	db, ok := Release("shared")
+	if !ok || db.DB != sqldb {
+		t.Fatal("expected the released connection")
+	}
+	if _, ok := Get("shared"); ok {
+		t.Errorf("expected the name to be removed")
+	}
+	Close()
+	if err := sqldb.Ping(); err != nil {
+		t.Errorf("expected the released database to stay open, got %v", err)
+	}
+	if _, ok := Release("shared"); ok {
+		t.Errorf("expected nothing to release")
+	}
+	if _, err := NewWithDB("shared", sqldb); err != nil {
+		t.Errorf("expected the name to be free again, got %v", err)
+	}
+}
+
+// Connector of the hosts driver whose Close fails
+type failingCloseConnector struct{}
+
+func (failingCloseConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return testHosts.Open("failing close")
+}
+
+func (failingCloseConnector) Driver() driver.Driver { return testHosts }
+func (failingCloseConnector) Close() error          { return errors.New("close failed") }
+
+func TestCloseAll(t *testing.T) {
+	defer Close()
+	if _, err := New("good", "ksql_hosts", "good"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewWithDB("bad", sql.OpenDB(failingCloseConnector{})); err != nil {
+		t.Fatal(err)
+	}
+	errs := CloseAll()
+	if len(errs) != 1 || errs["bad"] == nil || errs["bad"].Error() != "close failed" {
+		t.Errorf("expected the bad connection to fail, got %v", errs)
+	}
+	if names := Databases(); len(names) != 0 {
+		t.Errorf("expected every connection to be removed, got %v", names)
+	}
+	if errs := CloseAll(); errs != nil {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
+
+func TestCloseByName(t *testing.T) {
+	defer Close()
+	if _, err := New("tenant-a", "ksql_hosts", "tenant-a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New("tenant-b", "ksql_hosts", "tenant-b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := CloseByName("tenant-a"); err != nil {
+		t.Fatal(err)
+	}
+	if names := Databases(); len(names) != 1 || names[0] != "tenant-b" {
+		t.Errorf("expected only tenant-b to remain, got %v", names)
+	}
+	if err := CloseByName("tenant-a"); err != ErrConnNotFound {
+		t.Errorf("expected the connection not to be found, got %v", err)
+	}
+	if _, err := NewWithDB("bad", sql.OpenDB(failingCloseConnector{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := CloseByName("bad"); err == nil {
+		t.Errorf("expected the close error")
+	}
+	if _, ok := Get("bad"); ok {
+		t.Errorf("expected the failing connection to be removed")
+	}
+}
@@ -0,0 +1,136 @@
+// Postgres LISTEN/NOTIFY subscriptions for ksql, on connections of their own made with
+// lib/pq, e.g.
+//
+//	l, err := pqksql.NewListener("master", "orders_changed")
+//
+// It is a package of its own so that ksql does not depend on a postgres driver.
+package pqksql
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kahoon/ksql"
+	"github.com/lib/pq"
+)
+
+// Bounds of the delay between reconnection attempts of a Listener
+const (
+	DefaultListenerMinReconnect = time.Second
+	DefaultListenerMaxReconnect = time.Minute
+)
+
+// Interval of the pings checking the connection of an idle Listener
+const listenerPingInterval = 90 * time.Second
+
+// Notification received by a Listener. After a reconnection, a notification with
+// Reconnected set and no channel is delivered, since notifications may have been missed
+// while disconnected.
+type Notification struct {
+	Channel     string
+	Payload     string
+	PID         int // process of the notifying backend
+	Reconnected bool
+}
+
+// Subscription to postgres LISTEN/NOTIFY channels of a named database connection, on a
+// connection of its own. The connection is reestablished when lost, and the channels
+// listened to again.
+type Listener struct {
+	listener      *pq.Listener
+	notifications chan Notification
+	stop          chan struct{}
+	once          sync.Once
+	done          chan struct{}
+}
+
+// Listen to the channels of the postgres database connection saved by name, or fail with
+// ksql.ErrConnNotFound. More channels can be added later with Listen.
+func NewListener(name string, channels ...string) (*Listener, error) {
+	db, ok := ksql.Get(name)
+	if !ok {
+		return nil, ksql.ErrConnNotFound
+	}
+	return NewDBListener(db, channels...)
+}
+
+// Listen to the channels of the postgres database, see NewListener. The database must have
+// been opened by ksql.New, with a data source name, or it fails with ksql.ErrNoDataSource.
+func NewDBListener(db *ksql.DB, channels ...string) (*Listener, error) {
+	if db.Dialect() != ksql.DialectPostgres {
+		return nil, ksql.ErrUnsupportedDialect
+	}
+	dsn := db.DataSourceName()
+	if dsn == "" {
+		return nil, ksql.ErrNoDataSource
+	}
+	l := &Listener{
+		listener:      pq.NewListener(dsn, DefaultListenerMinReconnect, DefaultListenerMaxReconnect, nil),
+		notifications: make(chan Notification, 32),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, channel := range channels {
+		if err := l.listener.Listen(channel); err != nil {
+			l.listener.Close()
+			return nil, err
+		}
+	}
+	go l.run()
+	return l, nil
+}
+
+// Forward the notifications of the connection, pinging it while idle
+func (l *Listener) run() {
+	defer close(l.done)
+	defer close(l.notifications)
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			go l.listener.Ping()
+		case n, ok := <-l.listener.Notify:
+			if !ok {
+				return
+			}
+			notification := Notification{Reconnected: true}
+			if n != nil {
+				notification = Notification{Channel: n.Channel, Payload: n.Extra, PID: n.BePid}
+			}
+			select {
+			case l.notifications <- notification:
+			case <-l.stop:
+				return
+			}
+		}
+	}
+}
+
+// Get the notifications of the channels listened to, closed once the listener is closed
+func (l *Listener) Notifications() <-chan Notification {
+	return l.notifications
+}
+
+// Listen to one more channel
+func (l *Listener) Listen(channel string) error {
+	return l.listener.Listen(channel)
+}
+
+// Stop listening to the channel
+func (l *Listener) Unlisten(channel string) error {
+	return l.listener.Unlisten(channel)
+}
+
+// Stop listening to every channel, and close the connection and the notifications
+func (l *Listener) Close() error {
+	var err error
+	l.once.Do(func() {
+		close(l.stop)
+		err = l.listener.Close()
+		<-l.done
+	})
+	return err
+}
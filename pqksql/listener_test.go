@@ -0,0 +1,74 @@
+package pqksql
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kahoon/ksql"
+	"github.com/kahoon/ksql/ksqltest"
+)
+
+func getPGHost() string {
+	host := os.Getenv("PGHOST")
+	if host == "" {
+		host = "192.168.1.10"
+	}
+	return host
+}
+
+func TestNewListenerUnsupported(t *testing.T) {
+	defer ksql.Close()
+	if _, err := NewListener("missing", "events"); err != ksql.ErrConnNotFound {
+		t.Errorf("expected the connection not to be found, got %v", err)
+	}
+	if _, _, err := ksqltest.NewMock("hosts"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewListener("hosts", "events"); err != ksql.ErrUnsupportedDialect {
+		t.Errorf("expected an unsupported dialect, got %v", err)
+	}
+	sqldb, err := sql.Open("postgres", "postgres://localhost/adopted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := ksql.NewWithDB("adopted", sqldb, ksql.WithDialect(ksql.DialectPostgres))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewDBListener(db, "events"); err != ksql.ErrNoDataSource {
+		t.Errorf("expected no data source name, got %v", err)
+	}
+}
+
+func TestDBListener(t *testing.T) {
+	db, err := ksql.New("test", "postgres", fmt.Sprintf("postgres://postgres:postgres@%s/test?sslmode=disable", getPGHost()), ksql.WithPingOnOpen())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ksql.Close()
+	l, err := NewListener("test", "people_changed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if _, err := db.Exec("select pg_notify('people_changed', '1')"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case n := <-l.Notifications():
+		if n.Channel != "people_changed" || n.Payload != "1" {
+			t.Errorf("expected the notification of people_changed, got %+v", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a notification")
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-l.Notifications(); ok {
+		t.Errorf("expected the notifications to be closed")
+	}
+}
@@ -0,0 +1,162 @@
+package ksql
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var queryNameMarker = regexp.MustCompile(`^--\s*name:\s*(\S+)\s*$`)
+
+// Registry of SQL queries by name, loaded from .sql files where each query follows a
+// "-- name: GetUser" comment line
+type Queries struct {
+	queries map[string]string
+}
+
+// Load the queries of the files of fsys matching the patterns, such as "sql/*.sql" of an
+// embed.FS. A name used twice is an error.
+func LoadQueries(fsys fs.FS, patterns ...string) (*Queries, error) {
+	q := &Queries{queries: make(map[string]string)}
+	for _, pattern := range patterns {
+		files, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			f, err := fsys.Open(file)
+			if err != nil {
+				return nil, err
+			}
+			err = q.parse(f, file)
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return q, nil
+}
+
+// Load the queries of a single .sql file, see LoadQueries
+func ParseQueries(r io.Reader) (*Queries, error) {
+	q := &Queries{queries: make(map[string]string)}
+	if err := q.parse(r, "queries"); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (q *Queries) parse(r io.Reader, file string) error {
+	var name string
+	var b strings.Builder
+	flush := func() error {
+		if name == "" {
+			return nil
+		}
+		if _, dup := q.queries[name]; dup {
+			return fmt.Errorf("ksql: duplicate query name %q in %s", name, file)
+		}
+		q.queries[name] = strings.TrimSuffix(strings.TrimSpace(b.String()), ";")
+		b.Reset()
+		return nil
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := queryNameMarker.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			if err := flush(); err != nil {
+				return err
+			}
+			name = m[1]
+			continue
+		}
+		if name != "" {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// Get the query by name
+func (q *Queries) Get(name string) (string, bool) {
+	query, ok := q.queries[name]
+	return query, ok
+}
+
+// Get the names of the queries, sorted
+func (q *Queries) Names() []string {
+	names := make([]string, 0, len(q.queries))
+	for name := range q.queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run the queries of the registry by name on the database, see DB.QueryNamed
+func WithQueries(q *Queries) Option {
+	return func(db *DB) error {
+		db.queries = q
+		return nil
+	}
+}
+
+// Get the query of the registry of the database by name
+func (db *DB) namedQuery(name string) (string, error) {
+	if db.queries != nil {
+		if query, ok := db.queries.Get(name); ok {
+			return query, nil
+		}
+	}
+	return "", ErrQueryNotFound
+}
+
+// Run the query of the registry set by WithQueries by name
+func (db *DB) QueryNamed(name string, args ...interface{}) (*Rows, error) {
+	return db.QueryNamedContext(context.Background(), name, args...)
+}
+
+func (db *DB) QueryNamedContext(ctx context.Context, name string, args ...interface{}) (*Rows, error) {
+	query, err := db.namedQuery(name)
+	if err != nil {
+		return nil, err
+	}
+	return db.QueryContext(ctx, query, args...)
+}
+
+// Run the query of the registry by name, for a single row
+func (db *DB) QueryRowNamed(name string, args ...interface{}) *Row {
+	return db.QueryRowNamedContext(context.Background(), name, args...)
+}
+
+func (db *DB) QueryRowNamedContext(ctx context.Context, name string, args ...interface{}) *Row {
+	query, err := db.namedQuery(name)
+	if err != nil {
+		return &Row{err: err}
+	}
+	return db.QueryRowContext(ctx, query, args...)
+}
+
+// Execute the statement of the registry by name
+func (db *DB) ExecNamedQuery(name string, args ...interface{}) (sql.Result, error) {
+	return db.ExecNamedQueryContext(context.Background(), name, args...)
+}
+
+func (db *DB) ExecNamedQueryContext(ctx context.Context, name string, args ...interface{}) (sql.Result, error) {
+	query, err := db.namedQuery(name)
+	if err != nil {
+		return nil, err
+	}
+	return db.ExecContext(ctx, query, args...)
+}
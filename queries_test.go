@@ -0,0 +1,79 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadQueries(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/users.sql": {Data: []byte(`-- users of the application
+-- name: GetUser
+select id, name
+from users
+where id = $1;
+
+--name:ListUsers
+select id, name from users
+`)},
+		"sql/orders.sql": {Data: []byte("-- name: DeleteOrder\ndelete from orders where id = $1\n")},
+		"sql/notes.txt":  {Data: []byte("-- name: Ignored\nselect 1\n")},
+	}
+	q, err := LoadQueries(fsys, "sql/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := q.Names(); strings.Join(names, ",") != "DeleteOrder,GetUser,ListUsers" {
+		t.Errorf("expected three queries, got %v", names)
+	}
+	query, ok := q.Get("GetUser")
+	if !ok || query != "select id, name\nfrom users\nwhere id = $1" {
+		t.Errorf("expected the query without the trailing semicolon, got %q", query)
+	}
+	if _, ok := q.Get("Ignored"); ok {
+		t.Errorf("expected only the .sql files to be loaded")
+	}
+	fsys["sql/more.sql"] = &fstest.MapFile{Data: []byte("-- name: GetUser\nselect 1\n")}
+	if _, err := LoadQueries(fsys, "sql/*.sql"); err == nil {
+		t.Errorf("expected the duplicate name to fail")
+	}
+}
+
+func TestQueryNamed(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select name from people", []string{"name"}, []driver.Value{"jane doe"})
+	q, err := ParseQueries(strings.NewReader("-- name: GetName\nselect name from people\n-- name: Fail\nfail\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := New("hosts", "ksql_hosts", "hosts", WithQueries(q))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.QueryNamed("GetName")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatal("expected a row")
+	}
+	if name, _ := rows.GetString("name"); name != "jane doe" {
+		t.Errorf("expected jane doe, got %q", name)
+	}
+	var name string
+	if err := db.QueryRowNamed("GetName").Scan(&name); err != nil || name != "jane doe" {
+		t.Errorf("expected jane doe, got %q %v", name, err)
+	}
+	if _, err := db.ExecNamedQuery("Fail"); err == nil {
+		t.Errorf("expected the statement error")
+	}
+	if _, err := db.QueryNamed("Missing"); err != ErrQueryNotFound {
+		t.Errorf("expected the query not to be found, got %v", err)
+	}
+	if err := db.QueryRowNamed("Missing").Scan(&name); err != ErrQueryNotFound {
+		t.Errorf("expected the query not to be found, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package ksql
+
+import (
+	"fmt"
+	"time"
+)
+
+// Getters of a row that record the first error instead of returning it, and return zero
+// values from then on. Check Err once all the columns are read.
+type RowReader struct {
+	rows *Rows
+	err  error
+}
+
+// Read the current row, recording the first error, see RowReader
+func (rs *Rows) Reader() *RowReader {
+	return &RowReader{rows: rs}
+}
+
+// Read the row, recording the first error, see RowReader
+func (r *Row) Reader() *RowReader {
+	err := next(r)
+	return &RowReader{rows: r.rows, err: err}
+}
+
+// Read the row, recording the first error, see RowReader
+func (v *RowView) Reader() *RowReader {
+	return &RowReader{rows: v.rows}
+}
+
+// Get the first error met reading the row
+func (rr *RowReader) Err() error {
+	return rr.err
+}
+
+func read[T any](rr *RowReader, column string, get func(string) (T, error)) T {
+	var zero T
+	if rr.err != nil {
+		return zero
+	}
+	value, err := get(column)
+	if err != nil {
+		rr.err = fmt.Errorf("ksql: column %q: %w", column, err)
+		return zero
+	}
+	return value
+}
+
+// Check whether the value in this row is NULL by column name
+func (rr *RowReader) IsNull(column string) bool {
+	return read(rr, column, func(column string) (bool, error) { return rr.rows.IsNull(column) })
+}
+
+// Get the boolean value in this row by column name
+func (rr *RowReader) GetBoolean(column string) bool {
+	return read(rr, column, func(column string) (bool, error) { return rr.rows.GetBoolean(column) })
+}
+
+// Get the integer value in this row by column name
+func (rr *RowReader) GetInteger(column string) int64 {
+	return read(rr, column, func(column string) (int64, error) { return rr.rows.GetInteger(column) })
+}
+
+// Get the float value in this row by column name
+func (rr *RowReader) GetDouble(column string) float64 {
+	return read(rr, column, func(column string) (float64, error) { return rr.rows.GetDouble(column) })
+}
+
+// Get the string value in this row by column name
+func (rr *RowReader) GetString(column string) string {
+	return read(rr, column, func(column string) (string, error) { return rr.rows.GetString(column) })
+}
+
+// Get a copy of the binary value in this row by column name
+func (rr *RowReader) GetBytes(column string) []byte {
+	return read(rr, column, func(column string) ([]byte, error) { return rr.rows.GetBytes(column) })
+}
+
+// Get the time.Time value in this row by column name
+func (rr *RowReader) GetTime(column string) time.Time {
+	return read(rr, column, func(column string) (time.Time, error) { return rr.rows.GetTime(column) })
+}
+
+// Get the date value in this row by column name
+func (rr *RowReader) GetDate(column string) time.Time {
+	return read(rr, column, func(column string) (time.Time, error) { return rr.rows.GetDate(column) })
+}
+
+// Get the duration value in this row by column name
+func (rr *RowReader) GetDuration(column string) time.Duration {
+	return read(rr, column, func(column string) (time.Duration, error) { return rr.rows.GetDuration(column) })
+}
+
+// Get the UUID value in this row by column name
+func (rr *RowReader) GetUUID(column string) UUID {
+	return read(rr, column, func(column string) (UUID, error) { return rr.rows.GetUUID(column) })
+}
+
+// Get the exact numeric value in this row by column name
+func (rr *RowReader) GetDecimal(column string) string {
+	return read(rr, column, func(column string) (string, error) { return rr.rows.GetDecimal(column) })
+}
+
+// Decode the JSON value in this row by column name into dest
+func (rr *RowReader) GetJSON(column string, dest interface{}) {
+	read(rr, column, func(column string) (struct{}, error) { return struct{}{}, rr.rows.GetJSON(column, dest) })
+}
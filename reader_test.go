@@ -0,0 +1,38 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestRowReader(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select person", []string{"id", "name", "married", "ratio"},
+		[]driver.Value{int64(1), "john doe", true, 3.14})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := db.QueryRow("select person").Reader()
+	id, name, married, ratio := r.GetInteger("id"), r.GetString("name"), r.GetBoolean("married"), r.GetDouble("ratio")
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 || name != "john doe" || !married || ratio != 3.14 {
+		t.Errorf("expected 1, \"john doe\", true and 3.14, got %v %v %v %v", id, name, married, ratio)
+	}
+	r = db.QueryRow("select person").Reader()
+	id = r.GetInteger("name")
+	name = r.GetString("name")
+	if id != 0 || name != "" {
+		t.Errorf("expected zero values after an error, got %v %q", id, name)
+	}
+	if err := r.Err(); !errors.Is(err, ErrInvalidColumnTypeConversion) || err.Error() != `ksql: column "name": `+ErrInvalidColumnTypeConversion.Error() {
+		t.Errorf("expected the first error, got %v", err)
+	}
+	r = db.QueryRow("fail").Reader()
+	if r.GetString("name") != "" || r.Err() == nil {
+		t.Errorf("expected the query error, got %v", r.Err())
+	}
+}
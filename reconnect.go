@@ -0,0 +1,108 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+)
+
+// Source of the data source name of a database, called again to reconnect once the database
+// can't be reached, so a moved host or rotated credentials are picked up
+type DSNSource func(ctx context.Context) (dsn string, err error)
+
+// Open a database connection whose data source name is fetched from source, and save the
+// reference by name. When a query or exec fails to reach the database, the data source name
+// is fetched again for the new connections, and the statement is run once more after a delay
+// of the backoff policy. The delay grows as the failures repeat, and is reset by a success.
+// Failures are told apart by the Retryable of the policy, or as connection failures, and its
+// MaxAttempts is not used.
+func NewWithReconnect(name, driverName string, source DSNSource, backoff RetryPolicy, opts ...Option) (*DB, error) {
+	if _, dup := lookup(name); dup {
+		return nil, ErrDupConnName
+	}
+	probe, err := sql.Open(driverName, "")
+	if err != nil {
+		return nil, err
+	}
+	d := probe.Driver()
+	probe.Close()
+	if backoff.Retryable == nil {
+		backoff.Retryable = connectionFailure
+	}
+	connector := &reconnector{driver: d, source: source, backoff: backoff}
+	sqldb := sql.OpenDB(connector)
+	db := &DB{DB: sqldb, name: name, bind: bindStyleFor(driverName), dialect: dialectFor(driverName), reconnect: connector}
+	if err := db.apply(opts); err != nil {
+		sqldb.Close()
+		return nil, err
+	}
+	if err := register(name, db); err != nil {
+		sqldb.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+type reconnector struct {
+	driver  driver.Driver
+	source  DSNSource
+	backoff RetryPolicy
+
+	mu sync.Mutex
+	// connector of the current data source name, nil to fetch it again
+	connector driver.Connector
+	// failures to reach the database in a row
+	failures int
+}
+
+// Connect to the current data source name, fetching it first when needed
+func (c *reconnector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.mu.Lock()
+	connector := c.connector
+	c.mu.Unlock()
+	if connector == nil {
+		dsn, err := c.source(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if connector, err = dsnConnector(c.driver, dsn); err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.connector = connector
+		c.mu.Unlock()
+	}
+	return connector.Connect(ctx)
+}
+
+func (c *reconnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Forget the data source name after a failure to reach the database, and wait before the
+// statement is run again, longer as the failures repeat. It reports false when the context
+// ends first.
+func (c *reconnector) reset(ctx context.Context) bool {
+	c.mu.Lock()
+	c.connector = nil
+	c.failures++
+	failures := c.failures
+	c.mu.Unlock()
+	return c.backoff.wait(ctx, failures)
+}
+
+// Run fn, and once more after reconnecting when it failed to reach the database
+func (db *DB) reconnecting(ctx context.Context, fn func() error) error {
+	c := db.reconnect
+	err := fn()
+	if err != nil && c.backoff.Retryable(err) && c.reset(ctx) {
+		err = fn()
+	}
+	if err == nil {
+		c.mu.Lock()
+		c.failures = 0
+		c.mu.Unlock()
+	}
+	return err
+}
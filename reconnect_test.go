@@ -0,0 +1,65 @@
+package ksql
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReconnect(t *testing.T) {
+	defer Close()
+	hosts := []string{"reconnect old", "reconnect new"}
+	fetched := 0
+	source := func(ctx context.Context) (string, error) {
+		dsn := hosts[fetched%len(hosts)]
+		fetched++
+		return dsn, nil
+	}
+	unreachable := func(err error) bool { return strings.HasPrefix(err.Error(), "host unreachable") }
+	db, err := NewWithReconnect("reconnect", "ksql_hosts", source,
+		RetryPolicy{BaseDelay: time.Millisecond, Retryable: unreachable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testHosts.setDown("reconnect old", true)
+	defer testHosts.setDown("reconnect old", false)
+	if _, err := db.Exec("update people"); err != nil {
+		t.Fatalf("expected the exec to reach the new host, got %v", err)
+	}
+	if fetched != 2 {
+		t.Errorf("expected the data source name to be fetched again, got %d fetches", fetched)
+	}
+	rows, err := db.Query("select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if fetched != 2 {
+		t.Errorf("expected the data source name to be kept once connected, got %d fetches", fetched)
+	}
+
+	// the delay grows as the failures repeat, and a success resets it
+	testHosts.setDown("reconnect new", true)
+	db.SetMaxIdleConns(0)
+	db.reconnect.connector = nil
+	hosts = []string{"reconnect new"}
+	for i := 0; i < 3; i++ {
+		if _, err := db.Exec("update people"); err == nil {
+			t.Fatal("expected the exec to fail")
+		}
+	}
+	if db.reconnect.failures != 3 {
+		t.Errorf("expected 3 failures in a row, got %d", db.reconnect.failures)
+	}
+	testHosts.setDown("reconnect new", false)
+	if _, err := db.Exec("update people"); err != nil {
+		t.Fatal(err)
+	}
+	if db.reconnect.failures != 0 {
+		t.Errorf("expected the success to reset the failures, got %d", db.reconnect.failures)
+	}
+	if _, err := db.Exec("fail"); err == nil || db.reconnect.failures != 0 {
+		t.Errorf("expected no reconnect for a failed statement, got %d failures", db.reconnect.failures)
+	}
+}
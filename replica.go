@@ -0,0 +1,78 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+)
+
+// Where queries of a database with read replicas are sent
+type ReadPreference int
+
+const (
+	ReadPrimary ReadPreference = iota // all queries go to the primary
+	ReadReplica                       // Query and QueryRow go to the replicas, Exec and transactions to the primary
+)
+
+// Set where the queries of the database are sent, once replicas are registered with NewReplica
+func WithReadPreference(pref ReadPreference) Option {
+	return func(db *DB) error {
+		db.readPref = pref
+		return nil
+	}
+}
+
+// Open a read replica of the named primary database connection. The replica is not saved by
+// name, it is reached through the primary and closed along with it.
+func NewReplica(primary, driver, dsn string, opts ...Option) (*DB, error) {
+	db, ok := Get(primary)
+	if !ok {
+		return nil, ErrConnNotFound
+	}
+	sqldb, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	// replicas run the hooks of their primary, besides their own, and read values and limit statements like it
+	replica := &DB{DB: sqldb, name: primary, dsn: dsn, bind: bindStyleFor(driver), dialect: dialectFor(driver),
+		hooks: append([]Hook(nil), db.hooks...), slowThreshold: db.slowThreshold, read: db.read, txOptions: db.txOptions,
+		limit: db.limit.clone(), breaker: db.breaker.clone(), retry: db.retry, mapErrors: db.mapErrors, noPool: db.noPool,
+		used: db.used, comment: db.comment}
+	if err := replica.apply(opts); err != nil {
+		sqldb.Close()
+		return nil, err
+	}
+	db.mu.Lock()
+	db.replicas = append(db.replicas, replica)
+	db.mu.Unlock()
+	replica.logOpened()
+	return replica, nil
+}
+
+// Get the next read replica in round robin order, or the database itself when it has none
+func (db *DB) Replica() *DB {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if len(db.replicas) == 0 {
+		return db
+	}
+	n := atomic.AddUint32(&db.next, 1)
+	return db.replicas[int(n-1)%len(db.replicas)]
+}
+
+// Run the query on a read replica, or on the primary when there are none
+func (db *DB) QueryReplica(query string, args ...interface{}) (*Rows, error) {
+	return db.QueryReplicaContext(context.Background(), query, args...)
+}
+
+func (db *DB) QueryReplicaContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	return db.Replica().QueryContext(ctx, query, args...)
+}
+
+func (db *DB) QueryRowReplica(query string, args ...interface{}) *Row {
+	return db.QueryRowReplicaContext(context.Background(), query, args...)
+}
+
+func (db *DB) QueryRowReplicaContext(ctx context.Context, query string, args ...interface{}) *Row {
+	return db.Replica().QueryRowContext(ctx, query, args...)
+}
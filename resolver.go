@@ -0,0 +1,256 @@
+package ksql
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Opener of database connections on demand, for the names made of its prefix and an id, such
+// as "tenant:42". Get and Resolve open the connection of a name the first time it is asked
+// for, from the driver and data source name returned by Lookup, and save it by name. Only the
+// connections the resolver opened are closed by MaxOpen and IdleTimeout, and never while they
+// run statements or transactions or have open rows. The fields must be set before Register
+// is called.
+type Resolver struct {
+	Prefix string // prefix of the names, such as "tenant:"
+	// get the driver and data source name of the connection of an id
+	Lookup      func(ctx context.Context, id string) (driver, dsn string, err error)
+	MaxOpen     int           // most connections kept open, closing the least recently used, 0 for no limit
+	IdleTimeout time.Duration // close the connections not asked for this long, 0 to keep them
+	Options     []Option      // options of the connections
+
+	mu sync.Mutex
+	// names of the open connections, the most recently used first
+	lru     *list.List
+	entries map[string]*list.Element
+	pending map[string]*resolveCall
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// Connection of a resolver and when it was last asked for
+type resolvedConn struct {
+	name string
+	db   *DB
+	used time.Time
+}
+
+// Opening of a connection, waited for by the other callers asking for the same name
+type resolveCall struct {
+	done chan struct{}
+	db   *DB
+	err  error
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   []*Resolver
+)
+
+// Start resolving the names of the prefix, or fail with ErrDupConnName when the prefix is
+// already registered, and with ErrEmptyPrefix when it is empty
+func (r *Resolver) Register() error {
+	if r.Prefix == "" {
+		return ErrEmptyPrefix
+	}
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	for _, other := range resolvers {
+		if other.Prefix == r.Prefix {
+			return ErrDupConnName
+		}
+	}
+	r.mu.Lock()
+	r.lru, r.entries = list.New(), make(map[string]*list.Element)
+	r.pending = make(map[string]*resolveCall)
+	if r.IdleTimeout > 0 {
+		r.stop = make(chan struct{})
+		r.done = make(chan struct{})
+		go r.run(r.stop, r.done)
+	}
+	r.mu.Unlock()
+	resolvers = append(resolvers, r)
+	return nil
+}
+
+// Stop resolving the names of the prefix, and close the connections opened by the resolver,
+// returning the first error of the ones that failed to close
+func (r *Resolver) Unregister() error {
+	resolversMu.Lock()
+	for i, other := range resolvers {
+		if other == r {
+			resolvers = append(resolvers[:i], resolvers[i+1:]...)
+			break
+		}
+	}
+	resolversMu.Unlock()
+	r.mu.Lock()
+	stop, done := r.stop, r.done
+	r.stop, r.done = nil, nil
+	var conns []*resolvedConn
+	for _, e := range r.entries {
+		conns = append(conns, e.Value.(*resolvedConn))
+	}
+	r.lru, r.entries = list.New(), make(map[string]*list.Element)
+	r.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	return closeResolved(conns)
+}
+
+// Get the database connection saved by name, opening it through the resolver of its prefix
+// when it is not open yet. It fails with ErrConnNotFound when the name is not saved and no
+// resolver matches it.
+func Resolve(ctx context.Context, name string) (*DB, error) {
+	r := resolverFor(name)
+	// the use is recorded before the eviction can see the connection idle
+	poolMu.RLock()
+	db, ok := pool[name]
+	if ok {
+		db.markUsed()
+		if r != nil {
+			r.touch(name, db)
+		}
+	}
+	poolMu.RUnlock()
+	if ok {
+		return db, nil
+	}
+	if r == nil {
+		return nil, ErrConnNotFound
+	}
+	return r.open(ctx, name)
+}
+
+// Get the resolver whose prefix starts the name
+func resolverFor(name string) *Resolver {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	for _, r := range resolvers {
+		if strings.HasPrefix(name, r.Prefix) {
+			return r
+		}
+	}
+	return nil
+}
+
+// Open the connection of the name once, however many callers ask for it at the same time
+func (r *Resolver) open(ctx context.Context, name string) (*DB, error) {
+	r.mu.Lock()
+	if call, ok := r.pending[name]; ok {
+		r.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.db, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &resolveCall{done: make(chan struct{})}
+	r.pending[name] = call
+	r.mu.Unlock()
+
+	var opened bool
+	call.db, opened, call.err = r.connect(ctx, name)
+	r.mu.Lock()
+	delete(r.pending, name)
+	if opened {
+		r.entries[name] = r.lru.PushFront(&resolvedConn{name: name, db: call.db, used: time.Now()})
+	}
+	r.mu.Unlock()
+	close(call.done)
+	if opened && r.MaxOpen > 0 {
+		r.evict(r.MaxOpen, time.Time{})
+	}
+	return call.db, call.err
+}
+
+// Open the connection of the name, and report whether the resolver opened it rather than New
+func (r *Resolver) connect(ctx context.Context, name string) (*DB, bool, error) {
+	driver, dsn, err := r.Lookup(ctx, strings.TrimPrefix(name, r.Prefix))
+	if err != nil {
+		return nil, false, err
+	}
+	db, err := New(name, driver, dsn, r.Options...)
+	if err == ErrDupConnName {
+		// opened meanwhile by New, it is left to its owner
+		if db, ok := lookup(name); ok {
+			return db, false, nil
+		}
+	}
+	return db, err == nil, err
+}
+
+// Record the use of the connection of the name, when the resolver opened it
+func (r *Resolver) touch(name string, db *DB) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.entries[name]; ok && e.Value.(*resolvedConn).db == db {
+		e.Value.(*resolvedConn).used = time.Now()
+		r.lru.MoveToFront(e)
+	}
+}
+
+func (r *Resolver) run(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(r.IdleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.evict(0, time.Now().Add(-r.IdleTimeout))
+		}
+	}
+}
+
+// Close the least recently used connections until at most keep are left, when keep is not
+// zero, and the ones not asked for since the cutoff, when it is not zero. The connections
+// running statements or transactions, or with open rows, are kept. It is held by poolMu, so
+// Resolve can't hand them out meanwhile.
+func (r *Resolver) evict(keep int, cutoff time.Time) {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for e := r.lru.Back(); e != nil; {
+		prev := e.Prev()
+		conn := e.Value.(*resolvedConn)
+		over := keep > 0 && r.lru.Len() > keep
+		if !over && (cutoff.IsZero() || !conn.used.Before(cutoff)) {
+			// the connections closer to the front were used more recently
+			break
+		}
+		if conn.db.inUse() == 0 {
+			r.lru.Remove(e)
+			delete(r.entries, conn.name)
+			if pool[conn.name] == conn.db {
+				delete(pool, conn.name)
+			}
+			conn.db.close()
+		}
+		e = prev
+	}
+}
+
+// Close the connections and remove them, unless their names were taken by others meanwhile
+func closeResolved(conns []*resolvedConn) error {
+	var first error
+	for _, conn := range conns {
+		poolMu.Lock()
+		if pool[conn.name] == conn.db {
+			delete(pool, conn.name)
+		}
+		poolMu.Unlock()
+		if err := conn.db.close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
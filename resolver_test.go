@@ -0,0 +1,148 @@
+package ksql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResolver(t *testing.T) {
+	defer Close()
+	unknown := errors.New("unknown tenant")
+	var lookups int32
+	r := &Resolver{
+		Prefix: "tenant:",
+		Lookup: func(ctx context.Context, id string) (string, string, error) {
+			atomic.AddInt32(&lookups, 1)
+			if id == "unknown" {
+				return "", "", unknown
+			}
+			return "ksql_hosts", "tenant " + id, nil
+		},
+		MaxOpen: 2,
+	}
+	if err := r.Register(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Unregister()
+	if err := (&Resolver{Prefix: "tenant:"}).Register(); err != ErrDupConnName {
+		t.Errorf("expected the prefix to be taken, got %v", err)
+	}
+
+	// concurrent callers share the opening of a connection
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := Get("tenant:1"); !ok {
+				t.Error("expected the tenant to be resolved")
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&lookups); n != 1 {
+		t.Errorf("expected a single lookup, got %d", n)
+	}
+	db, ok := Get("tenant:1")
+	if !ok || db.Name() != "tenant:1" {
+		t.Fatal("expected the tenant to be saved by name")
+	}
+	if _, err := db.Exec("update people"); err != nil {
+		t.Error(err)
+	}
+	if _, err := Resolve(context.Background(), "tenant:unknown"); err != unknown {
+		t.Errorf("expected the error of the lookup, got %v", err)
+	}
+	if _, err := Resolve(context.Background(), "customer:1"); err != ErrConnNotFound {
+		t.Errorf("expected no resolver for the name, got %v", err)
+	}
+
+	// the least recently used tenant is closed beyond MaxOpen
+	Get("tenant:2")
+	Get("tenant:1")
+	Get("tenant:3")
+	if _, ok := lookup("tenant:2"); ok {
+		t.Error("expected the least recently used tenant to be closed")
+	}
+	if _, ok := lookup("tenant:1"); !ok {
+		t.Error("expected the recently used tenant to stay open")
+	}
+	if err := db.Ping(); err != nil {
+		t.Errorf("expected the open tenant to be usable, got %v", err)
+	}
+
+	// a tenant in use is kept open beyond MaxOpen
+	rows, err := db.Query("select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Get("tenant:3")
+	Get("tenant:4")
+	if _, ok := lookup("tenant:1"); !ok {
+		t.Error("expected the tenant in use to stay open")
+	}
+	if _, ok := lookup("tenant:3"); ok {
+		t.Error("expected the idle tenant to be closed instead")
+	}
+	rows.Close()
+
+	// connections opened by New are left to their owner
+	own, err := New("tenant:own", "ksql_hosts", "tenant own")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"own", "5", "6", "7"} {
+		Get("tenant:" + id)
+	}
+	if found, ok := lookup("tenant:own"); !ok || found != own {
+		t.Error("expected the connection opened by New not to be evicted")
+	}
+	if err := (&Resolver{}).Register(); err != ErrEmptyPrefix {
+		t.Errorf("expected an empty prefix to fail, got %v", err)
+	}
+
+	if err := r.Unregister(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := lookup("tenant:1"); ok {
+		t.Error("expected the tenants to be closed once unregistered")
+	}
+	if _, ok := Get("tenant:8"); ok {
+		t.Error("expected no resolving once unregistered")
+	}
+}
+
+func TestResolverIdleTimeout(t *testing.T) {
+	defer Close()
+	r := &Resolver{
+		Prefix: "idle:",
+		Lookup: func(ctx context.Context, id string) (string, string, error) {
+			return "ksql_hosts", "idle " + id, nil
+		},
+		IdleTimeout: 20 * time.Millisecond,
+	}
+	if err := r.Register(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Unregister()
+	if _, ok := Get("idle:1"); !ok {
+		t.Fatal("expected the connection to be resolved")
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := lookup("idle:1"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the idle connection to be closed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, ok := Get("idle:1"); !ok {
+		t.Error("expected the connection to be opened again")
+	}
+}
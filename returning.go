@@ -0,0 +1,58 @@
+package ksql
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var returningClause = regexp.MustCompile(`(?i)\bRETURNING\b`)
+
+// Execute an INSERT and get the integer key of the new row. Postgres reads the column from
+// a RETURNING clause, appended unless the statement has one, other databases use the
+// LastInsertId of the driver.
+func (db *DB) ExecReturningID(query, column string, args ...interface{}) (int64, error) {
+	return db.ExecReturningIDContext(context.Background(), query, column, args...)
+}
+
+func (db *DB) ExecReturningIDContext(ctx context.Context, query, column string, args ...interface{}) (int64, error) {
+	if db.dialect != DialectPostgres {
+		result, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return result.LastInsertId()
+	}
+	return db.QueryRowContext(ctx, returning(query, column), args...).GetIntegerAt(0)
+}
+
+// Execute an INSERT and get the key of the new row as text, such as a UUID, see
+// DB.ExecReturningID
+func (db *DB) ExecReturningKey(query, column string, args ...interface{}) (string, error) {
+	return db.ExecReturningKeyContext(context.Background(), query, column, args...)
+}
+
+func (db *DB) ExecReturningKeyContext(ctx context.Context, query, column string, args ...interface{}) (string, error) {
+	if db.dialect != DialectPostgres {
+		id, err := db.ExecReturningIDContext(ctx, query, column, args...)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(id, 10), nil
+	}
+	return db.QueryRowContext(ctx, returning(query, column), args...).GetStringAt(0)
+}
+
+// Append a RETURNING clause for the column, unless the statement outside its literals and
+// comments already has one
+func returning(query, column string) string {
+	if returningClause.MatchString(Fingerprint(query)) {
+		return query
+	}
+	if trimmed := strings.TrimRightFunc(query, unicode.IsSpace); strings.HasSuffix(trimmed, ";") {
+		query = strings.TrimSuffix(trimmed, ";")
+	}
+	return query + " RETURNING " + column
+}
@@ -0,0 +1,55 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestReturning(t *testing.T) {
+	tests := map[string]string{
+		"insert into people (name) values ($1)":                    "insert into people (name) values ($1) RETURNING id",
+		"insert into people (name) values ('returning') ":          "insert into people (name) values ('returning')  RETURNING id",
+		"insert into people (name) values ($1) returning id, name": "insert into people (name) values ($1) returning id, name",
+		"insert into people (name) values ($1);\n":                 "insert into people (name) values ($1) RETURNING id",
+		"insert into people (name) values ($1) -- returning\n":     "insert into people (name) values ($1) -- returning\n RETURNING id",
+	}
+	for query, want := range tests {
+		if got := returning(query, "id"); got != want {
+			t.Errorf("expected %q for %q, got %q", want, query, got)
+		}
+	}
+}
+
+func TestExecReturningID(t *testing.T) {
+	defer Close()
+	query := "insert into people (name) values ($1) RETURNING id"
+	testHosts.setResult(query, []string{"id"}, []driver.Value{int64(42)})
+	testHosts.setResult("insert into people (id, name) values (gen_random_uuid(), $1) RETURNING id", []string{"id"},
+		[]driver.Value{[]byte("6ba7b810-9dad-11d1-80b4-00c04fd430c8")})
+	db, err := New("hosts", "ksql_hosts", "hosts", WithDialect(DialectPostgres))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := db.ExecReturningID("insert into people (name) values ($1)", "id", "john doe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 42 {
+		t.Errorf("expected 42, got %d", id)
+	}
+	key, err := db.ExecReturningKey("insert into people (id, name) values (gen_random_uuid(), $1)", "id", "john doe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
+		t.Errorf("expected the uuid key, got %q", key)
+	}
+	// the results of the test driver have no last insert id
+	other, err := New("other", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.ExecReturningID("insert into people (name) values (?)", "id", "john doe"); err == nil {
+		t.Errorf("expected the LastInsertId error of the driver")
+	}
+}
@@ -0,0 +1,778 @@
+package ksql
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRowsTiming(t *testing.T) {
+	defer Close()
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("select * from people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.FirstRowTime().IsZero() || rows.RowsRead() != 0 {
+		t.Errorf("expected no rows read before Next")
+	}
+	for rows.Next() {
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if rows.RowsRead() != 1 {
+		t.Errorf("expected 1 row read, got %d", rows.RowsRead())
+	}
+	if rows.FirstRowTime().Before(rows.start) || rows.FirstRowTime().After(rows.end) {
+		t.Errorf("expected the first row between the start and end of the query")
+	}
+	d := rows.QueryDuration()
+	if d <= 0 || d != rows.end.Sub(rows.start) {
+		t.Errorf("expected the duration to stop at the end of the rows, got %v", d)
+	}
+	rows.Close()
+	if rows.QueryDuration() != d {
+		t.Errorf("expected closing to keep the duration, got %v", rows.QueryDuration())
+	}
+}
+
+func TestGetBytes(t *testing.T) {
+	defer Close()
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testHosts.setResult("select data", []string{"data", "empty", "id"}, []driver.Value{[]byte{1, 2, 3}, nil, int64(1)})
+	rows, err := db.Query("select data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatalf("expected a row of results, got none!")
+	}
+	data, err := rows.GetBytes("data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{1, 2, 3}) {
+		t.Errorf("expected [1 2 3] for \"data\", got %v", data)
+	}
+	data[0] = 9
+	if again, _ := rows.GetBytes("data"); again[0] != 1 {
+		t.Errorf("expected a copy of the bytes of \"data\"")
+	}
+	if empty, err := rows.GetBytes("empty"); err != nil || empty != nil {
+		t.Errorf("expected nil for NULL \"empty\", got %v %v", empty, err)
+	}
+	if _, err := rows.GetBytes("id"); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected a conversion error for \"id\", got %v", err)
+	}
+	name, err := db.QueryRow("select name").GetBytes("name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(name) != "john doe" {
+		t.Errorf("expected \"john doe\" for \"name\", got %q", name)
+	}
+}
+
+func TestGetJSON(t *testing.T) {
+	defer Close()
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testHosts.setResult("select settings", []string{"settings", "tags", "missing", "id"},
+		[]driver.Value{[]byte(`{"theme":"dark","size":12}`), `["a","b"]`, nil, int64(1)})
+	row := db.QueryRow("select settings")
+	var settings struct {
+		Theme string
+		Size  int
+	}
+	if err := row.GetJSON("settings", &settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings.Theme != "dark" || settings.Size != 12 {
+		t.Errorf("expected dark and 12 for \"settings\", got %+v", settings)
+	}
+	var tags []string
+	if err := row.GetJSON("tags", &tags); err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 || tags[0] != "a" || tags[1] != "b" {
+		t.Errorf("expected [a b] for \"tags\", got %v", tags)
+	}
+	missing := map[string]int{"kept": 1}
+	if err := row.GetJSON("missing", &missing); err != nil || missing != nil {
+		t.Errorf("expected NULL \"missing\" to decode as null, got %v %v", missing, err)
+	}
+	if err := row.GetJSON("id", &missing); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected a conversion error for \"id\", got %v", err)
+	}
+}
+
+func TestGetDecimal(t *testing.T) {
+	defer Close()
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testHosts.setResult("select price", []string{"price", "count", "ratio", "name", "fraction"},
+		[]driver.Value{[]byte("12345678901234567890.0123456789"), int64(3), 0.1, "john doe", "1/3"})
+	row := db.QueryRow("select price")
+	tests := map[string]string{"price": "12345678901234567890.0123456789", "count": "3", "ratio": "0.1"}
+	for column, want := range tests {
+		value, err := row.GetDecimal(column)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != want {
+			t.Errorf("expected %s for %q, got %s", want, column, value)
+		}
+	}
+	rat, err := row.GetRat("price")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rat.FloatString(10) != "12345678901234567890.0123456789" {
+		t.Errorf("expected an exact rational for \"price\", got %s", rat.FloatString(10))
+	}
+	for _, column := range []string{"name", "fraction"} {
+		if _, err := row.GetDecimal(column); err != ErrInvalidColumnTypeConversion {
+			t.Errorf("expected a conversion error for %q, got %v", column, err)
+		}
+	}
+}
+
+func TestGetDate(t *testing.T) {
+	defer Close()
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokyo := time.FixedZone("JST", 9*60*60)
+	testHosts.setResult("select born", []string{"time", "text", "bytes", "timestamp", "bad", "id"},
+		[]driver.Value{time.Date(2016, time.January, 2, 1, 0, 0, 0, tokyo), "2016-01-02", []byte("2016-01-02"),
+			"2016-01-02 03:04:05", "2016-01-02x", int64(1)})
+	row := db.QueryRow("select born")
+	want := time.Date(2016, time.January, 2, 0, 0, 0, 0, time.UTC)
+	for _, column := range []string{"time", "text", "bytes", "timestamp"} {
+		date, err := row.GetDate(column)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !date.Equal(want) || date.Location() != time.UTC {
+			t.Errorf("expected %v for %q, got %v", want, column, date)
+		}
+	}
+	for _, column := range []string{"bad", "id"} {
+		if _, err := row.GetDate(column); err != ErrInvalidColumnTypeConversion {
+			t.Errorf("expected a conversion error for %q, got %v", column, err)
+		}
+	}
+}
+
+func TestCaseInsensitiveColumns(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select upper", []string{"ID", "NAME", "Name", "name2"},
+		[]driver.Value{int64(1), "john doe", "jane doe", "baby doe"})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("select upper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatalf("expected a row of results, got none!")
+	}
+	if _, err := rows.GetInteger("id"); err != ErrColumnNotFound {
+		t.Errorf("expected exact column matches by default, got %v", err)
+	}
+	rows.SetCaseInsensitive(true)
+	id, err := rows.GetInteger("id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 {
+		t.Errorf("expected 1 for \"id\", got %d", id)
+	}
+	tests := map[string]string{"name": "john doe", "NAME": "john doe", "Name": "jane doe", "nAmE": "john doe", "NAME2": "baby doe"}
+	for column, want := range tests {
+		name, err := rows.GetString(column)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name != want {
+			t.Errorf("expected %q for %q, got %q", want, column, name)
+		}
+	}
+	folded, err := New("folded", "ksql_hosts", "hosts", WithCaseInsensitiveColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p struct {
+		ID   int64
+		Name string
+	}
+	if err := folded.QueryRow("select upper").ScanStruct(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != 1 || p.Name != "john doe" {
+		t.Errorf("expected 1 and \"john doe\" scanned regardless of case, got %+v", p)
+	}
+}
+
+func TestUniqueColumns(t *testing.T) {
+	tests := []struct {
+		columns, keys []string
+	}{
+		{[]string{"id", "name"}, []string{"id", "name"}},
+		{[]string{"id", "name", "id"}, []string{"id", "name", "id_2"}},
+		{[]string{"id", "id", "id"}, []string{"id", "id_2", "id_3"}},
+		{[]string{"id", "id", "id_2"}, []string{"id", "id_3", "id_2"}},
+	}
+	for _, test := range tests {
+		keys, dup := uniqueColumns(test.columns)
+		if !reflect.DeepEqual(keys, test.keys) {
+			t.Errorf("expected %v for %v, got %v", test.keys, test.columns, keys)
+		}
+		if dup != (len(test.columns) == 3) {
+			t.Errorf("expected duplicates to be reported for %v", test.columns)
+		}
+	}
+}
+
+func TestDuplicateColumns(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select join", []string{"id", "name", "id"}, []driver.Value{int64(1), "john doe", int64(2)})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := db.QueryRow("select join")
+	for column, want := range map[string]int64{"id": 1, "id_2": 2} {
+		id, err := row.GetInteger(column)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != want {
+			t.Errorf("expected %d for %q, got %d", want, column, id)
+		}
+	}
+	strict, err := New("strict", "ksql_hosts", "hosts", WithStrictColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := strict.QueryRow("select join").GetInteger("id"); err != ErrDuplicateColumn {
+		t.Errorf("expected a duplicate column error, got %v", err)
+	}
+	if _, err := strict.QueryRow("select people").GetInteger("id"); err != nil {
+		t.Errorf("expected unique columns to be read, got %v", err)
+	}
+}
+
+func TestIndexGetters(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select computed", []string{"?column?", "?column?", "?column?", "?column?", "?column?", "?column?"},
+		[]driver.Value{true, int64(42), 3.14, "john doe", time.Date(2016, time.January, 2, 3, 4, 5, 0, time.UTC), nil})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("select computed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if _, err := rows.GetIntegerAt(1); err != ErrNoRows {
+		t.Errorf("expected no rows before Next, got %v", err)
+	}
+	if !rows.Next() {
+		t.Fatalf("expected a row of results, got none!")
+	}
+	if v, err := rows.GetBooleanAt(0); err != nil || !v {
+		t.Errorf("expected true at 0, got %v %v", v, err)
+	}
+	if v, err := rows.GetIntegerAt(1); err != nil || v != 42 {
+		t.Errorf("expected 42 at 1, got %v %v", v, err)
+	}
+	if v, err := rows.GetDoubleAt(2); err != nil || v != 3.14 {
+		t.Errorf("expected 3.14 at 2, got %v %v", v, err)
+	}
+	if v, err := rows.GetStringAt(3); err != nil || v != "john doe" {
+		t.Errorf("expected \"john doe\" at 3, got %v %v", v, err)
+	}
+	if v, err := rows.GetTimeAt(4); err != nil || v.Year() != 2016 {
+		t.Errorf("expected 2016-01-02 at 4, got %v %v", v, err)
+	}
+	if null, err := rows.IsNullAt(5); err != nil || !null {
+		t.Errorf("expected NULL at 5, got %v %v", null, err)
+	}
+	if _, err := rows.GetStringAt(1); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected a conversion error at 1, got %v", err)
+	}
+	for _, i := range []int{-1, 6} {
+		if _, err := rows.GetStringAt(i); err != ErrColumnNotFound {
+			t.Errorf("expected column not found at %d, got %v", i, err)
+		}
+	}
+	if v, err := db.QueryRow("select computed").GetIntegerAt(1); err != nil || v != 42 {
+		t.Errorf("expected 42 at 1 of the row, got %v %v", v, err)
+	}
+}
+
+func TestNullAsZero(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select nulls", []string{"flag", "count", "ratio", "name", "modified"},
+		[]driver.Value{nil, nil, nil, nil, nil})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("select nulls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatalf("expected a row of results, got none!")
+	}
+	if _, err := rows.GetInteger("count"); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected a conversion error for NULL by default, got %v", err)
+	}
+	rows.SetNullAsZero(true)
+	if v, err := rows.GetBoolean("flag"); err != nil || v {
+		t.Errorf("expected false for NULL \"flag\", got %v %v", v, err)
+	}
+	if v, err := rows.GetInteger("count"); err != nil || v != 0 {
+		t.Errorf("expected 0 for NULL \"count\", got %v %v", v, err)
+	}
+	if v, err := rows.GetDoubleAt(2); err != nil || v != 0 {
+		t.Errorf("expected 0 for NULL \"ratio\", got %v %v", v, err)
+	}
+	if v, err := rows.GetString("name"); err != nil || v != "" {
+		t.Errorf("expected \"\" for NULL \"name\", got %v %v", v, err)
+	}
+	if v, err := rows.GetTime("modified"); err != nil || !v.IsZero() {
+		t.Errorf("expected the zero time for NULL \"modified\", got %v %v", v, err)
+	}
+	if v, err := rows.GetNullString("name"); err != nil || v.Valid {
+		t.Errorf("expected the nullable getters to still report NULL, got %v %v", v, err)
+	}
+	zero, err := New("zero", "ksql_hosts", "hosts", WithNullAsZero())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := zero.QueryRow("select nulls").GetInteger("count"); err != nil || v != 0 {
+		t.Errorf("expected 0 for NULL \"count\" with WithNullAsZero, got %v %v", v, err)
+	}
+}
+
+func TestStringFromBytes(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select bytes", []string{"name", "nickname"}, []driver.Value{[]byte("john doe"), nil})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := db.QueryRow("select bytes")
+	if v, err := row.GetString("name"); err != nil || v != "john doe" {
+		t.Errorf("expected \"john doe\" for \"name\", got %v %v", v, err)
+	}
+	if v, err := row.GetNullString("name"); err != nil || !v.Valid || v.String != "john doe" {
+		t.Errorf("expected \"john doe\" for nullable \"name\", got %v %v", v, err)
+	}
+	if v, err := Value[string](row, "name"); err != nil || v != "john doe" {
+		t.Errorf("expected \"john doe\" from Value, got %v %v", v, err)
+	}
+}
+
+func TestFlexibleBoolean(t *testing.T) {
+	defer Close()
+	columns := []string{"native", "one", "zero", "t", "f", "true", "false", "bytes", "two", "maybe"}
+	testHosts.setResult("select flags", columns,
+		[]driver.Value{true, int64(1), int64(0), "t", "f", "TRUE", "false", []byte("1"), int64(2), "maybe"})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := db.QueryRow("select flags")
+	tests := map[string]bool{"native": true, "one": true, "zero": false, "t": true, "f": false, "true": true, "false": false, "bytes": true}
+	for column, want := range tests {
+		v, err := row.GetBoolean(column)
+		if err != nil {
+			t.Errorf("%q: %v", column, err)
+			continue
+		}
+		if v != want {
+			t.Errorf("expected %v for %q, got %v", want, column, v)
+		}
+	}
+	for _, column := range []string{"two", "maybe"} {
+		if _, err := row.GetBoolean(column); err != ErrInvalidColumnTypeConversion {
+			t.Errorf("expected a conversion error for %q, got %v", column, err)
+		}
+	}
+}
+
+func TestRowQueryError(t *testing.T) {
+	defer Close()
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := db.QueryRow("fail")
+	if _, err := row.GetString("name"); err == nil || err.Error() != "statement failed" {
+		t.Errorf("expected the query error from the getter, got %v", err)
+	}
+	if _, err := row.IsNull("name"); err == nil {
+		t.Errorf("expected the query error again")
+	}
+}
+
+func TestNextResultSet(t *testing.T) {
+	defer Close()
+	testHosts.setResultSets("call report",
+		&hostsRows{columns: []string{"id", "name"}, values: [][]driver.Value{{int64(1), "john doe"}}},
+		&hostsRows{columns: []string{"total"}, values: [][]driver.Value{{int64(42)}, {int64(7)}}})
+	db, err := New("hosts", "ksql_hosts", "hosts", WithCaseInsensitiveColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("call report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatal("expected a row in the first result set")
+	}
+	if name, err := rows.GetString("NAME"); err != nil || name != "john doe" {
+		t.Errorf("expected john doe, got %q %v", name, err)
+	}
+	if rows.Next() {
+		t.Fatal("expected a single row in the first result set")
+	}
+	if !rows.NextResultSet() {
+		t.Fatalf("expected a second result set, got %v", rows.Err())
+	}
+	var totals []int64
+	for rows.Next() {
+		total, err := rows.GetInteger("Total")
+		if err != nil {
+			t.Fatal(err)
+		}
+		totals = append(totals, total)
+	}
+	if !reflect.DeepEqual(totals, []int64{42, 7}) {
+		t.Errorf("expected the totals, got %v", totals)
+	}
+	if _, err := rows.GetString("name"); err == nil {
+		t.Errorf("expected the columns of the first result set to be forgotten")
+	}
+	if rows.NextResultSet() {
+		t.Errorf("expected no third result set")
+	}
+}
+
+func TestGetRaw(t *testing.T) {
+	defer Close()
+	day := time.Date(2016, time.January, 2, 0, 0, 0, 0, time.UTC)
+	testHosts.setResult("select raw", []string{"point", "day", "missing"},
+		[]driver.Value{[]byte("(1,2)"), day, nil})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("select raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if _, err := rows.GetRaw("point"); err != ErrNoRows {
+		t.Errorf("expected no rows before Next, got %v", err)
+	}
+	if !rows.Next() {
+		t.Fatal("expected a row")
+	}
+	if v, err := rows.GetRaw("point"); err != nil || !reflect.DeepEqual(v, []byte("(1,2)")) {
+		t.Errorf("expected the bytes of the point, got %#v %v", v, err)
+	}
+	if v, err := rows.GetRawAt(1); err != nil || v != day {
+		t.Errorf("expected the time as is, got %#v %v", v, err)
+	}
+	if v, err := rows.GetRaw("missing"); err != nil || v != nil {
+		t.Errorf("expected nil for NULL, got %#v %v", v, err)
+	}
+	if _, err := rows.GetRaw("other"); err != ErrColumnNotFound {
+		t.Errorf("expected column not found, got %v", err)
+	}
+	if v, err := db.QueryRow("select raw").GetRaw("day"); err != nil || v != day {
+		t.Errorf("expected the time from the row, got %#v %v", v, err)
+	}
+}
+
+func TestValues(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select values", []string{"id", "data", "empty", "missing"},
+		[]driver.Value{int64(1), []byte("abc"), []byte{}, nil})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("select values")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if rows.Values() != nil {
+		t.Errorf("expected no values before Next")
+	}
+	if !rows.Next() {
+		t.Fatal("expected a row")
+	}
+	values := rows.Values()
+	want := map[string]interface{}{"id": int64(1), "data": []byte("abc"), "empty": []byte{}, "missing": nil}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("expected %v, got %v", want, values)
+	}
+	values["id"] = int64(2)
+	values["data"].([]byte)[0] = 'x'
+	delete(values, "missing")
+	if id, _ := rows.GetInteger("id"); id != 1 {
+		t.Errorf("expected the row to keep its id, got %d", id)
+	}
+	if data, _ := rows.GetString("data"); data != "abc" {
+		t.Errorf("expected the row to keep its bytes, got %q", data)
+	}
+	if null, err := rows.IsNull("missing"); err != nil || !null {
+		t.Errorf("expected the row to keep its columns, got %v %v", null, err)
+	}
+}
+
+// Reading 1000 rows of 6 columns costs about 2000 allocations, the copies of the values made
+// by database/sql, and none for the rows themselves
+func BenchmarkRowsNext(b *testing.B) {
+	defer Close()
+	values := make([][]driver.Value, 1000)
+	for i := range values {
+		values[i] = []driver.Value{int64(i), "john doe", 3.14, true, []byte("data"), time.Unix(int64(i), 0)}
+	}
+	testHosts.setResult("select bench", []string{"id", "name", "height", "married", "photo", "born"}, values...)
+	db, err := New("bench", "ksql_hosts", "bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		rows, err := db.Query("select bench")
+		if err != nil {
+			b.Fatal(err)
+		}
+		for rows.Next() {
+			if _, err := rows.GetInteger("id"); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := rows.GetString("name"); err != nil {
+				b.Fatal(err)
+			}
+		}
+		rows.Close()
+	}
+}
+
+func TestStmtColumnCache(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select cached", []string{"id", "name", "id"}, []driver.Value{int64(1), "john doe", int64(2)})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := db.Prepare("select cached")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	read := func() *Rows {
+		t.Helper()
+		rows, err := stmt.Query()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		if !rows.Next() {
+			t.Fatal("expected a row")
+		}
+		if id, err := rows.GetInteger("id_2"); err != nil || id != 2 {
+			t.Errorf("expected the second id, got %v %v", id, err)
+		}
+		return rows
+	}
+	first, second := read(), read()
+	if reflect.ValueOf(first.index).Pointer() != reflect.ValueOf(second.index).Pointer() {
+		t.Errorf("expected the column indexes to be shared by the executions of the statement")
+	}
+
+	// a change of the columns replaces the cache
+	testHosts.setResult("select cached", []string{"id", "email", "id"}, []driver.Value{int64(1), "jd@example.com", int64(2)})
+	rows := read()
+	if _, err := rows.GetString("name"); err != ErrColumnNotFound {
+		t.Errorf("expected the old columns to be forgotten, got %v", err)
+	}
+	if email, err := rows.GetString("email"); err != nil || email != "jd@example.com" {
+		t.Errorf("expected the new column, got %q %v", email, err)
+	}
+}
+
+func BenchmarkStmtQuery(b *testing.B) {
+	defer Close()
+	testHosts.setResult("select stmt bench", []string{"id", "name", "height", "married", "photo", "born"},
+		[]driver.Value{int64(1), "john doe", 3.14, true, []byte("data"), time.Unix(1, 0)})
+	db, err := New("bench", "ksql_hosts", "bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	stmt, err := db.Prepare("select stmt bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer stmt.Close()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := stmt.QueryRow().GetString("name"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRowsPooling(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select pooled", []string{"id", "name"},
+		[]driver.Value{int64(1), "john doe"}, []driver.Value{int64(2), "jane doe"})
+	pooled, err := New("pooled", "ksql_hosts", "pooled")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unpooled, err := New("unpooled", "ksql_hosts", "unpooled", WithoutRowsPooling())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name   string
+		db     *DB
+		retain bool
+		pooled bool
+	}{
+		{"pooled", pooled, false, true},
+		{"retained", pooled, true, false},
+		{"unpooled", unpooled, false, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			rows, err := test.db.Query("select pooled")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.retain {
+				rows.Retain()
+			}
+			for rows.Next() {
+			}
+			if pooled := rows.buffers != nil; pooled != test.pooled {
+				t.Errorf("expected the scan buffers to come from the pool %v, got %v", test.pooled, pooled)
+			}
+			rows.Close()
+			if rows.buffers != nil {
+				t.Errorf("expected the scan buffers to be recycled once closed")
+			}
+			if name, err := rows.GetString("name"); err != nil || name != "jane doe" {
+				t.Errorf("expected the last row to be read once closed, got %q %v", name, err)
+			}
+
+			// closed before the end of the results
+			rows, err = test.db.Query("select pooled")
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows.Next()
+			rows.Close()
+			if id, err := rows.GetInteger("id"); err != nil || id != 1 {
+				t.Errorf("expected the current row to be read once closed, got %v %v", id, err)
+			}
+			if name, err := test.db.QueryRow("select pooled").GetString("name"); err != nil || name != "john doe" {
+				t.Errorf("expected the row to keep its values, got %q %v", name, err)
+			}
+		})
+	}
+}
+
+func TestConcurrentRows(t *testing.T) {
+	defer Close()
+	var results [][]driver.Value
+	for i := 1; i <= 100; i++ {
+		results = append(results, []driver.Value{int64(i), []byte("photo")})
+	}
+	testHosts.setResult("select many", []string{"id", "photo"}, results...)
+	db, err := New("concurrent", "ksql_hosts", "concurrent", WithConcurrentRows())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("select many")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rows.Next() {
+		t.Fatal("expected a row")
+	}
+	// workers read the current row while the rows move on
+	done := make(chan struct{})
+	var wg, ready sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		ready.Add(1)
+		go func() {
+			defer wg.Done()
+			for first := true; ; first = false {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				id, err := rows.GetInteger("id")
+				rows.Values()
+				rows.Snapshot()
+				if first {
+					ready.Done()
+				}
+				if err != nil || id < 1 || id > 100 {
+					t.Errorf("expected an id, got %d %v", id, err)
+					return
+				}
+			}
+		}()
+	}
+	ready.Wait()
+	n := 1
+	for rows.Next() {
+		n++
+	}
+	close(done)
+	wg.Wait()
+	rows.Close()
+	if n != 100 {
+		t.Errorf("expected 100 rows, got %d", n)
+	}
+}
@@ -0,0 +1,281 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"iter"
+	"math/big"
+	"time"
+)
+
+// Read-only view of a row of results, with the getters of Rows. A view yielded by Rows.All
+// follows the rows, so it is only valid until the next iteration, while one from
+// Rows.Snapshot can be kept.
+type RowView struct {
+	rows *Rows
+}
+
+// Iterate over the remaining rows, closing them once done or when the loop stops early.
+// An error ends the iteration, with a nil view.
+func (rs *Rows) All() iter.Seq2[*RowView, error] {
+	return func(yield func(*RowView, error) bool) {
+		defer rs.Close()
+		view := &RowView{rows: rs}
+		for rs.Next() {
+			if !yield(view, nil) {
+				return
+			}
+		}
+		if err := rs.Err(); err != nil {
+			yield(nil, err)
+			return
+		}
+		if err := rs.Close(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// Run the query and iterate over its rows, see Rows.All
+func (db *DB) QueryIter(query string, args ...interface{}) iter.Seq2[*RowView, error] {
+	return db.QueryIterContext(context.Background(), query, args...)
+}
+
+func (db *DB) QueryIterContext(ctx context.Context, query string, args ...interface{}) iter.Seq2[*RowView, error] {
+	return func(yield func(*RowView, error) bool) {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		rows.All()(yield)
+	}
+}
+
+// Get an immutable view of the current row holding copies of its values, including copies
+// of the []byte values. Unlike the rows, it stays valid once the next row is read or the rows
+// are closed, and can be shared between goroutines. It is nil before the first row, and once
+// the rows failed.
+func (rs *Rows) Snapshot() *RowView {
+	rs.lock()
+	defer rs.unlock()
+	if rs.values == nil || rs.Err() != nil {
+		return nil
+	}
+	values := make([]interface{}, len(rs.values))
+	for i, value := range rs.values {
+		if b, ok := value.([]byte); ok {
+			value = append([]byte{}, b...)
+		}
+		values[i] = value
+	}
+	// the column indexes are never modified once built, so they are shared with the rows
+	snapshot := &Rows{db: rs.db, opts: rs.opts, columns: rs.columns, values: values, index: rs.index}
+	if rs.opts.foldCase {
+		snapshot.folded = rs.foldedColumns()
+	}
+	return &RowView{rows: snapshot}
+}
+
+// Read the remaining rows n at a time, calling fn with snapshots of every n rows and of the
+// last ones, so they can be written downstream in batches while the rows are still being
+// read. The views may be kept, but the slice is reused by the next call. The rows are closed,
+// and the first error of fn, of the rows, or of closing them is returned.
+func (rs *Rows) InChunks(n int, fn func(chunk []*RowView) error) error {
+	defer rs.Close()
+	if n < 1 {
+		n = 1
+	}
+	chunk := make([]*RowView, 0, n)
+	for rs.Next() {
+		if chunk = append(chunk, rs.Snapshot()); len(chunk) < n {
+			continue
+		}
+		if err := fn(chunk); err != nil {
+			return err
+		}
+		chunk = chunk[:0]
+	}
+	if err := rs.Err(); err != nil {
+		return err
+	}
+	if len(chunk) > 0 {
+		if err := fn(chunk); err != nil {
+			return err
+		}
+	}
+	return rs.Close()
+}
+
+// Copy every column value of this row into dest, keyed by column name
+func (v *RowView) MapScan(dest map[string]interface{}) error {
+	return v.rows.MapScan(dest)
+}
+
+// Get a copy of the values of this row keyed by column name, see Rows.Values
+func (v *RowView) Values() map[string]interface{} {
+	return v.rows.Values()
+}
+
+// Check whether the value in this row is NULL by column name
+func (v *RowView) IsNull(column string) (bool, error) {
+	return v.rows.IsNull(column)
+}
+
+// Get the value in this row by column name as the driver returned it, see Rows.GetRaw
+func (v *RowView) GetRaw(column string) (interface{}, error) {
+	return v.rows.GetRaw(column)
+}
+
+// Get the boolean value in this row by column name
+func (v *RowView) GetBoolean(column string) (bool, error) {
+	return v.rows.GetBoolean(column)
+}
+
+// Get the integer value in this row by column name
+func (v *RowView) GetInteger(column string) (int64, error) {
+	return v.rows.GetInteger(column)
+}
+
+// Get the float value in this row by column name
+func (v *RowView) GetDouble(column string) (float64, error) {
+	return v.rows.GetDouble(column)
+}
+
+// Get the string value in this row by column name
+func (v *RowView) GetString(column string) (string, error) {
+	return v.rows.GetString(column)
+}
+
+// Get a copy of the binary value in this row by column name, nil when NULL
+func (v *RowView) GetBytes(column string) ([]byte, error) {
+	return v.rows.GetBytes(column)
+}
+
+// Decode the JSON value in this row by column name into dest
+func (v *RowView) GetJSON(column string, dest interface{}) error {
+	return v.rows.GetJSON(column, dest)
+}
+
+// Get the UUID value in this row by column name
+func (v *RowView) GetUUID(column string) (UUID, error) {
+	return v.rows.GetUUID(column)
+}
+
+// Get the exact numeric value in this row by column name
+func (v *RowView) GetDecimal(column string) (string, error) {
+	return v.rows.GetDecimal(column)
+}
+
+// Get the exact numeric value in this row by column name as a rational number
+func (v *RowView) GetRat(column string) (*big.Rat, error) {
+	return v.rows.GetRat(column)
+}
+
+// Get the duration value in this row by column name
+func (v *RowView) GetDuration(column string) (time.Duration, error) {
+	return v.rows.GetDuration(column)
+}
+
+// Get the date value in this row by column name, as midnight UTC of that day
+func (v *RowView) GetDate(column string) (time.Time, error) {
+	return v.rows.GetDate(column)
+}
+
+// Get the string array value in this row by column name, nil when NULL
+func (v *RowView) GetStringSlice(column string) ([]string, error) {
+	return v.rows.GetStringSlice(column)
+}
+
+// Get the int64 array value in this row by column name, nil when NULL
+func (v *RowView) GetInt64Slice(column string) ([]int64, error) {
+	return v.rows.GetInt64Slice(column)
+}
+
+// Get the float64 array value in this row by column name, nil when NULL
+func (v *RowView) GetFloat64Slice(column string) ([]float64, error) {
+	return v.rows.GetFloat64Slice(column)
+}
+
+// Get the time.Time value in this row by column name
+func (v *RowView) GetTime(column string) (time.Time, error) {
+	return v.rows.GetTime(column)
+}
+
+// Get the time.Time value in this row by column name, in the location loc
+func (v *RowView) GetTimeIn(column string, loc *time.Location) (time.Time, error) {
+	return v.rows.GetTimeIn(column, loc)
+}
+
+// Get the nullable boolean value in this row by column name
+func (v *RowView) GetNullBoolean(column string) (sql.NullBool, error) {
+	return v.rows.GetNullBoolean(column)
+}
+
+// Get the nullable integer value in this row by column name
+func (v *RowView) GetNullInteger(column string) (sql.NullInt64, error) {
+	return v.rows.GetNullInteger(column)
+}
+
+// Get the nullable float value in this row by column name
+func (v *RowView) GetNullDouble(column string) (sql.NullFloat64, error) {
+	return v.rows.GetNullDouble(column)
+}
+
+// Get the nullable string value in this row by column name
+func (v *RowView) GetNullString(column string) (sql.NullString, error) {
+	return v.rows.GetNullString(column)
+}
+
+// Get the nullable time.Time value in this row by column name
+func (v *RowView) GetNullTime(column string) (sql.NullTime, error) {
+	return v.rows.GetNullTime(column)
+}
+
+// Check whether the value in this row is NULL by column index
+func (v *RowView) IsNullAt(i int) (bool, error) {
+	return v.rows.IsNullAt(i)
+}
+
+// Get the boolean value in this row by column index
+func (v *RowView) GetBooleanAt(i int) (bool, error) {
+	return v.rows.GetBooleanAt(i)
+}
+
+// Get the integer value in this row by column index
+func (v *RowView) GetIntegerAt(i int) (int64, error) {
+	return v.rows.GetIntegerAt(i)
+}
+
+// Get the double value in this row by column index
+func (v *RowView) GetDoubleAt(i int) (float64, error) {
+	return v.rows.GetDoubleAt(i)
+}
+
+// Get the string value in this row by column index
+func (v *RowView) GetStringAt(i int) (string, error) {
+	return v.rows.GetStringAt(i)
+}
+
+// Get the time.Time value in this row by column index
+func (v *RowView) GetTimeAt(i int) (time.Time, error) {
+	return v.rows.GetTimeAt(i)
+}
+
+// Get the value in this row by column index as the driver returned it, see Rows.GetRaw
+func (v *RowView) GetRawAt(i int) (interface{}, error) {
+	return v.rows.GetRawAt(i)
+}
+
+// Scan the row into the struct pointed to by dest
+func (v *RowView) ScanStruct(dest interface{}) error {
+	return v.rows.ScanStruct(dest)
+}
+
+func (v *RowView) value(column string) (interface{}, error) {
+	return v.rows.value(column)
+}
+
+func (v *RowView) source() *Rows {
+	return v.rows
+}
@@ -0,0 +1,141 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestRowsAll(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select people", []string{"id", "name"},
+		[]driver.Value{int64(1), "john doe"}, []driver.Value{int64(2), "jane doe"}, []driver.Value{int64(3), "baby doe"})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for row, err := range db.QueryIter("select people") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		name, err := row.GetString("name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id, _ := Value[int64](row, "id"); id != int64(len(names)+1) {
+			t.Errorf("expected %d for \"id\", got %d", len(names)+1, id)
+		}
+		names = append(names, name)
+	}
+	if len(names) != 3 || names[2] != "baby doe" {
+		t.Errorf("expected 3 people, got %v", names)
+	}
+	rows, err := db.Query("select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range rows.All() {
+		break
+	}
+	if rows.Next() {
+		t.Errorf("expected stopping the loop to close the rows")
+	}
+	n := 0
+	for row, err := range db.QueryIter("fail") {
+		n++
+		if row != nil || err == nil || err.Error() != "statement failed" {
+			t.Errorf("expected the query error with no row, got %v %v", row, err)
+		}
+	}
+	if n != 1 {
+		t.Errorf("expected a single error, got %d iterations", n)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select photos", []string{"id", "photo"},
+		[]driver.Value{int64(1), []byte("first")}, []driver.Value{int64(2), []byte("second")})
+	db, err := New("snapshot", "ksql_hosts", "snapshot", WithCaseInsensitiveColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("select photos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows.Snapshot() != nil {
+		t.Error("expected no snapshot before the first row")
+	}
+	var snapshots []*RowView
+	for rows.Next() {
+		snapshots = append(snapshots, rows.Snapshot())
+	}
+	rows.Close()
+	if len(snapshots) != 2 {
+		t.Fatalf("expected 2 snapshots, got %d", len(snapshots))
+	}
+	for i, want := range []string{"first", "second"} {
+		if id, err := snapshots[i].GetInteger("ID"); err != nil || id != int64(i+1) {
+			t.Errorf("expected id %d, got %d %v", i+1, id, err)
+		}
+		if photo, err := snapshots[i].GetBytes("photo"); err != nil || string(photo) != want {
+			t.Errorf("expected %q, got %q %v", want, photo, err)
+		}
+	}
+	photo, _ := snapshots[0].GetRaw("photo")
+	photo.([]byte)[0] = 'F'
+	if other, _ := snapshots[1].GetRaw("photo"); string(other.([]byte)) != "second" {
+		t.Errorf("expected the snapshots not to share bytes, got %q", other)
+	}
+}
+
+func TestInChunks(t *testing.T) {
+	defer Close()
+	var results [][]driver.Value
+	for i := 1; i <= 7; i++ {
+		results = append(results, []driver.Value{int64(i)})
+	}
+	testHosts.setResult("select seven", []string{"id"}, results...)
+	db, err := New("chunks", "ksql_hosts", "chunks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("select seven")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sizes []int
+	var views []*RowView
+	err = rows.InChunks(3, func(chunk []*RowView) error {
+		sizes = append(sizes, len(chunk))
+		views = append(views, chunk...)
+		return nil
+	})
+	if err != nil || len(sizes) != 3 || sizes[0] != 3 || sizes[2] != 1 {
+		t.Fatalf("expected chunks of 3, 3 and 1 rows, got %v %v", sizes, err)
+	}
+	for i, view := range views {
+		if id, err := view.GetInteger("id"); err != nil || id != int64(i+1) {
+			t.Errorf("expected id %d, got %d %v", i+1, id, err)
+		}
+	}
+
+	rows, err = db.Query("select seven")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := errors.New("stop")
+	calls := 0
+	err = rows.InChunks(2, func(chunk []*RowView) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("expected the error of fn to stop, got %d calls %v", calls, err)
+	}
+	if rows.Next() {
+		t.Error("expected the rows to be closed")
+	}
+}
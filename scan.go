@@ -0,0 +1,159 @@
+package ksql
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Cache of column name to field index mappings per struct type
+var fieldCache sync.Map
+
+// Scan the current row into the struct pointed to by dest. Columns are matched to fields
+// by their `ksql:"column"` tag, or by the snake_case form of the field name. Columns
+// without a matching field are ignored, and a tag of "-" skips the field.
+func (rs *Rows) ScanStruct(dest interface{}) error {
+	if err := rs.Err(); err != nil {
+		return err
+	}
+	if rs.values == nil {
+		return ErrNoRows
+	}
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+	return scanStruct(rs, v.Elem())
+}
+
+// Scan the row into the struct pointed to by dest, see Rows.ScanStruct
+func (r *Row) ScanStruct(dest interface{}) error {
+	if err := next(r); err != nil {
+		return err
+	}
+	return r.rows.ScanStruct(dest)
+}
+
+// Run the query and scan every row into the slice of structs pointed to by dest,
+// see Rows.ScanStruct for how columns are matched to fields
+func (db *DB) SelectAll(dest interface{}, query string, args ...interface{}) error {
+	return db.SelectAllContext(context.Background(), dest, query, args...)
+}
+
+func (db *DB) SelectAllContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return ErrInvalidDestination
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	result := reflect.MakeSlice(slice.Type(), 0, 0)
+	for rows.Next() {
+		elem := reflect.New(elemType)
+		if err := scanStruct(rows, elem.Elem()); err != nil {
+			return err
+		}
+		if !isPtr {
+			elem = elem.Elem()
+		}
+		result = reflect.Append(result, elem)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	slice.Set(result)
+	return nil
+}
+
+func scanStruct(rs *Rows, v reflect.Value) error {
+	fields := fieldsOf(v.Type())
+	rs.lock()
+	defer rs.unlock()
+	for name, index := range fields {
+		i, err := validateRows(rs, name)
+		if err == ErrColumnNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := rs.convertAssign(v.FieldByIndex(index), rs.values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get the column name to field index mapping of a struct type
+func fieldsOf(t reflect.Type) map[string][]int {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.(map[string][]int)
+	}
+	fields := make(map[string][]int)
+	collectFields(t, nil, fields)
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+func collectFields(t reflect.Type, parent []int, fields map[string][]int) {
+	var embedded []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("ksql")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			embedded = append(embedded, f)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = snakeCase(f.Name)
+		}
+		if _, dup := fields[name]; !dup {
+			fields[name] = append(append([]int{}, parent...), f.Index...)
+		}
+	}
+	// fields of embedded structs never shadow the fields of the outer struct
+	for _, f := range embedded {
+		collectFields(f.Type, append(append([]int{}, parent...), f.Index...), fields)
+	}
+}
+
+// Convert a Go field name to snake_case, keeping acronyms together (UserID -> user_id)
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,46 @@
+package ksql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"ID":           "id",
+		"Name":         "name",
+		"UserID":       "user_id",
+		"LastModified": "last_modified",
+		"HTTPServer":   "http_server",
+		"Address2":     "address2",
+	}
+	for name, want := range tests {
+		if got := snakeCase(name); got != want {
+			t.Errorf("snakeCase(%q): expected %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestFieldsOf(t *testing.T) {
+	type Audit struct {
+		ID           int
+		LastModified time.Time
+	}
+	type person struct {
+		Audit
+		ID      int64  `ksql:"id"`
+		Name    string `ksql:"full_name"`
+		Secret  string `ksql:"-"`
+		private string
+	}
+	fields := fieldsOf(reflect.TypeOf(person{}))
+	want := map[string][]int{
+		"id":            {1},
+		"full_name":     {2},
+		"last_modified": {0, 1},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("expected %v, got %v", want, fields)
+	}
+}
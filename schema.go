@@ -0,0 +1,188 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Column of a table, as described by the catalog of the database
+type TableColumn struct {
+	Name       string
+	DataType   string
+	Nullable   bool
+	Default    string // expression of the default value
+	HasDefault bool
+	Position   int // 1 based
+}
+
+// Index of a table, with its columns in order
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	Primary bool
+}
+
+// Catalog queries of a dialect, written with ? placeholders. Every columns query returns the
+// name, data type, YES or NO nullability, default and position, and every indexes query one
+// row per index column with the index name, uniqueness, primary flag and column name.
+type schemaSQL struct {
+	tables     string
+	columns    string
+	primaryKey string
+	indexes    string
+}
+
+var schemaQueries = map[Dialect]schemaSQL{
+	DialectPostgres: {
+		tables: "SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() " +
+			"AND table_type = 'BASE TABLE' ORDER BY table_name",
+		columns: "SELECT column_name, data_type, is_nullable, column_default, ordinal_position " +
+			"FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? ORDER BY ordinal_position",
+		primaryKey: "SELECT kcu.column_name FROM information_schema.table_constraints tc " +
+			"JOIN information_schema.key_column_usage kcu ON kcu.constraint_name = tc.constraint_name " +
+			"AND kcu.table_schema = tc.table_schema AND kcu.table_name = tc.table_name " +
+			"WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_schema = current_schema() AND tc.table_name = ? " +
+			"ORDER BY kcu.ordinal_position",
+		indexes: "SELECT i.relname, ix.indisunique, ix.indisprimary, a.attname FROM pg_index ix " +
+			"JOIN pg_class t ON t.oid = ix.indrelid JOIN pg_class i ON i.oid = ix.indexrelid " +
+			"JOIN pg_namespace n ON n.oid = t.relnamespace " +
+			"JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true " +
+			"JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum " +
+			"WHERE n.nspname = current_schema() AND t.relname = ? ORDER BY i.relname, k.ord",
+	},
+	DialectMySQL: {
+		tables: "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() " +
+			"AND table_type = 'BASE TABLE' ORDER BY table_name",
+		columns: "SELECT column_name, data_type, is_nullable, column_default, ordinal_position " +
+			"FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position",
+		primaryKey: "SELECT column_name FROM information_schema.key_column_usage WHERE table_schema = DATABASE() " +
+			"AND table_name = ? AND constraint_name = 'PRIMARY' ORDER BY ordinal_position",
+		indexes: "SELECT index_name, non_unique = 0, index_name = 'PRIMARY', column_name FROM information_schema.statistics " +
+			"WHERE table_schema = DATABASE() AND table_name = ? ORDER BY index_name, seq_in_index",
+	},
+	DialectSQLite: {
+		tables: "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name",
+		columns: "SELECT name, type, CASE WHEN \"notnull\" = 0 AND pk = 0 THEN 'YES' ELSE 'NO' END, dflt_value, cid + 1 " +
+			"FROM pragma_table_info(?) ORDER BY cid",
+		primaryKey: "SELECT name FROM pragma_table_info(?) WHERE pk > 0 ORDER BY pk",
+		indexes: "SELECT il.name, il.\"unique\", il.origin = 'pk', ii.name FROM pragma_index_list(?) il " +
+			"JOIN pragma_index_info(il.name) ii ORDER BY il.name, ii.seqno",
+	},
+}
+
+// Get the catalog query of the dialect of the database, rewritten for its placeholders
+func (db *DB) schemaQuery(pick func(schemaSQL) string) (string, error) {
+	queries, ok := schemaQueries[db.dialect]
+	if !ok {
+		return "", ErrUnsupportedDialect
+	}
+	return Rebind(db.bind, pick(queries)), nil
+}
+
+// Get the names of the tables of the current schema, sorted
+func (db *DB) Tables() ([]string, error) {
+	return db.TablesContext(context.Background())
+}
+
+func (db *DB) TablesContext(ctx context.Context) ([]string, error) {
+	query, err := db.schemaQuery(func(q schemaSQL) string { return q.tables })
+	if err != nil {
+		return nil, err
+	}
+	return db.queryNames(ctx, query)
+}
+
+// Get the columns of the table, in order
+func (db *DB) Columns(table string) ([]TableColumn, error) {
+	return db.ColumnsContext(context.Background(), table)
+}
+
+func (db *DB) ColumnsContext(ctx context.Context, table string) ([]TableColumn, error) {
+	query, err := db.schemaQuery(func(q schemaSQL) string { return q.columns })
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []TableColumn
+	for rows.Next() {
+		var c TableColumn
+		var nullable string
+		var def sql.NullString
+		if err := rows.Scan(&c.Name, &c.DataType, &nullable, &def, &c.Position); err != nil {
+			return nil, err
+		}
+		c.Nullable = nullable == "YES"
+		c.Default, c.HasDefault = def.String, def.Valid
+		columns = append(columns, c)
+	}
+	return columns, rows.Err()
+}
+
+// Get the columns of the primary key of the table, in order, empty when it has none
+func (db *DB) PrimaryKey(table string) ([]string, error) {
+	return db.PrimaryKeyContext(context.Background(), table)
+}
+
+func (db *DB) PrimaryKeyContext(ctx context.Context, table string) ([]string, error) {
+	query, err := db.schemaQuery(func(q schemaSQL) string { return q.primaryKey })
+	if err != nil {
+		return nil, err
+	}
+	return db.queryNames(ctx, query, table)
+}
+
+// Get the indexes of the table, sorted by name. Columns of expression indexes are left out.
+func (db *DB) Indexes(table string) ([]Index, error) {
+	return db.IndexesContext(context.Background(), table)
+}
+
+func (db *DB) IndexesContext(ctx context.Context, table string) ([]Index, error) {
+	query, err := db.schemaQuery(func(q schemaSQL) string { return q.indexes })
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []Index
+	for rows.Next() {
+		var index Index
+		var column string
+		if err := rows.Scan(&index.Name, &index.Unique, &index.Primary, &column); err != nil {
+			return nil, err
+		}
+		// the rows of an index are consecutive
+		if n := len(indexes); n > 0 && indexes[n-1].Name == index.Name {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, column)
+			continue
+		}
+		index.Columns = []string{column}
+		indexes = append(indexes, index)
+	}
+	return indexes, rows.Err()
+}
+
+// Run a query returning a single column of names
+func (db *DB) queryNames(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
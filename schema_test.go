@@ -0,0 +1,62 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	defer Close()
+	queries := schemaQueries[DialectSQLite]
+	testHosts.setResult(queries.tables, []string{"name"}, []driver.Value{"orders"}, []driver.Value{"people"})
+	testHosts.setResult(queries.columns, []string{"name", "type", "nullable", "dflt_value", "position"},
+		[]driver.Value{"id", "INTEGER", "NO", nil, int64(1)},
+		[]driver.Value{"name", "TEXT", "YES", "'john doe'", int64(2)})
+	testHosts.setResult(queries.primaryKey, []string{"name"}, []driver.Value{"id"})
+	testHosts.setResult(queries.indexes, []string{"index", "unique", "primary", "column"},
+		[]driver.Value{"people_name", int64(0), int64(0), "name"},
+		[]driver.Value{"people_name_id", int64(1), int64(0), "name"},
+		[]driver.Value{"people_name_id", int64(1), int64(0), "id"})
+	db, err := New("hosts", "ksql_hosts", "hosts", WithDialect(DialectSQLite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := db.Tables()
+	if err != nil || !reflect.DeepEqual(tables, []string{"orders", "people"}) {
+		t.Errorf("expected the tables, got %v %v", tables, err)
+	}
+	columns, err := db.Columns("people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TableColumn{
+		{Name: "id", DataType: "INTEGER", Position: 1},
+		{Name: "name", DataType: "TEXT", Nullable: true, Default: "'john doe'", HasDefault: true, Position: 2},
+	}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("expected %+v, got %+v", want, columns)
+	}
+	key, err := db.PrimaryKey("people")
+	if err != nil || !reflect.DeepEqual(key, []string{"id"}) {
+		t.Errorf("expected the id primary key, got %v %v", key, err)
+	}
+	indexes, err := db.Indexes("people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantIndexes := []Index{
+		{Name: "people_name", Columns: []string{"name"}},
+		{Name: "people_name_id", Columns: []string{"name", "id"}, Unique: true},
+	}
+	if !reflect.DeepEqual(indexes, wantIndexes) {
+		t.Errorf("expected %+v, got %+v", wantIndexes, indexes)
+	}
+	other, err := New("other", "ksql_hosts", "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Tables(); err != ErrUnsupportedDialect {
+		t.Errorf("expected an unsupported dialect, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package ksql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Split a SQL script into its statements at the semicolons outside of quoted text, dollar
+// quoted text and comments. Statements holding nothing but comments are dropped.
+func SplitStatements(script string) []string {
+	var statements []string
+	start := 0
+	for i := 0; i <= len(script); i++ {
+		if i < len(script) {
+			if end := skipLiteral(script, i); end > i {
+				i = end - 1
+				continue
+			}
+			if script[i] != ';' {
+				continue
+			}
+		}
+		if statement := strings.TrimSpace(script[start:i]); Fingerprint(statement) != "" {
+			statements = append(statements, statement)
+		}
+		start = i + 1
+	}
+	return statements
+}
+
+// Read a SQL script and execute its statements one after the other, see SplitStatements.
+// Use Tx.ExecScript to run all of them or none.
+func (db *DB) ExecScript(r io.Reader) error {
+	return db.ExecScriptContext(context.Background(), r)
+}
+
+func (db *DB) ExecScriptContext(ctx context.Context, r io.Reader) error {
+	return execScript(ctx, db, r)
+}
+
+// Read a SQL script and execute its statements as part of the transaction
+func (tx *Tx) ExecScript(r io.Reader) error {
+	return tx.ExecScriptContext(tx.context(), r)
+}
+
+func (tx *Tx) ExecScriptContext(ctx context.Context, r io.Reader) error {
+	return execScript(ctx, tx, r)
+}
+
+func execScript(ctx context.Context, e execer, r io.Reader) error {
+	script, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	for i, statement := range SplitStatements(string(script)) {
+		if _, err := e.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("ksql: statement %d of the script: %w", i+1, err)
+		}
+	}
+	return nil
+}
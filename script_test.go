@@ -0,0 +1,74 @@
+package ksql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- people of the test
+create table people (id integer, name text);
+insert into people values (1, 'john; doe');
+insert into people values (2, 'it''s; me') /* a ; comment */;
+create function touch() returns trigger as $$
+begin
+	new.modified = now(); return new;
+end;
+$$ language plpgsql;
+create function hello() returns text as $body$ select 'a;b' $body$ language sql;
+select $1::text;
+-- the end;
+`
+	want := []string{
+		"-- people of the test\ncreate table people (id integer, name text)",
+		"insert into people values (1, 'john; doe')",
+		"insert into people values (2, 'it''s; me') /* a ; comment */",
+		"create function touch() returns trigger as $$\nbegin\n\tnew.modified = now(); return new;\nend;\n$$ language plpgsql",
+		"create function hello() returns text as $body$ select 'a;b' $body$ language sql",
+		"select $1::text",
+	}
+	if got := SplitStatements(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := SplitStatements("select 1"); !reflect.DeepEqual(got, []string{"select 1"}) {
+		t.Errorf("expected a statement without a semicolon, got %q", got)
+	}
+}
+
+func TestExecScript(t *testing.T) {
+	defer Close()
+	hook := &recordingHook{}
+	db, err := New("hosts", "ksql_hosts", "hosts", WithHook(hook))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.ExecScript(strings.NewReader("delete from people; delete from pets;")); err != nil {
+		t.Fatal(err)
+	}
+	if len(hook.after) != 2 || hook.after[1].Query != "delete from pets" {
+		t.Errorf("expected 2 statements, got %v", hook.after)
+	}
+	err = db.WithTx(context.Background(), func(tx *Tx) error {
+		return tx.ExecScriptContext(context.Background(), strings.NewReader("delete from people;\nfail;\ndelete from pets"))
+	})
+	if err == nil || err.Error() != "ksql: statement 2 of the script: statement failed" {
+		t.Errorf("expected the failing statement, got %v", err)
+	}
+	if errors.Unwrap(err) == nil {
+		t.Errorf("expected the statement error to be wrapped")
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if err := tx.ExecScript(strings.NewReader("delete from pets")); err != nil {
+		t.Fatal(err)
+	}
+	if last := hook.after[len(hook.after)-1]; last.Query != "delete from pets" {
+		t.Errorf("expected the statement of the script in the transaction, got %q", last.Query)
+	}
+}
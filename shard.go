@@ -0,0 +1,251 @@
+package ksql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Function hashing the key of a row to pick the shard holding it, see NewShardGroup
+type HashFunc func(key string) uint64
+
+// Databases holding the shards of the same data, with each key routed to one of them
+type ShardGroup struct {
+	name string
+	// shards in the order of their names, and their names
+	names  []string
+	shards []*DB
+	hash   HashFunc
+}
+
+// Shard groups by name, guarded by poolMu like the database connections
+var groups = make(map[string]*ShardGroup)
+
+// Open a database connection for every shard of a group, with the same driver and options,
+// and save each one by the name of the group and of the shard, e.g. "orders:eu". The group is
+// saved by name as well, see GetShardGroup. A key is routed to the shard at its hash modulo
+// the number of shards, in the order of their names, hashed by FNV-1a when hash is nil.
+func NewShardGroup(name, driver string, shards map[string]string, hash HashFunc, opts ...Option) (*ShardGroup, error) {
+	if len(shards) == 0 {
+		return nil, ErrNoDataSource
+	}
+	if _, dup := GetShardGroup(name); dup {
+		return nil, ErrDupConnName
+	}
+	if hash == nil {
+		hash = fnvHash
+	}
+	group := &ShardGroup{name: name, hash: hash}
+	for shard := range shards {
+		group.names = append(group.names, shard)
+	}
+	sort.Strings(group.names)
+	for _, shard := range group.names {
+		db, err := New(name+":"+shard, driver, shards[shard], opts...)
+		if err != nil {
+			group.Close()
+			return nil, err
+		}
+		group.shards = append(group.shards, db)
+	}
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if _, dup := groups[name]; dup {
+		group.closeLocked()
+		return nil, ErrDupConnName
+	}
+	groups[name] = group
+	return group, nil
+}
+
+// Get a shard group by name
+func GetShardGroup(name string) (*ShardGroup, bool) {
+	poolMu.RLock()
+	defer poolMu.RUnlock()
+	group, ok := groups[name]
+	return group, ok
+}
+
+func fnvHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// Get the name the shard group is saved by
+func (g *ShardGroup) Name() string {
+	return g.name
+}
+
+// Get the database of the shard holding the key
+func (g *ShardGroup) Shard(key string) *DB {
+	return g.shards[g.hash(key)%uint64(len(g.shards))]
+}
+
+// Get the databases of the shards, in the order of their names
+func (g *ShardGroup) Shards() []*DB {
+	return append([]*DB(nil), g.shards...)
+}
+
+// Close the databases of the shards and remove them along with the group, returning the
+// first error of the shards that failed to close
+func (g *ShardGroup) Close() error {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if groups[g.name] == g {
+		delete(groups, g.name)
+	}
+	return g.closeLocked()
+}
+
+// Close the shards and remove them, with poolMu held
+func (g *ShardGroup) closeLocked() error {
+	var first error
+	for _, db := range g.shards {
+		if err := db.close(); err != nil && first == nil {
+			first = err
+		}
+		if pool[db.name] == db {
+			delete(pool, db.name)
+		}
+	}
+	return first
+}
+
+// Failure of the query of a shard, see ShardGroup.QueryAll
+type ShardError struct {
+	Shard string
+	Err   error
+}
+
+func (e *ShardError) Error() string { return fmt.Sprintf("ksql: shard %q: %v", e.Shard, e.Err) }
+func (e *ShardError) Unwrap() error { return e.Err }
+
+// Rows of a query run on every shard of a group, merged in the order they are read. The
+// getters of the embedded RowView read the current row, which can be kept like a snapshot.
+type ShardRows struct {
+	*RowView
+	shard  string
+	rows   chan shardRow
+	cancel context.CancelFunc
+	closed atomic.Bool
+
+	mu   sync.Mutex
+	errs map[string]error
+}
+
+type shardRow struct {
+	shard string
+	view  *RowView
+}
+
+// View without values, for the getters before the first row and after the last one
+var noRowView = &RowView{rows: &Rows{}}
+
+// Run the query on every shard of the group concurrently, and iterate over the rows of all
+// the shards, in the order they are read. The failure of a shard doesn't stop the others,
+// its error is reported once the rows are done by Err and Errors. The rows must be closed.
+func (g *ShardGroup) QueryAll(ctx context.Context, query string, args ...interface{}) *ShardRows {
+	ctx, cancel := context.WithCancel(ctx)
+	sr := &ShardRows{RowView: noRowView, rows: make(chan shardRow), cancel: cancel}
+	var wg sync.WaitGroup
+	for i, db := range g.shards {
+		wg.Add(1)
+		go func(shard string, db *DB) {
+			defer wg.Done()
+			if err := sr.read(ctx, shard, db, query, args); err != nil && !sr.closed.Load() {
+				sr.mu.Lock()
+				if sr.errs == nil {
+					sr.errs = make(map[string]error)
+				}
+				sr.errs[shard] = err
+				sr.mu.Unlock()
+			}
+		}(g.names[i], db)
+	}
+	go func() {
+		wg.Wait()
+		close(sr.rows)
+	}()
+	return sr
+}
+
+// Send snapshots of the rows of a shard until they are done or the rows are closed
+func (sr *ShardRows) read(ctx context.Context, shard string, db *DB, query string, args []interface{}) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		select {
+		case sr.rows <- shardRow{shard: shard, view: rows.Snapshot()}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// Advance to the next row of any shard, and report whether there is one
+func (sr *ShardRows) Next() bool {
+	row, ok := <-sr.rows
+	if !ok {
+		sr.RowView, sr.shard = noRowView, ""
+		sr.cancel()
+		return false
+	}
+	sr.RowView, sr.shard = row.view, row.shard
+	return true
+}
+
+// Get the name of the shard of the current row
+func (sr *ShardRows) Shard() string {
+	return sr.shard
+}
+
+// Get the errors of the shards that failed, as ShardError joined in the order of the shard
+// names, or nil. It is complete once Next returned false.
+func (sr *ShardRows) Err() error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	shards := make([]string, 0, len(sr.errs))
+	for shard := range sr.errs {
+		shards = append(shards, shard)
+	}
+	sort.Strings(shards)
+	errs := make([]error, len(shards))
+	for i, shard := range shards {
+		errs[i] = &ShardError{Shard: shard, Err: sr.errs[shard]}
+	}
+	return errors.Join(errs...)
+}
+
+// Get the errors of the shards that failed by shard name, see Err
+func (sr *ShardRows) Errors() map[string]error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	errs := make(map[string]error, len(sr.errs))
+	for shard, err := range sr.errs {
+		errs[shard] = err
+	}
+	return errs
+}
+
+// Stop reading the shards and wait for their rows to be closed. The errors of the shards
+// read so far remain available.
+func (sr *ShardRows) Close() error {
+	sr.closed.Store(true)
+	sr.cancel()
+	for range sr.rows {
+	}
+	sr.RowView, sr.shard = noRowView, ""
+	return nil
+}
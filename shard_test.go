@@ -0,0 +1,145 @@
+package ksql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestShardGroup(t *testing.T) {
+	defer Close()
+	group, err := NewShardGroup("orders", "ksql_hosts", map[string]string{"eu": "orders eu", "us": "orders us"},
+		func(key string) uint64 {
+			n, _ := strconv.ParseUint(key, 10, 64)
+			return n
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eu, ok := Get("orders:eu")
+	if !ok {
+		t.Fatal("expected the shards to be saved by name")
+	}
+	if group.Shard("4") != eu || group.Shard("5").Name() != "orders:us" {
+		t.Errorf("expected the keys to be routed by hash, got %q and %q", group.Shard("4").Name(), group.Shard("5").Name())
+	}
+	if found, ok := GetShardGroup("orders"); !ok || found != group {
+		t.Error("expected the group to be saved by name")
+	}
+	if _, err := NewShardGroup("orders", "ksql_hosts", map[string]string{"eu": "eu"}, nil); err != ErrDupConnName {
+		t.Errorf("expected a duplicate group name to fail, got %v", err)
+	}
+	if _, err := NewShardGroup("empty", "ksql_hosts", nil, nil); err != ErrNoDataSource {
+		t.Errorf("expected a group without shards to fail, got %v", err)
+	}
+	if _, err := group.Shard("1").Exec("update orders"); err != nil {
+		t.Error(err)
+	}
+	if err := group.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Get("orders:us"); ok {
+		t.Error("expected the shards to be removed")
+	}
+	if _, ok := GetShardGroup("orders"); ok {
+		t.Error("expected the group to be removed")
+	}
+
+	// the default hash spreads keys over every shard
+	group, err = NewShardGroup("users", "ksql_hosts", map[string]string{"a": "a", "b": "b", "c": "c"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[*DB]bool)
+	for i := 0; i < 100; i++ {
+		db := group.Shard(strconv.Itoa(i))
+		if db != group.Shard(strconv.Itoa(i)) {
+			t.Fatal("expected a key to always be routed to the same shard")
+		}
+		seen[db] = true
+	}
+	if len(seen) != 3 || len(group.Shards()) != 3 {
+		t.Errorf("expected the keys over 3 shards, got %d", len(seen))
+	}
+	Close()
+	if _, ok := GetShardGroup("users"); ok {
+		t.Error("expected Close to remove the groups")
+	}
+}
+
+func TestShardQueryAll(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select report", []string{"name"}, []driver.Value{"john doe"})
+	group, err := NewShardGroup("reports", "ksql_hosts",
+		map[string]string{"a": "reports a", "b": "reports b", "c": "reports c"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the connections of b are all closed once idle, so it is reached again by the next query
+	group.Shards()[1].SetMaxIdleConns(0)
+	rows := group.QueryAll(context.Background(), "select report")
+	if _, err := rows.GetString("name"); err != ErrNoRows {
+		t.Errorf("expected no row before Next, got %v", err)
+	}
+	shards := make(map[string]bool)
+	for rows.Next() {
+		if name, err := rows.GetString("name"); err != nil || name != "john doe" {
+			t.Errorf("expected the row of a shard, got %q %v", name, err)
+		}
+		shards[rows.Shard()] = true
+	}
+	if err := rows.Err(); err != nil || len(shards) != 3 {
+		t.Errorf("expected a row from each of the 3 shards, got %v %v", shards, err)
+	}
+	rows.Close()
+
+	testHosts.setDown("reports b", true)
+	defer testHosts.setDown("reports b", false)
+	rows = group.QueryAll(context.Background(), "select report")
+	n := 0
+	for rows.Next() {
+		n++
+	}
+	rows.Close()
+	var shardErr *ShardError
+	if err := rows.Err(); !errors.As(err, &shardErr) || shardErr.Shard != "b" || n != 2 {
+		t.Errorf("expected the rows of 2 shards and the error of the third, got %d %v", n, err)
+	}
+	if errs := rows.Errors(); len(errs) != 1 || errs["b"] == nil {
+		t.Errorf("expected the error by shard, got %v", errs)
+	}
+}
+
+func TestShardQueryAllClose(t *testing.T) {
+	defer Close()
+	var results [][]driver.Value
+	for i := 0; i < 50; i++ {
+		results = append(results, []driver.Value{int64(i)})
+	}
+	testHosts.setResult("select many ids", []string{"id"}, results...)
+	group, err := NewShardGroup("events", "ksql_hosts", map[string]string{"a": "events a", "b": "events b"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := group.QueryAll(context.Background(), "select many ids")
+	if !rows.Next() {
+		t.Fatal("expected a row")
+	}
+	view := rows.RowView
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rows.Err(); err != nil {
+		t.Errorf("expected closing early not to fail the shards, got %v", err)
+	}
+	if _, err := view.GetInteger("id"); err != nil {
+		t.Errorf("expected the row to be kept, got %v", err)
+	}
+	for _, db := range group.Shards() {
+		if inUse := db.Stats().InUse; inUse != 0 {
+			t.Errorf("expected the rows of %q to be closed, got %d connections in use", db.Name(), inUse)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package ksql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Interval between checks for in-flight work while shutting down
+const shutdownPollInterval = 10 * time.Millisecond
+
+// Shut down every open database connection gracefully. New statements and transactions are
+// refused with ErrShuttingDown right away, while the ones in flight, including open rows and
+// transactions, may finish until the context is done. Every connection is then closed and
+// removed, and the error of the context or of the connections that failed to close returned.
+func Shutdown(ctx context.Context) error {
+	poolMu.RLock()
+	dbs := make([]*DB, 0, len(pool))
+	for _, db := range pool {
+		dbs = append(dbs, db)
+	}
+	poolMu.RUnlock()
+	for _, db := range dbs {
+		db.drain()
+	}
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	var errs []error
+wait:
+	for _, db := range dbs {
+		for db.inUse() > 0 {
+			select {
+			case <-ctx.Done():
+				errs = append(errs, ctx.Err())
+				break wait
+			case <-ticker.C:
+			}
+		}
+	}
+	failed := CloseAll()
+	names := make([]string, 0, len(failed))
+	for name := range failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		errs = append(errs, fmt.Errorf("ksql: closing %q: %w", name, failed[name]))
+	}
+	return errors.Join(errs...)
+}
+
+// Refuse new statements and transactions on the database and its replicas
+func (db *DB) drain() {
+	atomic.StoreInt32(&db.draining, 1)
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	for _, replica := range db.replicas {
+		replica.drain()
+	}
+}
+
+func (db *DB) isDraining() bool {
+	return atomic.LoadInt32(&db.draining) != 0
+}
+
+// Get the number of connections of the database and its replicas in use
+func (db *DB) inUse() int {
+	n := db.Stats().InUse
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	for _, replica := range db.replicas {
+		n += replica.inUse()
+	}
+	return n
+}
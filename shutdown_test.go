@@ -0,0 +1,87 @@
+package ksql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	defer Close()
+	db, err := New("draining", "ksql_hosts", "draining")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := db.Prepare("update people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- Shutdown(context.Background())
+	}()
+	// new work is refused while the transaction in flight goes on
+	deadline := time.Now().Add(time.Second)
+	for !db.isDraining() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := db.Query("select people"); err != ErrShuttingDown {
+		t.Errorf("expected new queries to be refused, got %v", err)
+	}
+	if _, err := db.Begin(); err != ErrShuttingDown {
+		t.Errorf("expected new transactions to be refused, got %v", err)
+	}
+	if _, err := stmt.Exec(); err != ErrShuttingDown {
+		t.Errorf("expected the prepared statements to be refused, got %v", err)
+	}
+	if _, err := stmt.Query(); err != ErrShuttingDown {
+		t.Errorf("expected the prepared queries to be refused, got %v", err)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("expected to wait for the transaction, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err := tx.Exec("update people"); err != nil {
+		t.Errorf("expected the transaction to go on, got %v", err)
+	}
+	if _, err := tx.Stmt(stmt).Exec(); err != nil {
+		t.Errorf("expected the prepared statement to go on in the transaction, got %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("expected a clean shutdown, got %v", err)
+	}
+	if names := Databases(); len(names) != 0 {
+		t.Errorf("expected every connection to be closed, got %v", names)
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	defer Close()
+	db, err := New("stuck", "ksql_hosts", "stuck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("select people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to pass, got %v", err)
+	}
+	if _, ok := Get("stuck"); ok {
+		t.Errorf("expected the connection to be closed anyway")
+	}
+}
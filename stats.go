@@ -0,0 +1,56 @@
+package ksql
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Snapshot of the statistics of a named database connection, flattened for logging and export
+type PoolStats struct {
+	Name               string        `json:"name"`
+	MaxOpenConnections int           `json:"max_open_connections"`
+	OpenConnections    int           `json:"open_connections"`
+	InUse              int           `json:"in_use"`
+	Idle               int           `json:"idle"`
+	WaitCount          int64         `json:"wait_count"`
+	WaitDuration       time.Duration `json:"wait_duration"`
+	MaxIdleClosed      int64         `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64         `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64         `json:"max_lifetime_closed"`
+}
+
+// Get the statistics of every named database connection
+func Stats() map[string]sql.DBStats {
+	poolMu.RLock()
+	defer poolMu.RUnlock()
+	stats := make(map[string]sql.DBStats, len(pool))
+	for name, db := range pool {
+		stats[name] = db.Stats()
+	}
+	return stats
+}
+
+// Get a snapshot of the statistics of every named database connection, sorted by name
+func StatsSnapshot() []PoolStats {
+	stats := Stats()
+	snapshot := make([]PoolStats, 0, len(stats))
+	for _, name := range Databases() {
+		s, ok := stats[name]
+		if !ok {
+			continue
+		}
+		snapshot = append(snapshot, PoolStats{
+			Name:               name,
+			MaxOpenConnections: s.MaxOpenConnections,
+			OpenConnections:    s.OpenConnections,
+			InUse:              s.InUse,
+			Idle:               s.Idle,
+			WaitCount:          s.WaitCount,
+			WaitDuration:       s.WaitDuration,
+			MaxIdleClosed:      s.MaxIdleClosed,
+			MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+			MaxLifetimeClosed:  s.MaxLifetimeClosed,
+		})
+	}
+	return snapshot
+}
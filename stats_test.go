@@ -0,0 +1,31 @@
+package ksql
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	defer Close()
+	first, err := New("first", "ksql_hosts", "first", WithMaxOpenConns(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New("second", "ksql_hosts", "second"); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	stats := Stats()
+	if len(stats) != 2 {
+		t.Fatalf("expected the stats of 2 connections, got %v", stats)
+	}
+	if stats["first"].MaxOpenConnections != 3 || stats["first"].OpenConnections != 1 {
+		t.Errorf("expected 1 of 3 connections open on the first database, got %+v", stats["first"])
+	}
+	snapshot := StatsSnapshot()
+	if len(snapshot) != 2 || snapshot[0].Name != "first" || snapshot[1].Name != "second" {
+		t.Fatalf("expected a snapshot of both connections sorted by name, got %+v", snapshot)
+	}
+	if snapshot[0].MaxOpenConnections != 3 || snapshot[0].Idle != 1 {
+		t.Errorf("expected 1 idle of 3 connections on the first database, got %+v", snapshot[0])
+	}
+}
@@ -0,0 +1,63 @@
+package ksql
+
+import "sort"
+
+// Tag the database connection with an attribute, e.g. WithTag("role", "replica"), to find it
+// with GetByTag and ListByTag
+func WithTag(key, value string) Option {
+	return func(db *DB) error {
+		if db.tags == nil {
+			db.tags = make(map[string]string)
+		}
+		db.tags[key] = value
+		return nil
+	}
+}
+
+// Tag the database connection with several attributes, see WithTag
+func WithTags(tags map[string]string) Option {
+	return func(db *DB) error {
+		for key, value := range tags {
+			WithTag(key, value)(db)
+		}
+		return nil
+	}
+}
+
+// Get a copy of the tags of the database connection
+func (db *DB) Tags() map[string]string {
+	tags := make(map[string]string, len(db.tags))
+	for key, value := range db.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// Get the value of a tag of the database connection
+func (db *DB) Tag(key string) (string, bool) {
+	value, ok := db.tags[key]
+	return value, ok
+}
+
+// Get the first open database connection, by name, with the tag
+func GetByTag(key, value string) (*DB, bool) {
+	list := ListByTag(key, value)
+	if len(list) == 0 {
+		return nil, false
+	}
+	return list[0], true
+}
+
+// Get the open database connections with the tag, sorted by name
+func ListByTag(key, value string) []*DB {
+	poolMu.RLock()
+	defer poolMu.RUnlock()
+	var list []*DB
+	for _, db := range pool {
+		if v, ok := db.tags[key]; ok && v == value {
+			list = append(list, db)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+	return list
+}
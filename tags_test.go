@@ -0,0 +1,35 @@
+package ksql
+
+import "testing"
+
+func TestTags(t *testing.T) {
+	defer Close()
+	if _, err := New("eu-replica", "ksql_hosts", "eu-replica", WithTags(map[string]string{"role": "replica", "region": "eu"})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New("eu-primary", "ksql_hosts", "eu-primary", WithTag("role", "primary"), WithTag("region", "eu")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New("untagged", "ksql_hosts", "untagged"); err != nil {
+		t.Fatal(err)
+	}
+	db, ok := GetByTag("role", "replica")
+	if !ok || db.Name() != "eu-replica" {
+		t.Errorf("expected the replica, got %v", db)
+	}
+	list := ListByTag("region", "eu")
+	if len(list) != 2 || list[0].Name() != "eu-primary" || list[1].Name() != "eu-replica" {
+		t.Errorf("expected both eu connections by name, got %d", len(list))
+	}
+	if _, ok := GetByTag("region", "us"); ok {
+		t.Errorf("expected no connection in us")
+	}
+	if region, ok := db.Tag("region"); !ok || region != "eu" {
+		t.Errorf("expected the eu region, got %q", region)
+	}
+	tags := db.Tags()
+	tags["role"] = "primary"
+	if role, _ := db.Tag("role"); role != "replica" {
+		t.Errorf("expected the tags to be copied")
+	}
+}
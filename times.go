@@ -0,0 +1,67 @@
+package ksql
+
+import (
+	"strings"
+	"time"
+)
+
+// Layouts of the text timestamps read by GetTime, unless set by WithTimeLayouts
+var DefaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// Set the layouts, tried in order, of the text timestamps read by GetTime from the database,
+// for drivers such as sqlite that return timestamps as text
+func WithTimeLayouts(layouts ...string) Option {
+	return func(db *DB) error {
+		db.read.timeLayouts = layouts
+		return nil
+	}
+}
+
+// Set the location the times read by GetTime from the database are in. Text timestamps
+// without a time zone are taken to be in that location, rather than in UTC.
+func WithLocation(loc *time.Location) Option {
+	return func(db *DB) error {
+		db.read.location = loc
+		return nil
+	}
+}
+
+// Read a native time, or parse text with the time layouts of the rows, in the location of
+// the rows when set
+func (rs *Rows) convertToTime(value interface{}) (time.Time, error) {
+	loc := rs.opts.location
+	var s string
+	switch value := value.(type) {
+	case []byte:
+		s = string(value)
+	case string:
+		s = value
+	default:
+		t, err := convertToTime(value)
+		if err == nil && loc != nil {
+			t = t.In(loc)
+		}
+		return t, err
+	}
+	layouts := rs.opts.timeLayouts
+	if layouts == nil {
+		layouts = DefaultTimeLayouts
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	s = strings.TrimSpace(s)
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t.In(loc), nil
+		}
+	}
+	return time.Time{}, ErrInvalidColumnTypeConversion
+}
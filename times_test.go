@@ -0,0 +1,94 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestTimeLayouts(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select times", []string{"rfc3339", "zone", "sql", "bytes", "date", "native", "custom"},
+		[]driver.Value{"2016-01-02T03:04:05Z", "2016-01-02 04:04:05.5+01", "2016-01-02 03:04:05", []byte("2016-01-02 03:04:05"),
+			"2016-01-02", time.Date(2016, time.January, 2, 3, 4, 5, 0, time.UTC), "02/01/2016 03:04"})
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := db.QueryRow("select times")
+	want := time.Date(2016, time.January, 2, 3, 4, 5, 0, time.UTC)
+	tests := map[string]time.Time{"rfc3339": want, "zone": want.Add(500 * time.Millisecond), "sql": want, "bytes": want,
+		"date": time.Date(2016, time.January, 2, 0, 0, 0, 0, time.UTC), "native": want}
+	for column, want := range tests {
+		v, err := row.GetTime(column)
+		if err != nil {
+			t.Errorf("%q: %v", column, err)
+			continue
+		}
+		if !v.Equal(want) {
+			t.Errorf("expected %v for %q, got %v", want, column, v)
+		}
+	}
+	if _, err := row.GetTime("custom"); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected a conversion error for \"custom\", got %v", err)
+	}
+	custom, err := New("custom", "ksql_hosts", "hosts", WithTimeLayouts("02/01/2006 15:04"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	row = custom.QueryRow("select times")
+	v, err := row.GetNullTime("custom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Valid || !v.Time.Equal(time.Date(2016, time.January, 2, 3, 4, 0, 0, time.UTC)) {
+		t.Errorf("expected 2016-01-02 03:04 for \"custom\", got %v", v)
+	}
+	if _, err := row.GetTime("sql"); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected only the custom layouts to be tried, got %v", err)
+	}
+}
+
+func TestTimeLocation(t *testing.T) {
+	defer Close()
+	testHosts.setResult("select zones", []string{"utc", "local", "offset"},
+		[]driver.Value{time.Date(2016, time.January, 2, 3, 4, 5, 0, time.UTC), "2016-01-02 03:04:05", "2016-01-02 03:04:05+01"})
+	tokyo := time.FixedZone("JST", 9*60*60)
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := db.QueryRow("select zones").GetTimeIn("utc", tokyo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Location() != tokyo || v.Hour() != 12 {
+		t.Errorf("expected 12:04:05 JST for \"utc\", got %v", v)
+	}
+	zoned, err := New("zoned", "ksql_hosts", "hosts", WithLocation(tokyo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := zoned.QueryRow("select zones")
+	tests := map[string]time.Time{
+		"utc":    time.Date(2016, time.January, 2, 12, 4, 5, 0, tokyo),
+		"local":  time.Date(2016, time.January, 2, 3, 4, 5, 0, tokyo),
+		"offset": time.Date(2016, time.January, 2, 11, 4, 5, 0, tokyo),
+	}
+	for column, want := range tests {
+		v, err := row.GetTime(column)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !v.Equal(want) || v.Location() != tokyo {
+			t.Errorf("expected %v for %q, got %v", want, column, v)
+		}
+	}
+	v, err = row.GetTimeIn("local", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Location() != time.UTC || v.Hour() != 18 || v.Day() != 1 {
+		t.Errorf("expected 2016-01-01 18:04:05 UTC for \"local\", got %v", v)
+	}
+}
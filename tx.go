@@ -0,0 +1,279 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// Set the isolation level and read-only flag of the transactions begun without options,
+// by Begin, WithTx and BeginTx with nil options
+func WithTxOptions(opts sql.TxOptions) Option {
+	return func(db *DB) error {
+		db.txOptions = &opts
+		return nil
+	}
+}
+
+// Get the default transaction options of the database, nil when the driver defaults are used
+func (db *DB) TxOptions() *sql.TxOptions {
+	if db.txOptions == nil {
+		return nil
+	}
+	opts := *db.txOptions
+	return &opts
+}
+
+// Begin a transaction with the options instead of the default ones
+func (db *DB) BeginWith(opts sql.TxOptions) (*Tx, error) {
+	return db.BeginWithContext(context.Background(), opts)
+}
+
+func (db *DB) BeginWithContext(ctx context.Context, opts sql.TxOptions) (*Tx, error) {
+	return db.BeginTx(ctx, &opts)
+}
+
+// Run fn inside a transaction, which is committed when fn returns nil and rolled
+// back when fn returns an error or panics. The panic is propagated after the rollback.
+func (db *DB) WithTx(ctx context.Context, fn func(tx *Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Run fn inside a read-only transaction, on a read replica when the database prefers them
+// with WithReadPreference, and always roll it back once fn returns or panics. The isolation
+// level is the default one of WithTxOptions.
+func (db *DB) WithReadTx(ctx context.Context, fn func(tx *Tx) error) error {
+	target := db
+	if db.readPref == ReadReplica {
+		target = db.Replica()
+	}
+	opts := sql.TxOptions{ReadOnly: true}
+	if target.txOptions != nil {
+		opts.Isolation = target.txOptions.Isolation
+	}
+	tx, err := target.BeginTx(ctx, &opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Rollback()
+}
+
+// Policy for retrying a transaction or statement with exponential backoff
+type RetryPolicy struct {
+	MaxAttempts int           // total number of attempts, including the first one
+	BaseDelay   time.Duration // delay before the first retry, doubled on every retry
+	MaxDelay    time.Duration // upper bound of the delay between attempts
+	// fraction of every delay picked at random, so that clients failing together don't
+	// retry together, 0.2 waits between 80% and 120% of the delay
+	Jitter float64
+	// check whether an error is worth another attempt, see WithTxRetry and WithRetryPolicy
+	// for the errors retried when nil
+	Retryable func(error) bool
+}
+
+// Retry policy used when a zero RetryPolicy is given
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 5, BaseDelay: 10 * time.Millisecond, MaxDelay: time.Second}
+
+func (p RetryPolicy) isZero() bool {
+	return p.MaxAttempts == 0 && p.BaseDelay == 0 && p.MaxDelay == 0 && p.Jitter == 0 && p.Retryable == nil
+}
+
+// Get the delay before the given retry, starting at 1
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < retry && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// Wait before the given retry, with jitter, and report false when the context ends first
+func (p RetryPolicy) wait(ctx context.Context, retry int) bool {
+	d := p.delay(retry)
+	if p.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// Call fn until it succeeds, fails with an error that is not retryable, or the attempts
+// are exhausted, and get its last error. Errors are checked by retryable when the policy
+// has no classifier of its own.
+func (p RetryPolicy) do(ctx context.Context, retryable func(error) bool, fn func() error) error {
+	if p.Retryable != nil {
+		retryable = p.Retryable
+	}
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !retryable(err) || attempt >= p.MaxAttempts || !p.wait(ctx, attempt) {
+			return err
+		}
+	}
+}
+
+// Run fn inside a transaction like WithTx, and run it again in a new transaction when it
+// fails with a serialization failure or deadlock, or the errors of the policy classifier,
+// waiting between attempts according to the policy. The last error is returned once the
+// attempts are exhausted.
+func (db *DB) WithTxRetry(ctx context.Context, policy RetryPolicy, fn func(tx *Tx) error) error {
+	if policy.isZero() {
+		policy = DefaultRetryPolicy
+	}
+	return policy.do(ctx, retryable, func() error {
+		return db.WithTx(ctx, fn)
+	})
+}
+
+// Retry the queries and execs of the database, including QueryRow, when they fail to reach
+// the database, according to the policy. By default only connection failures are retried,
+// such as a refused or reset connection or a server shutting down, which database/sql does
+// not already retry. Statements run in transactions or prepared are never retried, nor are
+// the errors of reading rows. Beware that an exec may have run when the connection is lost
+// while waiting for its result, a classifier can restrict the retries to safer errors.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(db *DB) error {
+		if policy.isZero() {
+			policy = DefaultRetryPolicy
+		}
+		db.retry = &policy
+		return nil
+	}
+}
+
+// Begin a nested pseudo-transaction backed by a savepoint of this transaction. Commit
+// releases the savepoint and Rollback rolls back to it, leaving the outer transaction open.
+func (tx *Tx) Begin() (*Tx, error) {
+	root := tx
+	if tx.root != nil {
+		root = tx.root
+	}
+	root.savepoints++
+	name := "ksql_savepoint_" + strconv.Itoa(root.savepoints)
+	if err := tx.Savepoint(name); err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx.Tx, db: tx.db, ctx: tx.ctx, root: root, savepoint: name}, nil
+}
+
+// Commit the transaction, or release the savepoint of a nested transaction
+func (tx *Tx) Commit() error {
+	if tx.root == nil {
+		err := tx.Tx.Commit()
+		tx.finish("COMMIT", err)
+		return tx.db.mapError(err)
+	}
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	return tx.Release(tx.savepoint)
+}
+
+// Roll back the transaction, or roll back to the savepoint of a nested transaction
+func (tx *Tx) Rollback() error {
+	if tx.root == nil {
+		err := tx.Tx.Rollback()
+		tx.finish("ROLLBACK", err)
+		return err
+	}
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	return tx.RollbackTo(tx.savepoint)
+}
+
+// Report the end of the transaction to the hooks and give back its slot, once
+func (tx *Tx) finish(query string, err error) {
+	if tx.release != nil {
+		tx.release()
+		tx.release = nil
+	}
+	if tx.event == nil {
+		return
+	}
+	event := tx.event
+	tx.event = nil
+	event.Query = query
+	tx.db.afterQuery(tx.ctx, event, nil, err)
+}
+
+// Get the context the transaction was started with, including the values added by hooks
+func (tx *Tx) context() context.Context {
+	if tx.ctx == nil {
+		return context.Background()
+	}
+	return tx.ctx
+}
+
+// Create a savepoint with the given name
+func (tx *Tx) Savepoint(name string) error {
+	return tx.execSavepoint("SAVEPOINT ", name)
+}
+
+// Release the savepoint with the given name
+func (tx *Tx) Release(name string) error {
+	return tx.execSavepoint("RELEASE SAVEPOINT ", name)
+}
+
+// Roll back to the savepoint with the given name, keeping the savepoint
+func (tx *Tx) RollbackTo(name string) error {
+	return tx.execSavepoint("ROLLBACK TO SAVEPOINT ", name)
+}
+
+func (tx *Tx) execSavepoint(statement, name string) error {
+	if !validSavepointName(name) {
+		return ErrInvalidSavepointName
+	}
+	_, err := tx.Tx.Exec(statement + name)
+	return err
+}
+
+// Savepoint names are interpolated into the statement, so only plain identifiers are allowed
+func validSavepointName(name string) bool {
+	if name == "" || !isNameStart(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isNameStart(name[i]) && !(name[i] >= '0' && name[i] <= '9') {
+			return false
+		}
+	}
+	return true
+}
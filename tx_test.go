@@ -0,0 +1,174 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+	for i, d := range want {
+		if got := policy.delay(i + 1); got != d {
+			t.Errorf("retry %d: expected a delay of %v, got %v", i+1, d, got)
+		}
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, Jitter: 0.5}
+	attempts := 0
+	err := policy.do(context.Background(), retryable, func() error {
+		attempts++
+		return &pqError{"40001"}
+	})
+	if err == nil || attempts != 4 {
+		t.Errorf("expected 4 failed attempts, got %d %v", attempts, err)
+	}
+	policy.Retryable = func(err error) bool { return err.Error() == "again" }
+	attempts = 0
+	err = policy.do(context.Background(), retryable, func() error {
+		if attempts++; attempts < 3 {
+			return errors.New("again")
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("expected the classifier to retry twice, got %d %v", attempts, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	attempts = 0
+	policy.do(ctx, retryable, func() error {
+		attempts++
+		return errors.New("again")
+	})
+	if attempts != 1 {
+		t.Errorf("expected the ended context to stop the retries, got %d attempts", attempts)
+	}
+}
+
+func TestRetryPolicyStatements(t *testing.T) {
+	defer Close()
+	unreachable := func(err error) bool { return strings.HasPrefix(err.Error(), "host unreachable") }
+	hook := &recoveringHook{dsn: "flaky"}
+	db, err := New("flaky", "ksql_hosts", "flaky", WithHook(hook),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, Retryable: unreachable}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testHosts.setDown("flaky", true)
+	if _, err := db.Exec("update people"); err != nil {
+		t.Fatalf("expected the exec to be retried, got %v", err)
+	}
+	if hook.attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", hook.attempts)
+	}
+	db.SetMaxIdleConns(0)
+	testHosts.setDown("flaky", true)
+	hook.attempts = 0
+	rows, err := db.Query("select people")
+	if err != nil {
+		t.Fatalf("expected the query to be retried, got %v", err)
+	}
+	rows.Close()
+	if hook.attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", hook.attempts)
+	}
+	hook.attempts = 0
+	if _, err := db.Exec("fail"); err == nil || hook.attempts != 1 {
+		t.Errorf("expected no retry of a failed statement, got %d attempts", hook.attempts)
+	}
+}
+
+// Hook bringing the host back up once a statement failed on it
+type recoveringHook struct {
+	dsn      string
+	attempts int
+}
+
+func (h *recoveringHook) BeforeQuery(ctx context.Context, event *QueryEvent) (context.Context, error) {
+	h.attempts++
+	return ctx, nil
+}
+
+func (h *recoveringHook) AfterQuery(ctx context.Context, event *QueryEvent) {
+	if event.Err != nil {
+		testHosts.setDown(h.dsn, false)
+	}
+}
+
+func TestTxOptions(t *testing.T) {
+	defer Close()
+	db, err := New("tx options", "ksql_hosts", "tx options",
+		WithTxOptions(sql.TxOptions{Isolation: sql.LevelRepeatableRead}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts := db.TxOptions(); opts == nil || opts.Isolation != sql.LevelRepeatableRead {
+		t.Errorf("expected the default options, got %v", opts)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Rollback()
+	if opts := testHosts.lastTxOptions(); opts.Isolation != driver.IsolationLevel(sql.LevelRepeatableRead) || opts.ReadOnly {
+		t.Errorf("expected repeatable read, got %v", opts)
+	}
+	err = db.WithTx(context.Background(), func(tx *Tx) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts := testHosts.lastTxOptions(); opts.Isolation != driver.IsolationLevel(sql.LevelRepeatableRead) {
+		t.Errorf("expected repeatable read in WithTx, got %v", opts)
+	}
+	tx, err = db.BeginWith(sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Rollback()
+	if opts := testHosts.lastTxOptions(); opts.Isolation != driver.IsolationLevel(sql.LevelSerializable) || !opts.ReadOnly {
+		t.Errorf("expected a serializable read only transaction, got %v", opts)
+	}
+}
+
+func TestWithReadTx(t *testing.T) {
+	defer Close()
+	hook := &recordingHook{}
+	db, err := New("read tx", "ksql_hosts", "read tx primary", WithHook(hook), WithReadPreference(ReadReplica),
+		WithTxOptions(sql.TxOptions{Isolation: sql.LevelRepeatableRead}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewReplica("read tx", "ksql_hosts", "read tx replica"); err != nil {
+		t.Fatal(err)
+	}
+	// only the replica is reachable
+	testHosts.setDown("read tx primary", true)
+	defer testHosts.setDown("read tx primary", false)
+	var name string
+	err = db.WithReadTx(context.Background(), func(tx *Tx) error {
+		name, err = tx.QueryRow("select people").GetString("name")
+		return err
+	})
+	if err != nil || name != "john doe" {
+		t.Fatalf("expected to read from the replica, got %q %v", name, err)
+	}
+	opts := testHosts.lastTxOptions()
+	if !opts.ReadOnly || opts.Isolation != driver.IsolationLevel(sql.LevelRepeatableRead) {
+		t.Errorf("expected a read only repeatable read transaction, got %v", opts)
+	}
+	if last := hook.after[len(hook.after)-1]; last.Op != OpTx || last.Query != "ROLLBACK" {
+		t.Errorf("expected the transaction to be rolled back, got %v %q", last.Op, last.Query)
+	}
+	stop := errors.New("stop")
+	if err := db.WithReadTx(context.Background(), func(tx *Tx) error { return stop }); err != stop {
+		t.Errorf("expected the error of fn, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Generate the statement inserting values into table, or updating the other columns of the row
+// when one with the same keys exists. values is a map of column names to values, or a struct
+// whose fields are matched to columns like Rows.ScanStruct. Postgres and SQLite get
+// ON CONFLICT DO UPDATE, MySQL gets ON DUPLICATE KEY UPDATE. It fails with ErrInvalidUpsert
+// when values is neither, or when the keys are not among its columns.
+func UpsertSQL(dialect Dialect, style BindStyle, table string, keys []string, values interface{}) (Statement, error) {
+	columns, args, err := upsertValues(values)
+	if err != nil {
+		return Statement{}, err
+	}
+	isKey := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		isKey[key] = true
+	}
+	var updates []string
+	for _, column := range columns {
+		if isKey[column] {
+			delete(isKey, column)
+		} else {
+			updates = append(updates, column)
+		}
+	}
+	if len(keys) == 0 || len(isKey) > 0 {
+		return Statement{}, ErrInvalidUpsert
+	}
+	var b strings.Builder
+	b.WriteString("INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (")
+	for i := range columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		style.placeholder(&b, i+1)
+	}
+	b.WriteByte(')')
+	switch dialect {
+	case DialectPostgres, DialectSQLite:
+		b.WriteString(" ON CONFLICT (" + strings.Join(keys, ", ") + ") DO ")
+		if len(updates) == 0 {
+			b.WriteString("NOTHING")
+			break
+		}
+		b.WriteString("UPDATE SET ")
+		for i, column := range updates {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(column + " = EXCLUDED." + column)
+		}
+	case DialectMySQL:
+		b.WriteString(" ON DUPLICATE KEY UPDATE ")
+		// updating a key to itself leaves the existing row alone
+		if len(updates) == 0 {
+			updates = keys[:1]
+		}
+		for i, column := range updates {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(column + " = VALUES(" + column + ")")
+		}
+	default:
+		return Statement{}, ErrUnsupportedDialect
+	}
+	return Statement{Query: b.String(), Args: args}, nil
+}
+
+// Get the columns and values of a map, in column order, or of a struct, in field order
+func upsertValues(values interface{}) ([]string, []interface{}, error) {
+	v := reflect.ValueOf(values)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	var columns []string
+	var args []interface{}
+	switch {
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		for _, key := range v.MapKeys() {
+			columns = append(columns, key.String())
+		}
+		sort.Strings(columns)
+		for _, column := range columns {
+			args = append(args, v.MapIndex(reflect.ValueOf(column).Convert(v.Type().Key())).Interface())
+		}
+	case v.Kind() == reflect.Struct:
+		fields := fieldsOf(v.Type())
+		for column := range fields {
+			columns = append(columns, column)
+		}
+		sort.Slice(columns, func(i, j int) bool {
+			a, b := fields[columns[i]], fields[columns[j]]
+			for k := 0; k < len(a) && k < len(b); k++ {
+				if a[k] != b[k] {
+					return a[k] < b[k]
+				}
+			}
+			return len(a) < len(b)
+		})
+		for _, column := range columns {
+			args = append(args, v.FieldByIndex(fields[column]).Interface())
+		}
+	default:
+		return nil, nil, ErrInvalidUpsert
+	}
+	if len(columns) == 0 {
+		return nil, nil, ErrInvalidUpsert
+	}
+	return columns, args, nil
+}
+
+// Insert values into table, or update the other columns of the row with the same keys,
+// see UpsertSQL
+func (db *DB) Upsert(ctx context.Context, table string, keys []string, values interface{}) (sql.Result, error) {
+	statement, err := UpsertSQL(db.dialect, db.bind, table, keys, values)
+	if err != nil {
+		return nil, err
+	}
+	return db.ExecContext(ctx, statement.Query, statement.Args...)
+}
+
+// Insert or update values as part of the transaction, see DB.Upsert
+func (tx *Tx) Upsert(ctx context.Context, table string, keys []string, values interface{}) (sql.Result, error) {
+	statement, err := UpsertSQL(tx.db.dialect, tx.db.bind, table, keys, values)
+	if err != nil {
+		return nil, err
+	}
+	return tx.ExecContext(ctx, statement.Query, statement.Args...)
+}
@@ -0,0 +1,77 @@
+package ksql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUpsertSQL(t *testing.T) {
+	type person struct {
+		ID   int64
+		Name string
+		Age  int
+	}
+	john := person{1, "john doe", 42}
+	tests := []struct {
+		dialect Dialect
+		style   BindStyle
+		keys    []string
+		values  interface{}
+		query   string
+		args    []interface{}
+	}{
+		{DialectPostgres, BindDollar, []string{"id"}, john,
+			"INSERT INTO people (id, name, age) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, age = EXCLUDED.age",
+			[]interface{}{int64(1), "john doe", 42}},
+		{DialectSQLite, BindQuestion, []string{"id"}, map[string]interface{}{"name": "john doe", "id": 1},
+			"INSERT INTO people (id, name) VALUES (?, ?) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name",
+			[]interface{}{1, "john doe"}},
+		{DialectMySQL, BindQuestion, []string{"id"}, &john,
+			"INSERT INTO people (id, name, age) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name), age = VALUES(age)",
+			[]interface{}{int64(1), "john doe", 42}},
+		{DialectPostgres, BindDollar, []string{"id"}, map[string]interface{}{"id": 1},
+			"INSERT INTO people (id) VALUES ($1) ON CONFLICT (id) DO NOTHING",
+			[]interface{}{1}},
+		{DialectMySQL, BindQuestion, []string{"id"}, map[string]interface{}{"id": 1},
+			"INSERT INTO people (id) VALUES (?) ON DUPLICATE KEY UPDATE id = VALUES(id)",
+			[]interface{}{1}},
+	}
+	for _, test := range tests {
+		statement, err := UpsertSQL(test.dialect, test.style, "people", test.keys, test.values)
+		if err != nil {
+			t.Errorf("%v: %v", test.dialect, err)
+			continue
+		}
+		if statement.Query != test.query || !reflect.DeepEqual(statement.Args, test.args) {
+			t.Errorf("%v: expected %q %v, got %q %v", test.dialect, test.query, test.args, statement.Query, statement.Args)
+		}
+	}
+	if _, err := UpsertSQL(DialectPostgres, BindDollar, "people", []string{"email"}, john); err != ErrInvalidUpsert {
+		t.Errorf("expected an invalid upsert error for a missing key, got %v", err)
+	}
+	if _, err := UpsertSQL(DialectSQLServer, BindAt, "people", []string{"id"}, john); err != ErrUnsupportedDialect {
+		t.Errorf("expected an unsupported dialect error, got %v", err)
+	}
+	if _, err := UpsertSQL(DialectPostgres, BindDollar, "people", []string{"id"}, 1); err != ErrInvalidUpsert {
+		t.Errorf("expected an invalid upsert error for values that are not a map or struct, got %v", err)
+	}
+}
+
+func TestDialectFor(t *testing.T) {
+	tests := map[string]Dialect{
+		"postgres":                       DialectPostgres,
+		"github.com/lib/pq":              DialectPostgres,
+		"pgx":                            DialectPostgres,
+		"mysql":                          DialectMySQL,
+		"github.com/go-sql-driver/mysql": DialectMySQL,
+		"sqlite3":                        DialectSQLite,
+		"sqlserver":                      DialectSQLServer,
+		"godror":                         DialectOracle,
+		"ksql_hosts":                     DialectUnknown,
+	}
+	for driver, want := range tests {
+		if got := dialectFor(driver); got != want {
+			t.Errorf("expected %v for %q, got %v", want, driver, got)
+		}
+	}
+}
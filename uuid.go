@@ -0,0 +1,80 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+)
+
+// A UUID as read by GetUUID. It scans from and is stored as its canonical text form.
+type UUID [16]byte
+
+// Format the UUID as xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+func (u UUID) String() string {
+	var b [36]byte
+	hex.Encode(b[0:8], u[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return string(b[:])
+}
+
+// Parse the UUID from text with or without hyphens and braces, or from its 16 raw bytes
+func ParseUUID(value []byte) (UUID, error) {
+	var u UUID
+	if len(value) == 16 {
+		copy(u[:], value)
+		return u, nil
+	}
+	if len(value) == 38 && value[0] == '{' && value[37] == '}' {
+		value = value[1:37]
+	}
+	if len(value) == 36 {
+		if value[8] != '-' || value[13] != '-' || value[18] != '-' || value[23] != '-' {
+			return u, ErrInvalidColumnTypeConversion
+		}
+		digits := make([]byte, 0, 32)
+		for i, c := range value {
+			if i != 8 && i != 13 && i != 18 && i != 23 {
+				digits = append(digits, c)
+			}
+		}
+		value = digits
+	}
+	if len(value) != 32 {
+		return u, ErrInvalidColumnTypeConversion
+	}
+	if _, err := hex.Decode(u[:], value); err != nil {
+		return u, ErrInvalidColumnTypeConversion
+	}
+	return u, nil
+}
+
+// Scan the UUID from a text or binary column
+func (u *UUID) Scan(value interface{}) error {
+	var err error
+	switch value := value.(type) {
+	case []byte:
+		*u, err = ParseUUID(value)
+	case string:
+		*u, err = ParseUUID([]byte(value))
+	default:
+		err = ErrInvalidColumnTypeConversion
+	}
+	return err
+}
+
+// Store the UUID as its canonical text form
+func (u UUID) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+func convertToUUID(value interface{}) (UUID, error) {
+	var u UUID
+	err := u.Scan(value)
+	return u, err
+}
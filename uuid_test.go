@@ -0,0 +1,59 @@
+package ksql
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestParseUUID(t *testing.T) {
+	want := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	for _, text := range []string{
+		want,
+		"6BA7B810-9DAD-11D1-80B4-00C04FD430C8",
+		"{6ba7b810-9dad-11d1-80b4-00c04fd430c8}",
+		"6ba7b8109dad11d180b400c04fd430c8",
+		"\x6b\xa7\xb8\x10\x9d\xad\x11\xd1\x80\xb4\x00\xc0\x4f\xd4\x30\xc8",
+	} {
+		u, err := ParseUUID([]byte(text))
+		if err != nil {
+			t.Errorf("%q: %v", text, err)
+			continue
+		}
+		if u.String() != want {
+			t.Errorf("expected %s for %q, got %s", want, text, u)
+		}
+	}
+	for _, text := range []string{"", "6ba7b810-9dad-11d1-80b4", "6ba7b810x9dad-11d1-80b4-00c04fd430c8", "zba7b8109dad11d180b400c04fd430c8"} {
+		if _, err := ParseUUID([]byte(text)); err != ErrInvalidColumnTypeConversion {
+			t.Errorf("expected a conversion error for %q, got %v", text, err)
+		}
+	}
+}
+
+func TestGetUUID(t *testing.T) {
+	defer Close()
+	db, err := New("hosts", "ksql_hosts", "hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testHosts.setResult("select uuid", []string{"text", "bytes", "id"},
+		[]driver.Value{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", []byte("6ba7b810-9dad-11d1-80b4-00c04fd430c8"), int64(1)})
+	row := db.QueryRow("select uuid")
+	for _, column := range []string{"text", "bytes"} {
+		u, err := row.GetUUID(column)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.String() != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
+			t.Errorf("expected 6ba7b810-9dad-11d1-80b4-00c04fd430c8 for %q, got %s", column, u)
+		}
+	}
+	if _, err := row.GetUUID("id"); err != ErrInvalidColumnTypeConversion {
+		t.Errorf("expected a conversion error for \"id\", got %v", err)
+	}
+	// UUID is a scanner, so the generic accessor reads it too
+	u, err := Value[UUID](row, "text")
+	if err != nil || u.String() != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
+		t.Errorf("expected Value to scan the UUID, got %s %v", u, err)
+	}
+}
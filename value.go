@@ -0,0 +1,154 @@
+package ksql
+
+import (
+	"database/sql"
+	"reflect"
+	"time"
+)
+
+// Source is a row of results whose columns can be read by name, implemented by *Rows and *Row
+type Source interface {
+	value(column string) (interface{}, error)
+	// rows whose options convert the values, once value succeeded
+	source() *Rows
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// Get the value of type T in the current row by column name. Besides the conversions
+// of the typed getters, with the same options of the rows, T may be a pointer (nil on
+// NULL), or implement sql.Scanner such as the sql.Null* types.
+func Value[T any](src Source, column string) (T, error) {
+	var dest T
+	value, err := src.value(column)
+	if err != nil {
+		return dest, err
+	}
+	if err := src.source().convertAssign(reflect.ValueOf(&dest).Elem(), value); err != nil {
+		var zero T
+		return zero, err
+	}
+	return dest, nil
+}
+
+func (rs *Rows) value(column string) (interface{}, error) {
+	rs.lock()
+	defer rs.unlock()
+	i, err := validateRows(rs, column)
+	if err != nil {
+		return nil, err
+	}
+	return rs.values[i], nil
+}
+
+func (r *Row) value(column string) (interface{}, error) {
+	if err := next(r); err != nil {
+		return nil, err
+	}
+	return r.rows.value(column)
+}
+
+func (rs *Rows) source() *Rows {
+	return rs
+}
+
+func (r *Row) source() *Rows {
+	return r.rows
+}
+
+// Store a column value into dest using the package conversion rules, and the options of the
+// rows like the typed getters
+func (rs *Rows) convertAssign(dest reflect.Value, value interface{}) error {
+	if dest.CanAddr() && dest.Addr().Type().Implements(scannerType) {
+		return dest.Addr().Interface().(sql.Scanner).Scan(value)
+	}
+	if dest.Kind() == reflect.Ptr {
+		if value == nil {
+			dest.Set(reflect.Zero(dest.Type()))
+			return nil
+		}
+		elem := reflect.New(dest.Type().Elem())
+		if err := rs.convertAssign(elem.Elem(), value); err != nil {
+			return err
+		}
+		dest.Set(elem)
+		return nil
+	}
+	if dest.Kind() == reflect.Interface {
+		if value == nil {
+			dest.Set(reflect.Zero(dest.Type()))
+			return nil
+		}
+		if !reflect.TypeOf(value).AssignableTo(dest.Type()) {
+			return ErrInvalidColumnTypeConversion
+		}
+		dest.Set(reflect.ValueOf(value))
+		return nil
+	}
+	if dest.Type() == timeType {
+		v, err := convert(rs, value, rs.convertToTime)
+		if err != nil {
+			return err
+		}
+		dest.Set(reflect.ValueOf(v))
+		return nil
+	}
+	switch dest.Kind() {
+	case reflect.Bool:
+		v, err := convert(rs, value, convertToBool)
+		if err != nil {
+			return err
+		}
+		dest.SetBool(v)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := convert(rs, value, convertToInt)
+		if err != nil {
+			return err
+		}
+		if dest.OverflowInt(v) {
+			return ErrInvalidColumnTypeConversion
+		}
+		dest.SetInt(v)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := convert(rs, value, convertToInt)
+		if err != nil {
+			return err
+		}
+		if v < 0 || dest.OverflowUint(uint64(v)) {
+			return ErrInvalidColumnTypeConversion
+		}
+		dest.SetUint(uint64(v))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		v, err := convert(rs, value, convertToDouble)
+		if err != nil {
+			return err
+		}
+		dest.SetFloat(v)
+		return nil
+	case reflect.String:
+		v, err := convert(rs, value, convertToString)
+		if err != nil {
+			return err
+		}
+		dest.SetString(v)
+		return nil
+	case reflect.Slice:
+		if dest.Type().Elem().Kind() != reflect.Uint8 {
+			break
+		}
+		// copied, the driver may reuse its buffer for the next row
+		v, err := convertToBytes(value)
+		if err != nil {
+			return err
+		}
+		dest.SetBytes(v)
+		return nil
+	}
+	return ErrInvalidColumnTypeConversion
+}
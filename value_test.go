@@ -0,0 +1,96 @@
+package ksql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConvertAssign(t *testing.T) {
+	now := time.Date(2016, time.January, 2, 3, 4, 5, 0, time.UTC)
+	seven := int64(7)
+	tests := []struct {
+		value interface{}
+		dest  interface{}
+		want  interface{}
+		err   error
+	}{
+		{int64(7), new(int64), int64(7), nil},
+		{int64(7), new(int8), int8(7), nil},
+		{int64(300), new(int8), int8(0), ErrInvalidColumnTypeConversion},
+		{int64(7), new(uint16), uint16(7), nil},
+		{int64(-7), new(uint16), uint16(0), ErrInvalidColumnTypeConversion},
+		{3.5, new(float32), float32(3.5), nil},
+		{"john doe", new(string), "john doe", nil},
+		{true, new(bool), true, nil},
+		{now, new(time.Time), now, nil},
+		{int64(7), new(*int64), &seven, nil},
+		{nil, new(*int64), (*int64)(nil), nil},
+		{nil, new(int64), int64(0), ErrInvalidColumnTypeConversion},
+		{"7", new(int64), int64(0), ErrInvalidColumnTypeConversion},
+		{int64(7), new(interface{}), int64(7), nil},
+		{nil, new(sql.NullString), sql.NullString{}, nil},
+		{"jd", new(sql.NullString), sql.NullString{String: "jd", Valid: true}, nil},
+		{[]byte("photo"), new([]byte), []byte("photo"), nil},
+		{"photo", new(json.RawMessage), json.RawMessage("photo"), nil},
+		{nil, new([]byte), []byte(nil), nil},
+		{int64(7), new([]byte), []byte(nil), ErrInvalidColumnTypeConversion},
+		{[]byte("7"), new([]int), []int(nil), ErrInvalidColumnTypeConversion},
+	}
+	for _, test := range tests {
+		dest := reflect.ValueOf(test.dest).Elem()
+		err := (&Rows{}).convertAssign(dest, test.value)
+		if err != test.err {
+			t.Errorf("convert %#v to %s: expected error %v, got %v", test.value, dest.Type(), test.err, err)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(dest.Interface(), test.want) {
+			t.Errorf("convert %#v to %s: expected %#v, got %#v", test.value, dest.Type(), test.want, dest.Interface())
+		}
+	}
+}
+
+func TestConvertAssignOptions(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip(err)
+	}
+	rs := &Rows{opts: readOptions{nullAsZero: true, lenient: true, timeLayouts: []string{"02/01/2006"}, location: paris}}
+	tests := []struct {
+		value interface{}
+		dest  interface{}
+		want  interface{}
+	}{
+		{nil, new(int64), int64(0)},
+		{nil, new(*int64), (*int64)(nil)},
+		{"7", new(int64), int64(7)},
+		{[]byte("yes"), new(bool), true},
+		{int64(2), new(float64), 2.0},
+		{"03/01/2016", new(time.Time), time.Date(2016, time.January, 3, 0, 0, 0, 0, paris)},
+	}
+	for _, test := range tests {
+		dest := reflect.ValueOf(test.dest).Elem()
+		if err := rs.convertAssign(dest, test.value); err != nil {
+			t.Errorf("convert %#v to %s: %v", test.value, dest.Type(), err)
+			continue
+		}
+		if !reflect.DeepEqual(dest.Interface(), test.want) {
+			t.Errorf("convert %#v to %s: expected %#v, got %#v", test.value, dest.Type(), test.want, dest.Interface())
+		}
+	}
+	row := detachedRows(nil, rs.opts, []string{"id", "born"}, []interface{}{"7", "03/01/2016"})
+	if v, err := row.GetNullInteger("id"); err != nil || v.Int64 != 7 {
+		t.Errorf("expected the nullable getter to parse the text, got %v %v", v, err)
+	}
+	if v, err := Value[time.Time](row, "born"); err != nil || !v.Equal(time.Date(2016, time.January, 3, 0, 0, 0, 0, paris)) {
+		t.Errorf("expected Value to use the time layouts, got %v %v", v, err)
+	}
+	var person struct {
+		ID int64 `ksql:"id"`
+	}
+	if err := scanStruct(row, reflect.ValueOf(&person).Elem()); err != nil || person.ID != 7 {
+		t.Errorf("expected the struct to be scanned leniently, got %v %v", person.ID, err)
+	}
+}
@@ -0,0 +1,69 @@
+package ksql
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// Open and ping n connections of the database concurrently, then return them to the pool,
+// so the first burst of traffic doesn't wait on new connections. n is capped to the maximum
+// number of open connections when set, and only as many connections as the idle limit allows
+// (see sql.DB.SetMaxIdleConns, 2 by default) are kept once released. The first error cancels
+// the connections still being opened and is returned.
+func (db *DB) Warmup(ctx context.Context, n int) error {
+	return db.WarmupProgress(ctx, n, nil)
+}
+
+// Warm up the database like Warmup, calling progress, when set, once every connection is
+// ready or failed with the number done so far, the number wanted and the error if any.
+// The calls are never concurrent, so progress can fail startup or log as it goes.
+func (db *DB) WarmupProgress(ctx context.Context, n int, progress func(done, total int, err error)) error {
+	if db.isDraining() {
+		return ErrShuttingDown
+	}
+	if max := db.Stats().MaxOpenConnections; max > 0 && n > max {
+		n = max
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		conns []*sql.Conn
+		first error
+		done  int
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := db.DB.Conn(ctx)
+			if err == nil {
+				if err = conn.PingContext(ctx); err != nil {
+					conn.Close()
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			if err != nil {
+				if first == nil {
+					first = err
+					cancel()
+				}
+			} else {
+				conns = append(conns, conn)
+			}
+			if progress != nil {
+				progress(done, n, err)
+			}
+		}()
+	}
+	wg.Wait()
+	// the connections are held until every one is open, so none is reused by another ping
+	for _, conn := range conns {
+		conn.Close()
+	}
+	return first
+}
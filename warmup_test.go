@@ -0,0 +1,43 @@
+package ksql
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWarmup(t *testing.T) {
+	defer Close()
+	db, err := New("warm", "ksql_hosts", "warm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxIdleConns(4)
+	calls := 0
+	err = db.WarmupProgress(context.Background(), 4, func(done, total int, err error) {
+		calls++
+		if done != calls || total != 4 || err != nil {
+			t.Errorf("expected progress %d of 4, got %d of %d %v", calls, done, total, err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := db.Stats(); stats.OpenConnections != 4 || stats.Idle != 4 {
+		t.Errorf("expected 4 idle connections, got %+v", stats)
+	}
+
+	db.SetMaxOpenConns(2)
+	if err := db.Warmup(context.Background(), 10); err != nil {
+		t.Fatal(err)
+	}
+
+	cold, err := New("cold", "ksql_hosts", "cold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testHosts.setDown("cold", true)
+	defer testHosts.setDown("cold", false)
+	if err := cold.Warmup(context.Background(), 3); err == nil {
+		t.Errorf("expected the warmup to fail")
+	}
+}